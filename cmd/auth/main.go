@@ -2,16 +2,28 @@ package main
 
 import (
 	"go-auth/config"
+	queries "go-auth/db/Queries"
 	seeder "go-auth/db/Seeder"
 	authdb "go-auth/db/database"
 	"go-auth/handlers"
 	"go-auth/handlers/admin"
 	"go-auth/handlers/auth"
+	"go-auth/handlers/oauth"
 	"go-auth/handlers/user"
 	"go-auth/middleware"
 	authmiddle "go-auth/middleware/auth"
+	"go-auth/middleware/ratelimit"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/breach"
+	"go-auth/utils/jwt"
+	"go-auth/utils/mailer"
+	"go-auth/utils/password"
+	"go-auth/utils/revocation"
+	"go-auth/utils/webauthn"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -24,6 +36,19 @@ func main() {
 	cfg := config.Load()
 	log.Println("Config loaded:", cfg)
 
+	// Hash new passwords with the configured algorithm; older hashes are upgraded on login
+	if cfg.PasswordHashAlgorithm == "bcrypt" {
+		password.Configure(password.NewBcryptHasher(cfg.BcryptCost))
+	} else {
+		password.Configure(password.NewArgon2idHasher(password.Argon2Params{
+			Memory:      uint32(cfg.Argon2Memory),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+			SaltLength:  uint32(cfg.Argon2SaltLength),
+			KeyLength:   uint32(cfg.Argon2KeyLength),
+		}))
+	}
+
 	// Initialize database
 	database, err := authdb.InitDB(cfg.DBDriver, cfg.DBSource)
 	if err != nil {
@@ -55,19 +80,236 @@ func main() {
 
 	log.Println("Super admin seeded successfully")
 
+	// Load signing keys
+	var keys *jwt.KeySet
+	if cfg.JWTAlgorithm == "HS256" && len(cfg.JWTSecrets) > 0 {
+		// Rotating HMAC keyring
+		secrets := make([]jwt.HMACSecret, 0, len(cfg.JWTSecrets))
+		for _, entry := range cfg.JWTSecrets {
+			secret := jwt.HMACSecret{KeyID: entry.KeyID, Secret: entry.Secret}
+			if entry.RetireAt != nil {
+				secret.RetireAt = *entry.RetireAt
+			}
+			secrets = append(secrets, secret)
+		}
+		keys, err = jwt.NewHMACKeyring(cfg.JWTKeyID, secrets)
+		if err != nil {
+			log.Fatalf("Failed to load JWT keyring: %v", err)
+		}
+	} else {
+		signingKey, err := jwt.LoadSigningKey(cfg.JWTAlgorithm, cfg.JWTKeyID, cfg.JWTSecret, cfg.JWTPrivateKeyPath)
+		if err != nil {
+			log.Fatalf("Failed to load signing key: %v", err)
+		}
+		verificationKeys, err := jwt.LoadVerificationKeys(cfg.JWTVerificationKeyPaths)
+		if err != nil {
+			log.Fatalf("Failed to load verification keys: %v", err)
+		}
+		keys = jwt.NewKeySet(signingKey, verificationKeys...)
+	}
+
+	keys.Issuer = cfg.JWTIssuer
+	keys.Audience = cfg.JWTAudience
+	keys.Leeway = cfg.JWTLeeway
+
+	log.Printf("Signing tokens with %s (kid: %q, %d key(s) accepted)", keys.Active.Method.Alg(), keys.Active.ID, len(keys.Keys))
+
+	// Password rules for new passwords, optionally screened against an offline breach corpus
+	passwordRules := &handlers.PasswordRules{Policy: &cfg.PasswordPolicy}
+	var loginBreaches breach.Checker
+	if cfg.BreachCorpusPath != "" {
+		passwordRules.Breaches, err = breach.Open(cfg.BreachCorpusPath)
+		if err != nil {
+			log.Fatalf("Failed to load breach corpus: %v", err)
+		}
+		if cfg.BreachCheckOnLogin {
+			loginBreaches = passwordRules.Breaches
+		}
+		log.Printf("Breached password screening enabled (%s)", cfg.BreachCorpusPath)
+	}
+
+	// Outgoing email (password reset and email verification links)
+	var mail mailer.Mailer
+	switch cfg.Mailer {
+	case "smtp":
+		mail = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailerFrom)
+	case "file":
+		mail, err = mailer.NewFileMailer(cfg.MailerFileDir, cfg.MailerFrom)
+		if err != nil {
+			log.Fatalf("Failed to create file mailer: %v", err)
+		}
+	default:
+		mail = mailer.NewLogMailer()
+	}
+	log.Printf("Sending email via %s", cfg.Mailer)
+
+	emailVerification := &auth.EmailVerification{
+		Mailer:   mail,
+		URL:      cfg.EmailVerificationURL,
+		Required: cfg.EmailVerificationMode == models.EmailVerificationRequired,
+	}
+	log.Printf("Email verification is %s", cfg.EmailVerificationMode)
+
+	// Token denylist for logout / revocation
+	var denylist revocation.Denylist
+	if cfg.RevocationStore == "memory" {
+		denylist = revocation.NewMemoryDenylist()
+	} else {
+		denylist = revocation.NewPostgresDenylist(database)
+	}
+	verifier := authmiddle.NewTokenVerifier(keys, denylist)
+
+	// Audit log for impersonation and other security events
+	auditLog := audit.NewLogger(database)
+	verifier.Audit = auditLog
+
+	// Accept "Authorization: ApiKey <key>" as well as bearer tokens
+	verifier.Database = database
+
+	// Optional online check that rejects tokens issued before the user's token_version was bumped
+	if cfg.TokenVersionCheck {
+		versions := revocation.NewVersionCache(database, cfg.TokenVersionCacheTTL)
+		verifier.Versions = versions
+		log.Printf("Token version check enabled (cache TTL %s)", cfg.TokenVersionCacheTTL)
+
+		// Periodically drop cached versions of users who have stopped making requests
+		go func() {
+			for range time.Tick(10 * time.Minute) {
+				versions.Purge()
+			}
+		}()
+	}
+
+	// Periodically drop denylist entries for tokens that have expired anyway
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := denylist.Purge(); err != nil {
+				log.Printf("Failed to purge revoked tokens: %v", err)
+			}
+		}
+	}()
+
+	// Periodically drop device codes that expired an hour ago, freeing their user codes
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := queries.DeleteExpiredDeviceCodes(database, time.Now().Add(-time.Hour)); err != nil {
+				log.Printf("Failed to purge device codes: %v", err)
+			}
+		}
+	}()
+
+	// Rate limits for public endpoints, per route from RATE_LIMITS
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimitStore == "postgres" {
+		rateLimitStore = ratelimit.NewPostgresStore(database)
+	} else {
+		rateLimitStore = ratelimit.NewMemoryStore()
+	}
+	limiter := ratelimit.NewLimiter(rateLimitStore, cfg.TrustedProxies)
+	rateLimited := func(route string, handler http.Handler) http.Handler {
+		return ratelimit.RateLimitMiddleware(limiter, route, cfg.RateLimits[route]...)(handler)
+	}
+
+	// Periodically drop token buckets that have refilled completely
+	go func() {
+		for range time.Tick(10 * time.Minute) {
+			if err := rateLimitStore.Purge(); err != nil {
+				log.Printf("Failed to purge rate limit buckets: %v", err)
+			}
+		}
+	}()
+
+	// Checks applied by /login, /login/mfa, /login/webauthn/finish and the OAuth login pages
+	loginOptions := &auth.LoginOptions{
+		Breaches:             loginBreaches,
+		PasswordMaxAge:       cfg.PasswordMaxAge,
+		Lockout:              &cfg.Lockout,
+		RequireVerifiedEmail: emailVerification.Required,
+	}
+
 	// Setup routes
 	mux := http.NewServeMux()
 
 	// Public routes (no authentication required)
 	mux.HandleFunc("/health", handlers.HealthCheckHandler())
-	mux.HandleFunc("/register", auth.RegisterHandler(database, cfg.JWTSecret, cfg.DefaultRegistrationRole))
-	mux.HandleFunc("/login", auth.LoginHandler(database, cfg.JWTSecret))
-	mux.HandleFunc("/refresh", auth.RefreshTokenHandler(database, cfg.JWTSecret))
+	mux.HandleFunc("/.well-known/jwks.json", auth.JWKSHandler(keys))
+	mux.Handle("/register", rateLimited("/register", auth.RegisterHandler(database, keys, cfg.DefaultRegistrationRole, passwordRules, emailVerification)))
+	mux.HandleFunc("/password-policy", auth.PasswordPolicyHandler(&cfg.PasswordPolicy))
+	mux.Handle("/login", rateLimited("/login", auth.LoginHandler(database, keys, loginOptions)))
+	mux.Handle("/login/mfa", rateLimited("/login/mfa", auth.LoginMFAHandler(database, verifier, loginOptions)))
+	mux.Handle("/refresh", rateLimited("/refresh", auth.RefreshTokenHandler(database, verifier)))
+	mux.Handle("/password/forgot", rateLimited("/password/forgot", auth.ForgotPasswordHandler(database, mail, cfg.PasswordResetURL)))
+	mux.Handle("/password/reset", rateLimited("/password/reset", auth.ResetPasswordHandler(database, passwordRules)))
+	mux.Handle("/email/verify", rateLimited("/email/verify", auth.VerifyEmailHandler(database, verifier)))
+	mux.Handle("/email/verify/resend", rateLimited("/email/verify/resend", auth.ResendVerificationHandler(database, keys, emailVerification)))
+
+	// OAuth 2.0 authorization server (authorization code + PKCE, refresh token)
+	mux.Handle("/oauth/authorize", rateLimited("/oauth/authorize", oauth.AuthorizeHandler(database, loginOptions)))
+	mux.Handle("/oauth/token", rateLimited("/oauth/token", oauth.TokenHandler(database, verifier, cfg.OIDCEnabled)))
+
+	// Device authorization grant (RFC 8628) for CLIs: device requests a code, user approves at /oauth/device.
+	// The verification URI handed to devices is built from JWT_ISSUER, never from the request's Host
+	if cfg.IssuerIsURL() {
+		mux.Handle("/oauth/device_authorization", rateLimited("/oauth/device_authorization", oauth.DeviceAuthorizationHandler(database, keys)))
+		mux.Handle("/oauth/device", rateLimited("/oauth/device", oauth.DeviceVerificationHandler(database, loginOptions)))
+	} else {
+		log.Println("Device authorization grant disabled: JWT_ISSUER is not the service's base URL")
+	}
+
+	// Service-to-service routes (client credentials required)
+	mux.Handle("/introspect", rateLimited("/introspect", oauth.IntrospectHandler(database, verifier, cfg.IntrospectionClients)))
 
 	// Protected routes (authentication required)
-	authMiddleware := authmiddle.AuthMiddleware(cfg.JWTSecret)
-	mux.Handle("/profile", authMiddleware(http.HandlerFunc(user.GetProfileHandler(database))))
-	mux.Handle("/change-password", authMiddleware(http.HandlerFunc(auth.ChangePasswordHandler(database))))
+	authMiddleware := authmiddle.AuthMiddleware(verifier)
+
+	// /change-password also accepts the restricted token given to users who must change their password
+	passwordChangeMiddleware := authmiddle.AuthMiddleware(verifier, models.AccessToken, models.PasswordChangeToken)
+
+	mux.Handle("/profile", rateLimited("/profile", authMiddleware(http.HandlerFunc(user.GetProfileHandler(database)))))
+	mux.Handle("/change-password", rateLimited("/change-password", passwordChangeMiddleware(http.HandlerFunc(auth.ChangePasswordHandler(database, keys, passwordRules)))))
+	mux.Handle("/logout", rateLimited("/logout", authMiddleware(http.HandlerFunc(auth.LogoutHandler(database, verifier)))))
+	mux.Handle("/logout/all", rateLimited("/logout/all", authMiddleware(http.HandlerFunc(auth.LogoutAllHandler(database, denylist)))))
+
+	// API keys: GET lists, create and revoke the current user's keys
+	mux.Handle("/api-keys", rateLimited("/api-keys", authMiddleware(http.HandlerFunc(user.GetAPIKeysHandler(database)))))
+	mux.Handle("/api-keys/create", rateLimited("/api-keys/create", authMiddleware(http.HandlerFunc(user.CreateAPIKeyHandler(database, auditLog)))))
+	mux.Handle("/api-keys/revoke/", rateLimited("/api-keys/revoke/", authMiddleware(http.HandlerFunc(user.RevokeAPIKeyHandler(database, auditLog)))))
+
+	// Multi-factor authentication: GET status, TOTP enrollment, recovery codes
+	mux.Handle("/mfa", rateLimited("/mfa", authMiddleware(http.HandlerFunc(user.GetMFAStatusHandler(database)))))
+	mux.Handle("/mfa/totp/enroll", rateLimited("/mfa/totp/enroll", authMiddleware(http.HandlerFunc(user.EnrollTOTPHandler(database, cfg.MFAIssuer)))))
+	mux.Handle("/mfa/totp/confirm", rateLimited("/mfa/totp/confirm", authMiddleware(http.HandlerFunc(user.ConfirmTOTPHandler(database, auditLog)))))
+	mux.Handle("/mfa/disable", rateLimited("/mfa/disable", authMiddleware(http.HandlerFunc(user.DisableMFAHandler(database, auditLog)))))
+	mux.Handle("/mfa/recovery-codes", rateLimited("/mfa/recovery-codes", authMiddleware(http.HandlerFunc(user.RegenerateRecoveryCodesHandler(database, auditLog)))))
+
+	// Passkeys (WebAuthn): passwordless login and registration for signed-in users
+	if cfg.WebAuthnRPID != "" {
+		rp := &webauthn.RelyingParty{ID: cfg.WebAuthnRPID, Name: cfg.WebAuthnRPName, Origins: cfg.WebAuthnOrigins}
+		mux.Handle("/login/webauthn/begin", rateLimited("/login/webauthn/begin", auth.BeginWebAuthnLoginHandler(database, rp)))
+		mux.Handle("/login/webauthn/finish", rateLimited("/login/webauthn/finish", auth.FinishWebAuthnLoginHandler(database, keys, rp, loginOptions)))
+		mux.Handle("/webauthn/register/begin", rateLimited("/webauthn/register/begin", authMiddleware(http.HandlerFunc(user.BeginWebAuthnRegistrationHandler(database, rp)))))
+		mux.Handle("/webauthn/register/finish", rateLimited("/webauthn/register/finish", authMiddleware(http.HandlerFunc(user.FinishWebAuthnRegistrationHandler(database, rp, auditLog)))))
+		mux.Handle("/webauthn/credentials", rateLimited("/webauthn/credentials", authMiddleware(http.HandlerFunc(user.GetWebAuthnCredentialsHandler(database)))))
+		mux.Handle("/webauthn/credentials/delete/", rateLimited("/webauthn/credentials/delete/", authMiddleware(http.HandlerFunc(user.DeleteWebAuthnCredentialHandler(database, auditLog)))))
+
+		// Periodically drop ceremony challenges that were never answered
+		go func() {
+			for range time.Tick(time.Hour) {
+				if err := queries.DeleteExpiredWebAuthnChallenges(database); err != nil {
+					log.Printf("Failed to purge webauthn challenges: %v", err)
+				}
+			}
+		}()
+
+		log.Printf("Passkeys enabled (rp id %s, origins %v)", rp.ID, rp.Origins)
+	}
+
+	// OpenID Connect provider (discovery, ID tokens via /oauth/token, userinfo)
+	if cfg.OIDCEnabled {
+		mux.HandleFunc("/.well-known/openid-configuration", oauth.DiscoveryHandler(keys))
+		mux.Handle("/userinfo", rateLimited("/userinfo", authmiddle.ScopedAuthMiddleware(verifier, "openid")(http.HandlerFunc(oauth.UserInfoHandler(database)))))
+		log.Printf("OpenID Connect enabled (issuer %s)", keys.Issuer)
+	}
 
 	// Admin routes (authentication + role required)
 	roleMiddleware := middleware.RequireRole(superAdminRole)
@@ -79,10 +321,10 @@ func main() {
 	mux.Handle("/admin/users/get/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.GetUserHandler(database)))))
 	
 	// Create user: POST /admin/users/create
-	mux.Handle("/admin/users/create", authMiddleware(roleMiddleware(http.HandlerFunc(admin.CreateUserHandler(database, cfg.Roles)))))
+	mux.Handle("/admin/users/create", authMiddleware(roleMiddleware(http.HandlerFunc(admin.CreateUserHandler(database, cfg.Roles, passwordRules)))))
 	
 	// Update user: PATCH /admin/users/update/{uuid}
-	mux.Handle("/admin/users/update/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.UpdateUserHandler(database)))))
+	mux.Handle("/admin/users/update/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.UpdateUserHandler(database, keys, emailVerification)))))
 	
 	// Delete user: DELETE /admin/users/delete/{uuid}
 	mux.Handle("/admin/users/delete/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.DeleteUserHandler(database)))))
@@ -90,6 +332,51 @@ func main() {
 	// Update user role: PUT /admin/users/role/{uuid}
 	mux.Handle("/admin/users/role/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.UpdateUserRoleHandler(database, cfg.Roles)))))
 
+	// Force logout: POST /admin/users/logout/{uuid}
+	mux.Handle("/admin/users/logout/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.ForceLogoutUserHandler(database)))))
+
+	// Impersonate a user: POST /admin/users/impersonate/{uuid}
+	mux.Handle("/admin/users/impersonate/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.ImpersonateUserHandler(database, keys, auditLog, superAdminRole)))))
+
+	// Read the audit log: GET /admin/audit?actor_id=&subject_id=&action=&limit=
+	mux.Handle("/admin/audit", authMiddleware(roleMiddleware(http.HandlerFunc(admin.GetAuditLogHandler(database)))))
+
+	// Get all API keys: GET /admin/api-keys?user_id={uuid}
+	mux.Handle("/admin/api-keys", authMiddleware(roleMiddleware(http.HandlerFunc(admin.GetAllAPIKeysHandler(database)))))
+
+	// Create API key for a user: POST /admin/api-keys/create/{uuid}
+	mux.Handle("/admin/api-keys/create/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.CreateUserAPIKeyHandler(database, auditLog)))))
+
+	// Revoke any API key: POST /admin/api-keys/revoke/{uuid}
+	mux.Handle("/admin/api-keys/revoke/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.RevokeUserAPIKeyHandler(database, auditLog)))))
+
+	// Get all service accounts
+	mux.Handle("/admin/service-accounts", authMiddleware(roleMiddleware(http.HandlerFunc(admin.GetAllServiceAccountsHandler(database)))))
+
+	// Create service account: POST /admin/service-accounts/create
+	mux.Handle("/admin/service-accounts/create", authMiddleware(roleMiddleware(http.HandlerFunc(admin.CreateServiceAccountHandler(database, cfg.Roles, auditLog)))))
+
+	// Rotate service account secret: POST /admin/service-accounts/rotate/{uuid}
+	mux.Handle("/admin/service-accounts/rotate/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.RotateServiceAccountSecretHandler(database, auditLog)))))
+
+	// Disable service account: POST /admin/service-accounts/disable/{uuid}
+	mux.Handle("/admin/service-accounts/disable/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.DisableServiceAccountHandler(database, auditLog)))))
+
+	// Unlock a locked-out account: POST /admin/users/unlock/{uuid}
+	mux.Handle("/admin/users/unlock/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.UnlockUserHandler(database)))))
+
+	// Reset a user's MFA: POST /admin/users/mfa/reset/{uuid}
+	mux.Handle("/admin/users/mfa/reset/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.ResetUserMFAHandler(database, auditLog)))))
+
+	// Get all OAuth clients
+	mux.Handle("/admin/oauth/clients", authMiddleware(roleMiddleware(http.HandlerFunc(admin.GetAllOAuthClientsHandler(database)))))
+
+	// Register OAuth client: POST /admin/oauth/clients/create
+	mux.Handle("/admin/oauth/clients/create", authMiddleware(roleMiddleware(http.HandlerFunc(admin.CreateOAuthClientHandler(database)))))
+
+	// Delete OAuth client: DELETE /admin/oauth/clients/delete/{client_id}
+	mux.Handle("/admin/oauth/clients/delete/", authMiddleware(roleMiddleware(http.HandlerFunc(admin.DeleteOAuthClientHandler(database)))))
+
 	// Start server
 	log.Printf("Starting auth service on port %s", cfg.ServerPort)
 	if err := http.ListenAndServe(":"+cfg.ServerPort, mux); err != nil {
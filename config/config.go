@@ -3,15 +3,143 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"go-auth/models"
+	"go-auth/utils/password"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+// JWTSecretEntry is one HMAC secret in the JWT_SECRETS keyring
+type JWTSecretEntry struct {
+	KeyID    string     `json:"kid"`
+	Secret   string     `json:"secret"`
+	RetireAt *time.Time `json:"retire_at"`
+}
+
+// rateLimitEntry is one limit of a route in RATE_LIMITS
+type rateLimitEntry struct {
+	Key      string `json:"key"`
+	Requests int    `json:"requests"`
+	Per      string `json:"per"`
+}
+
+// defaultRateLimits apply to routes not listed in RATE_LIMITS
+var defaultRateLimits = map[string][]models.RateLimit{
+	"/register": {
+		{Key: models.RateLimitByIP, Requests: 20, Per: time.Hour},
+	},
+	"/login": {
+		{Key: models.RateLimitByIP, Requests: 30, Per: time.Minute},
+		{Key: models.RateLimitByAccount, Requests: 10, Per: time.Minute},
+	},
+	"/login/mfa": {
+		{Key: models.RateLimitByIP, Requests: 30, Per: time.Minute},
+	},
+	"/login/webauthn/begin": {
+		{Key: models.RateLimitByIP, Requests: 30, Per: time.Minute},
+	},
+	"/login/webauthn/finish": {
+		{Key: models.RateLimitByIP, Requests: 30, Per: time.Minute},
+	},
+	"/refresh": {
+		{Key: models.RateLimitByIP, Requests: 60, Per: time.Minute},
+	},
+	"/oauth/authorize": {
+		{Key: models.RateLimitByIP, Requests: 30, Per: time.Minute},
+		{Key: models.RateLimitByAccount, Requests: 10, Per: time.Minute},
+	},
+	"/oauth/device": {
+		{Key: models.RateLimitByIP, Requests: 30, Per: time.Minute},
+		{Key: models.RateLimitByAccount, Requests: 10, Per: time.Minute},
+	},
+	"/oauth/token": {
+		{Key: models.RateLimitByIP, Requests: 60, Per: time.Minute},
+	},
+	"/oauth/device_authorization": {
+		{Key: models.RateLimitByIP, Requests: 10, Per: time.Minute},
+	},
+	"/password/forgot": {
+		{Key: models.RateLimitByIP, Requests: 10, Per: time.Hour},
+		{Key: models.RateLimitByAccount, Requests: 3, Per: time.Hour},
+	},
+	"/password/reset": {
+		{Key: models.RateLimitByIP, Requests: 10, Per: time.Minute},
+	},
+	"/email/verify": {
+		{Key: models.RateLimitByIP, Requests: 10, Per: time.Minute},
+	},
+	"/email/verify/resend": {
+		{Key: models.RateLimitByIP, Requests: 10, Per: time.Hour},
+		{Key: models.RateLimitByAccount, Requests: 3, Per: time.Hour},
+	},
+}
+
+// rateLimitedRoutes are the routes RATE_LIMITS may configure
+var rateLimitedRoutes = []string{
+	"/register", "/login", "/login/mfa", "/refresh", "/password/forgot", "/password/reset",
+	"/email/verify", "/email/verify/resend",
+	"/oauth/authorize", "/oauth/token", "/oauth/device_authorization", "/oauth/device", "/introspect",
+	"/profile", "/change-password", "/logout", "/logout/all",
+	"/api-keys", "/api-keys/create", "/api-keys/revoke/", "/userinfo",
+	"/mfa", "/mfa/totp/enroll", "/mfa/totp/confirm", "/mfa/disable", "/mfa/recovery-codes",
+	"/login/webauthn/begin", "/login/webauthn/finish", "/webauthn/register/begin", "/webauthn/register/finish",
+	"/webauthn/credentials", "/webauthn/credentials/delete/",
+}
+
 type Config struct {
 	DBDriver                  string
 	DBSource                  string
 	JWTSecret                 string
+	JWTSecrets                []JWTSecretEntry
+	JWTAlgorithm              string
+	JWTKeyID                  string
+	JWTPrivateKeyPath         string
+	JWTVerificationKeyPaths   []string
+	JWTIssuer                 string
+	JWTAudience               []string
+	JWTLeeway                 time.Duration
 	ServerPort                string
+	RevocationStore           string
+	TokenVersionCheck         bool
+	TokenVersionCacheTTL      time.Duration
+	IntrospectionClients      map[string]string
+	OIDCEnabled               bool
+	PasswordHashAlgorithm     string
+	Argon2Memory              int
+	Argon2Iterations          int
+	Argon2Parallelism         int
+	Argon2SaltLength          int
+	Argon2KeyLength           int
+	BcryptCost                int
+	PasswordPolicy            models.PasswordPolicy
+	PasswordMaxAge            map[string]time.Duration
+	BreachCorpusPath          string
+	BreachCheckOnLogin        bool
+	Mailer                    string
+	MailerFrom                string
+	MailerFileDir             string
+	SMTPHost                  string
+	SMTPPort                  string
+	SMTPUsername              string
+	SMTPPassword              string
+	PasswordResetURL          string
+	EmailVerificationMode     string
+	EmailVerificationURL      string
+	Lockout                   models.LockoutPolicy
+	MFAIssuer                 string
+	WebAuthnRPID              string
+	WebAuthnRPName            string
+	WebAuthnOrigins           []string
+	RateLimitStore            string
+	RateLimits                map[string][]models.RateLimit
+	TrustedProxies            []*net.IPNet
 	Roles                     []string
 	DefaultRegistrationRole   string
 	SuperAdminEmail           string
@@ -24,7 +152,56 @@ func Load() *Config {
 		DBDriver:                getEnv("DB_DRIVER", "postgres"),
 		DBSource:                getEnv("DB_SOURCE", ""),
 		JWTSecret:               getEnv("JWT_SECRET", ""),
+		JWTAlgorithm:            strings.ToUpper(getEnv("JWT_ALGORITHM", "HS256")),
+		JWTKeyID:                getEnv("JWT_KEY_ID", ""),
+		JWTPrivateKeyPath:       getEnv("JWT_PRIVATE_KEY_PATH", ""),
+		JWTIssuer:               getEnv("JWT_ISSUER", ""),
+		JWTLeeway:               getEnvDuration("JWT_LEEWAY", 0),
 		ServerPort:              getEnv("SERVER_PORT", "8080"),
+		RevocationStore:         strings.ToLower(getEnv("REVOCATION_STORE", "postgres")),
+		TokenVersionCheck:       getEnvBool("TOKEN_VERSION_CHECK", false),
+		TokenVersionCacheTTL:    getEnvDuration("TOKEN_VERSION_CACHE_TTL", 30*time.Second),
+		OIDCEnabled:             getEnvBool("OIDC_ENABLED", false),
+		PasswordHashAlgorithm:   strings.ToLower(getEnv("PASSWORD_HASH_ALGORITHM", "argon2id")),
+		Argon2Memory:            getEnvInt("ARGON2_MEMORY", password.DefaultArgon2Memory),
+		Argon2Iterations:        getEnvInt("ARGON2_ITERATIONS", password.DefaultArgon2Iterations),
+		Argon2Parallelism:       getEnvInt("ARGON2_PARALLELISM", password.DefaultArgon2Parallelism),
+		Argon2SaltLength:        getEnvInt("ARGON2_SALT_LENGTH", password.DefaultArgon2SaltLength),
+		Argon2KeyLength:         getEnvInt("ARGON2_KEY_LENGTH", password.DefaultArgon2KeyLength),
+		BcryptCost:              getEnvInt("BCRYPT_COST", password.DefaultBcryptCost),
+		PasswordPolicy: models.PasswordPolicy{
+			MinLength:            getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:            getEnvInt("PASSWORD_MAX_LENGTH", 72),
+			RequireUppercase:     getEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase:     getEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:         getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:        getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			DisallowPersonalInfo: getEnvBool("PASSWORD_DISALLOW_PERSONAL_INFO", true),
+			HistoryDepth:         getEnvInt("PASSWORD_HISTORY_DEPTH", 5),
+		},
+		BreachCorpusPath:        getEnv("BREACH_CORPUS_PATH", ""),
+		BreachCheckOnLogin:      getEnvBool("BREACH_CHECK_ON_LOGIN", false),
+		Mailer:                  strings.ToLower(getEnv("MAILER", "log")),
+		MailerFrom:              getEnv("MAILER_FROM", "no-reply@localhost"),
+		MailerFileDir:           getEnv("MAILER_FILE_DIR", "mail"),
+		SMTPHost:                getEnv("SMTP_HOST", ""),
+		SMTPPort:                getEnv("SMTP_PORT", "587"),
+		SMTPUsername:            getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:            getEnv("SMTP_PASSWORD", ""),
+		PasswordResetURL:        getEnv("PASSWORD_RESET_URL", ""),
+		EmailVerificationMode:   strings.ToLower(getEnv("EMAIL_VERIFICATION_MODE", models.EmailVerificationOptional)),
+		EmailVerificationURL:    getEnv("EMAIL_VERIFICATION_URL", ""),
+		Lockout: models.LockoutPolicy{
+			Threshold:  getEnvInt("LOCKOUT_THRESHOLD", 10),
+			Duration:   getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+			DelayAfter: getEnvInt("LOGIN_DELAY_AFTER", 3),
+			DelayBase:  getEnvDuration("LOGIN_DELAY_BASE", time.Second),
+			DelayMax:   getEnvDuration("LOGIN_DELAY_MAX", 30*time.Second),
+		},
+		MFAIssuer:               getEnv("MFA_ISSUER", "Go Auth"),
+		WebAuthnRPID:            strings.ToLower(getEnv("WEBAUTHN_RP_ID", "")),
+		WebAuthnRPName:          getEnv("WEBAUTHN_RP_NAME", "Go Auth"),
+		RateLimitStore:          strings.ToLower(getEnv("RATE_LIMIT_STORE", "memory")),
 		DefaultRegistrationRole: getEnv("DEFAULT_REGISTRATION_ROLE", "User"),
 		SuperAdminEmail:         getEnv("SUPER_ADMIN_EMAIL", ""),
 		SuperAdminPassword:      getEnv("SUPER_ADMIN_PASSWORD", ""),
@@ -36,12 +213,104 @@ func Load() *Config {
 		panic(fmt.Sprintf("Failed to parse ROLES environment variable: %v", err))
 	}
 
+	// Parse JWT_VERIFICATION_KEY_PATHS from env (JSON array of PEM public key files)
+	verificationKeysEnv := getEnv("JWT_VERIFICATION_KEY_PATHS", "[]")
+	if err := json.Unmarshal([]byte(verificationKeysEnv), &config.JWTVerificationKeyPaths); err != nil {
+		panic(fmt.Sprintf("Failed to parse JWT_VERIFICATION_KEY_PATHS environment variable: %v", err))
+	}
+
+	// Parse JWT_AUDIENCE from env (JSON array of accepted audiences)
+	audienceEnv := getEnv("JWT_AUDIENCE", "[]")
+	if err := json.Unmarshal([]byte(audienceEnv), &config.JWTAudience); err != nil {
+		panic(fmt.Sprintf("Failed to parse JWT_AUDIENCE environment variable: %v", err))
+	}
+
+	// Parse JWT_SECRETS from env (JSON array of {kid, secret, retire_at})
+	secretsEnv := getEnv("JWT_SECRETS", "[]")
+	if err := json.Unmarshal([]byte(secretsEnv), &config.JWTSecrets); err != nil {
+		panic(fmt.Sprintf("Failed to parse JWT_SECRETS environment variable: %v", err))
+	}
+
+	// Parse INTROSPECTION_CLIENTS from env (JSON object of client_id -> secret)
+	introspectionEnv := getEnv("INTROSPECTION_CLIENTS", "{}")
+	if err := json.Unmarshal([]byte(introspectionEnv), &config.IntrospectionClients); err != nil {
+		panic(fmt.Sprintf("Failed to parse INTROSPECTION_CLIENTS environment variable: %v", err))
+	}
+
+	// Parse PASSWORD_MAX_AGE from env (JSON object of role -> duration, e.g. {"Super Admin": "2160h"})
+	maxAgeEnv := getEnv("PASSWORD_MAX_AGE", "{}")
+	var maxAges map[string]string
+	if err := json.Unmarshal([]byte(maxAgeEnv), &maxAges); err != nil {
+		panic(fmt.Sprintf("Failed to parse PASSWORD_MAX_AGE environment variable: %v", err))
+	}
+	config.PasswordMaxAge = make(map[string]time.Duration, len(maxAges))
+	for role, value := range maxAges {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge <= 0 {
+			panic(fmt.Sprintf("PASSWORD_MAX_AGE for role '%s' must be a positive duration", role))
+		}
+		config.PasswordMaxAge[role] = maxAge
+	}
+
+	// Parse RATE_LIMITS from env (JSON object of route -> [{key, requests, per}]); listed routes
+	// replace their defaults and an empty list turns limiting off for the route
+	rateLimitsEnv := getEnv("RATE_LIMITS", "{}")
+	var rateLimits map[string][]rateLimitEntry
+	if err := json.Unmarshal([]byte(rateLimitsEnv), &rateLimits); err != nil {
+		panic(fmt.Sprintf("Failed to parse RATE_LIMITS environment variable: %v", err))
+	}
+	config.RateLimits = make(map[string][]models.RateLimit, len(defaultRateLimits)+len(rateLimits))
+	for route, limits := range defaultRateLimits {
+		config.RateLimits[route] = limits
+	}
+	for route, entries := range rateLimits {
+		if !slices.Contains(rateLimitedRoutes, route) {
+			panic(fmt.Sprintf("RATE_LIMITS route '%s' can't be rate limited", route))
+		}
+		limits := make([]models.RateLimit, 0, len(entries))
+		for _, entry := range entries {
+			limit := parseRateLimit(route, entry)
+			if slices.Contains(limits, limit) {
+				panic(fmt.Sprintf("RATE_LIMITS route '%s' lists the same limit twice", route))
+			}
+			limits = append(limits, limit)
+		}
+		config.RateLimits[route] = limits
+	}
+
+	// Parse WEBAUTHN_ORIGINS from env (JSON array of origins passkey ceremonies may come from)
+	webAuthnOriginsEnv := getEnv("WEBAUTHN_ORIGINS", "[]")
+	if err := json.Unmarshal([]byte(webAuthnOriginsEnv), &config.WebAuthnOrigins); err != nil {
+		panic(fmt.Sprintf("Failed to parse WEBAUTHN_ORIGINS environment variable: %v", err))
+	}
+
+	// Parse TRUSTED_PROXIES from env (JSON array of IP addresses or CIDR ranges)
+	trustedProxiesEnv := getEnv("TRUSTED_PROXIES", "[]")
+	var trustedProxies []string
+	if err := json.Unmarshal([]byte(trustedProxiesEnv), &trustedProxies); err != nil {
+		panic(fmt.Sprintf("Failed to parse TRUSTED_PROXIES environment variable: %v", err))
+	}
+	for _, proxy := range trustedProxies {
+		config.TrustedProxies = append(config.TrustedProxies, parseTrustedProxy(proxy))
+	}
+
 	// Validate required fields
 	if config.DBSource == "" {
 		panic("DB_SOURCE environment variable is required")
 	}
-	if config.JWTSecret == "" {
-		panic("JWT_SECRET environment variable is required")
+	switch config.JWTAlgorithm {
+	case "HS256":
+		if len(config.JWTSecrets) > 0 {
+			validateJWTSecrets(config)
+		} else if config.JWTSecret == "" {
+			panic("JWT_SECRET environment variable is required")
+		}
+	case "RS256", "ES256", "EDDSA":
+		if config.JWTPrivateKeyPath == "" {
+			panic(fmt.Sprintf("JWT_PRIVATE_KEY_PATH environment variable is required for %s", config.JWTAlgorithm))
+		}
+	default:
+		panic(fmt.Sprintf("JWT_ALGORITHM '%s' is not supported (use HS256, RS256, ES256 or EdDSA)", config.JWTAlgorithm))
 	}
 	if config.SuperAdminEmail == "" {
 		panic("SUPER_ADMIN_EMAIL environment variable is required")
@@ -50,11 +319,59 @@ func Load() *Config {
 		panic("SUPER_ADMIN_PASSWORD environment variable is required")
 	}
 
+	// ID tokens must be verifiable by relying parties from the published JWKS
+	if config.OIDCEnabled {
+		if !config.IssuerIsURL() {
+			panic("JWT_ISSUER must be set to the service's base URL when OIDC_ENABLED is true")
+		}
+		if config.JWTAlgorithm == "HS256" {
+			panic("OIDC_ENABLED requires an asymmetric JWT_ALGORITHM (RS256, ES256 or EdDSA)")
+		}
+	}
+
+	validatePasswordHashing(config)
+	validatePasswordPolicy(config)
+
+	if config.BreachCheckOnLogin && config.BreachCorpusPath == "" {
+		panic("BREACH_CHECK_ON_LOGIN requires BREACH_CORPUS_PATH")
+	}
+	config.PasswordPolicy.RejectBreached = config.BreachCorpusPath != ""
+
+	validateLockout(config)
+	validateWebAuthn(config)
+
+	switch config.Mailer {
+	case "log", "file":
+	case "smtp":
+		if config.SMTPHost == "" {
+			panic("SMTP_HOST environment variable is required when MAILER is smtp")
+		}
+	default:
+		panic(fmt.Sprintf("MAILER '%s' is not supported (use log, file or smtp)", config.Mailer))
+	}
+	if config.EmailVerificationMode != models.EmailVerificationOptional && config.EmailVerificationMode != models.EmailVerificationRequired {
+		panic(fmt.Sprintf("EMAIL_VERIFICATION_MODE '%s' is not supported (use optional or required)", config.EmailVerificationMode))
+	}
+
+	if config.RevocationStore != "postgres" && config.RevocationStore != "memory" {
+		panic(fmt.Sprintf("REVOCATION_STORE '%s' is not supported (use postgres or memory)", config.RevocationStore))
+	}
+	if config.RateLimitStore != "postgres" && config.RateLimitStore != "memory" {
+		panic(fmt.Sprintf("RATE_LIMIT_STORE '%s' is not supported (use postgres or memory)", config.RateLimitStore))
+	}
+
 	// Validate roles
 	if len(config.Roles) == 0 {
 		panic("ROLES must contain at least one role")
 	}
 
+	// Password max ages must refer to configured roles
+	for role := range config.PasswordMaxAge {
+		if !slices.Contains(config.Roles, role) {
+			panic(fmt.Sprintf("PASSWORD_MAX_AGE role '%s' not found in ROLES", role))
+		}
+	}
+
 	// Validate default registration role exists in roles
 	roleExists := false
 	for _, role := range config.Roles {
@@ -70,6 +387,157 @@ func Load() *Config {
 	return config
 }
 
+// validateJWTSecrets checks the HMAC keyring and the active key selected by JWT_KEY_ID
+func validateJWTSecrets(config *Config) {
+	if config.JWTKeyID == "" {
+		if len(config.JWTSecrets) > 1 {
+			panic("JWT_KEY_ID must name the active secret when JWT_SECRETS has more than one entry")
+		}
+		config.JWTKeyID = config.JWTSecrets[0].KeyID
+	}
+
+	seen := make(map[string]bool)
+	activeFound := false
+	for _, entry := range config.JWTSecrets {
+		if entry.Secret == "" {
+			panic(fmt.Sprintf("JWT_SECRETS entry '%s' has an empty secret", entry.KeyID))
+		}
+		if seen[entry.KeyID] {
+			panic(fmt.Sprintf("JWT_SECRETS contains duplicate kid '%s'", entry.KeyID))
+		}
+		seen[entry.KeyID] = true
+
+		if entry.KeyID == config.JWTKeyID {
+			activeFound = true
+			if entry.RetireAt != nil && !entry.RetireAt.After(time.Now()) {
+				panic(fmt.Sprintf("active JWT secret '%s' is already retired", entry.KeyID))
+			}
+		}
+	}
+	if !activeFound {
+		panic(fmt.Sprintf("JWT_KEY_ID '%s' not found in JWT_SECRETS", config.JWTKeyID))
+	}
+}
+
+// validatePasswordHashing checks the hash algorithm and its cost parameters
+func validatePasswordHashing(config *Config) {
+	switch config.PasswordHashAlgorithm {
+	case "argon2id":
+		if config.Argon2Iterations < 1 {
+			panic("ARGON2_ITERATIONS must be at least 1")
+		}
+		if config.Argon2Parallelism < 1 || config.Argon2Parallelism > 255 {
+			panic("ARGON2_PARALLELISM must be between 1 and 255")
+		}
+		if config.Argon2Memory < 8*config.Argon2Parallelism {
+			panic("ARGON2_MEMORY must be at least 8 KiB per unit of ARGON2_PARALLELISM")
+		}
+		if config.Argon2SaltLength < 8 {
+			panic("ARGON2_SALT_LENGTH must be at least 8 bytes")
+		}
+		if config.Argon2KeyLength < 16 {
+			panic("ARGON2_KEY_LENGTH must be at least 16 bytes")
+		}
+	case "bcrypt":
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			panic(fmt.Sprintf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost))
+		}
+	default:
+		panic(fmt.Sprintf("PASSWORD_HASH_ALGORITHM '%s' is not supported (use argon2id or bcrypt)", config.PasswordHashAlgorithm))
+	}
+}
+
+// validatePasswordPolicy checks the limits of the password policy
+func validatePasswordPolicy(config *Config) {
+	policy := config.PasswordPolicy
+	if policy.MinLength < 1 {
+		panic("PASSWORD_MIN_LENGTH must be at least 1")
+	}
+	if policy.HistoryDepth < 0 {
+		panic("PASSWORD_HISTORY_DEPTH must not be negative")
+	}
+	if policy.MaxLength < policy.MinLength {
+		panic("PASSWORD_MAX_LENGTH must not be less than PASSWORD_MIN_LENGTH")
+	}
+	// bcrypt silently ignores everything after 72 bytes
+	if config.PasswordHashAlgorithm == "bcrypt" && policy.MaxLength > 72 {
+		panic("PASSWORD_MAX_LENGTH must be at most 72 when PASSWORD_HASH_ALGORITHM is bcrypt")
+	}
+}
+
+// validateLockout checks the login throttling thresholds and durations
+func validateLockout(config *Config) {
+	lockout := config.Lockout
+	if lockout.Threshold < 0 || lockout.DelayAfter < 0 {
+		panic("LOCKOUT_THRESHOLD and LOGIN_DELAY_AFTER must not be negative")
+	}
+	if lockout.Threshold > 0 && lockout.Duration <= 0 {
+		panic("LOCKOUT_DURATION must be positive when LOCKOUT_THRESHOLD is set")
+	}
+	if lockout.DelayAfter > 0 && (lockout.DelayBase <= 0 || lockout.DelayMax < lockout.DelayBase) {
+		panic("LOGIN_DELAY_BASE must be positive and LOGIN_DELAY_MAX at least LOGIN_DELAY_BASE")
+	}
+}
+
+// validateWebAuthn checks that passkey ceremonies can only come from origins within the RP ID.
+// Passkeys are disabled when WEBAUTHN_RP_ID is not set.
+func validateWebAuthn(config *Config) {
+	if config.WebAuthnRPID == "" {
+		return
+	}
+	if strings.ContainsAny(config.WebAuthnRPID, ":/") {
+		panic("WEBAUTHN_RP_ID must be a domain name such as example.com, without scheme or port")
+	}
+	if len(config.WebAuthnOrigins) == 0 {
+		config.WebAuthnOrigins = []string{"https://" + config.WebAuthnRPID}
+	}
+	for _, origin := range config.WebAuthnOrigins {
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Host == "" || parsed.Path != "" || parsed.RawQuery != "" {
+			panic(fmt.Sprintf("WEBAUTHN_ORIGINS entry '%s' must be an origin such as https://example.com", origin))
+		}
+		host := parsed.Hostname()
+		if host != config.WebAuthnRPID && !strings.HasSuffix(host, "."+config.WebAuthnRPID) {
+			panic(fmt.Sprintf("WEBAUTHN_ORIGINS entry '%s' is not within WEBAUTHN_RP_ID '%s'", origin, config.WebAuthnRPID))
+		}
+		if parsed.Scheme != "https" && !(parsed.Scheme == "http" && host == "localhost") {
+			panic(fmt.Sprintf("WEBAUTHN_ORIGINS entry '%s' must use https (http is only allowed for localhost)", origin))
+		}
+	}
+}
+
+// parseRateLimit converts and checks one RATE_LIMITS entry
+func parseRateLimit(route string, entry rateLimitEntry) models.RateLimit {
+	switch entry.Key {
+	case models.RateLimitByIP, models.RateLimitByAccount, models.RateLimitByAPIKey:
+	default:
+		panic(fmt.Sprintf("RATE_LIMITS key '%s' for route '%s' is not supported (use ip, account or api_key)", entry.Key, route))
+	}
+	if entry.Requests < 1 {
+		panic(fmt.Sprintf("RATE_LIMITS requests for route '%s' must be at least 1", route))
+	}
+	per, err := time.ParseDuration(entry.Per)
+	if err != nil || per <= 0 {
+		panic(fmt.Sprintf("RATE_LIMITS per for route '%s' must be a positive duration", route))
+	}
+	return models.RateLimit{Key: entry.Key, Requests: entry.Requests, Per: per}
+}
+
+// parseTrustedProxy parses a TRUSTED_PROXIES entry; a single address is a network of one
+func parseTrustedProxy(proxy string) *net.IPNet {
+	if ip := net.ParseIP(proxy); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+	}
+	_, network, err := net.ParseCIDR(proxy)
+	if err != nil {
+		panic(fmt.Sprintf("TRUSTED_PROXIES entry '%s' is not an IP address or CIDR range", proxy))
+	}
+	return network
+}
+
 // getEnv retrieves an environment variable with a fallback default
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -79,8 +547,53 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvBool retrieves a boolean environment variable with a fallback default
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse %s environment variable: %v", key, err))
+	}
+	return parsed
+}
+
+// getEnvInt retrieves an integer environment variable with a fallback default
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse %s environment variable: %v", key, err))
+	}
+	return parsed
+}
+
+// getEnvDuration retrieves a duration environment variable (e.g. "30s", "15m") with a fallback default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse %s environment variable: %v", key, err))
+	}
+	return parsed
+}
+
+// IssuerIsURL reports whether JWT_ISSUER is the service's base URL, so links to the
+// service's own pages can be built from it
+func (c *Config) IssuerIsURL() bool {
+	return strings.HasPrefix(c.JWTIssuer, "https://") || strings.HasPrefix(c.JWTIssuer, "http://")
+}
+
 // String returns a formatted string representation of the config
 func (c *Config) String() string {
-	return fmt.Sprintf("Config{Driver: %s, Port: %s, Roles: %v, DefaultRole: %s}", 
-		c.DBDriver, c.ServerPort, c.Roles, c.DefaultRegistrationRole)
+	return fmt.Sprintf("Config{Driver: %s, Port: %s, Roles: %v, DefaultRole: %s, JWTAlgorithm: %s}",
+		c.DBDriver, c.ServerPort, c.Roles, c.DefaultRegistrationRole, c.JWTAlgorithm)
 }
\ No newline at end of file
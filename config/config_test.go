@@ -0,0 +1,74 @@
+package config
+
+import (
+	"go-auth/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDefaultRateLimitsCoverConfigurableRoutes(t *testing.T) {
+	for route := range defaultRateLimits {
+		if !slices.Contains(rateLimitedRoutes, route) {
+			t.Errorf("default limits for %s, which RATE_LIMITS can't configure", route)
+		}
+	}
+}
+
+func TestDefaultRateLimitsProtectPasswordForms(t *testing.T) {
+	// Every route that checks a password, code or token without prior authentication
+	for _, route := range []string{
+		"/login", "/login/mfa", "/login/webauthn/finish", "/oauth/authorize", "/oauth/device",
+		"/oauth/token", "/password/reset", "/email/verify",
+	} {
+		hasIPLimit := slices.ContainsFunc(defaultRateLimits[route], func(limit models.RateLimit) bool {
+			return limit.Key == models.RateLimitByIP
+		})
+		if !hasIPLimit {
+			t.Errorf("%s has no default per-IP limit", route)
+		}
+	}
+}
+
+func TestValidateJWTSecrets(t *testing.T) {
+	retired := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name      string
+		keyID     string
+		secrets   []JWTSecretEntry
+		wantPanic bool
+	}{
+		{"single secret without kid", "", []JWTSecretEntry{{KeyID: "a", Secret: "s"}}, false},
+		{"several secrets without kid", "", []JWTSecretEntry{{KeyID: "a", Secret: "s"}, {KeyID: "b", Secret: "t"}}, true},
+		{"active secret selected", "b", []JWTSecretEntry{{KeyID: "a", Secret: "s", RetireAt: &retired}, {KeyID: "b", Secret: "t"}}, false},
+		{"active secret missing", "c", []JWTSecretEntry{{KeyID: "a", Secret: "s"}, {KeyID: "b", Secret: "t"}}, true},
+		{"active secret retired", "a", []JWTSecretEntry{{KeyID: "a", Secret: "s", RetireAt: &retired}, {KeyID: "b", Secret: "t"}}, true},
+		{"duplicate kid", "a", []JWTSecretEntry{{KeyID: "a", Secret: "s"}, {KeyID: "a", Secret: "t"}}, true},
+		{"empty secret", "a", []JWTSecretEntry{{KeyID: "a", Secret: "s"}, {KeyID: "b", Secret: ""}}, true},
+	}
+	for _, test := range tests {
+		config := &Config{JWTKeyID: test.keyID, JWTSecrets: test.secrets}
+		panicked := func() (panicked bool) {
+			defer func() { panicked = recover() != nil }()
+			validateJWTSecrets(config)
+			return false
+		}()
+		if panicked != test.wantPanic {
+			t.Errorf("%s: got panic %v, want %v", test.name, panicked, test.wantPanic)
+		}
+	}
+}
+
+func TestIssuerIsURL(t *testing.T) {
+	for issuer, want := range map[string]bool{
+		"":                         false,
+		"go-auth":                  false,
+		"auth.example.com":         false,
+		"https://auth.example.com": true,
+		"http://localhost:8080":    true,
+	} {
+		if got := (&Config{JWTIssuer: issuer}).IssuerIsURL(); got != want {
+			t.Errorf("IssuerIsURL(%q) = %v, want %v", issuer, got, want)
+		}
+	}
+}
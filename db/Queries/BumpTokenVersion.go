@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BumpTokenVersion increments a user's token_version, invalidating every token issued so far
+func BumpTokenVersion(db *sql.DB, userID string) error {
+	query := `
+	UPDATE users
+	SET token_version = token_version + 1, updated_at = $1
+	WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := db.Exec(query, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to bump token version: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
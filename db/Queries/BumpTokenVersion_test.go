@@ -0,0 +1,64 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"testing"
+)
+
+func TestTokenVersion(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	want := user.TokenVersion
+	check := func(step string) {
+		t.Helper()
+		version, err := queries.GetTokenVersion(db, user.ID)
+		if err != nil {
+			t.Fatalf("%s: GetTokenVersion: %v", step, err)
+		}
+		if version != want {
+			t.Fatalf("%s: token version = %d, want %d", step, version, want)
+		}
+	}
+	check("new user")
+
+	if err := queries.BumpTokenVersion(db, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+	want++
+	check("bump")
+
+	// Role changes invalidate tokens carrying the old role
+	if _, err := queries.UpdateUserRole(db, user.ID, "User"); err != nil {
+		t.Fatalf("UpdateUserRole: %v", err)
+	}
+	want++
+	check("role change")
+
+	// Only an email change invalidates tokens, since they carry the email and whether it is verified
+	if _, err := queries.UpdateUser(db, user.ID, user.Username+"_renamed", user.Email); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	check("username change")
+	updated, err := queries.UpdateUser(db, user.ID, user.Username+"_renamed", "changed_"+user.Email)
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	want++
+	check("email change")
+	if updated.TokenVersion != want {
+		t.Fatalf("UpdateUser returned token version %d, want %d", updated.TokenVersion, want)
+	}
+
+	// Deleted users have no token version, so their tokens are rejected
+	if err := queries.DeleteUser(db, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := queries.GetTokenVersion(db, user.ID); err != queries.ErrUserNotFound {
+		t.Fatalf("deleted user: got %v, want ErrUserNotFound", err)
+	}
+	if err := queries.BumpTokenVersion(db, user.ID); err != queries.ErrUserNotFound {
+		t.Fatalf("bump deleted user: got %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,57 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ChangePassword replaces a user's password, moving the old hash into password_history,
+// clearing must_change_password and restarting the password age. Only the historyDepth-1 most recent old hashes are
+// kept, since the current hash counts as the first of the last historyDepth passwords.
+// Unlike UpdatePassword (used for rehashing), this records a new password choice.
+func ChangePassword(db *sql.DB, userID string, hashedPassword string, historyDepth int) error {
+	query := `
+	WITH old AS (
+		SELECT id, password FROM users WHERE id = $1 FOR UPDATE
+	), saved AS (
+		INSERT INTO password_history (user_id, password_hash)
+		SELECT id, password FROM old WHERE $3 > 1
+	)
+	UPDATE users
+	SET password = $2, must_change_password = FALSE, password_changed_at = $4, updated_at = $4
+	FROM old
+	WHERE users.id = old.id
+	`
+
+	result, err := db.Exec(query, userID, hashedPassword, historyDepth, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	// Drop hashes that fell out of the history window
+	pruneQuery := `
+	DELETE FROM password_history
+	WHERE user_id = $1 AND id NOT IN (
+		SELECT id FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT GREATEST($2 - 1, 0)
+	)
+	`
+
+	if _, err := db.Exec(pruneQuery, userID, historyDepth); err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"reflect"
+	"testing"
+)
+
+func TestChangePasswordKeepsHistory(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	// With a depth of 3 the current hash and the 2 before it are remembered
+	for _, hash := range []string{"hash-1", "hash-2", "hash-3", "hash-4"} {
+		if err := queries.ChangePassword(db, user.ID, hash, 3); err != nil {
+			t.Fatalf("ChangePassword(%s): %v", hash, err)
+		}
+	}
+
+	history, err := queries.GetPasswordHistory(db, user.ID, 10)
+	if err != nil {
+		t.Fatalf("GetPasswordHistory: %v", err)
+	}
+	if want := []string{"hash-3", "hash-2"}; !reflect.DeepEqual(history, want) {
+		t.Errorf("history = %v, want %v", history, want)
+	}
+
+	history, err = queries.GetPasswordHistory(db, user.ID, 1)
+	if err != nil {
+		t.Fatalf("GetPasswordHistory: %v", err)
+	}
+	if want := []string{"hash-3"}; !reflect.DeepEqual(history, want) {
+		t.Errorf("limited history = %v, want %v", history, want)
+	}
+
+	stored, err := queries.GetUserByID(db, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if stored.Password != "hash-4" {
+		t.Errorf("password = %q, want hash-4", stored.Password)
+	}
+}
+
+func TestChangePasswordWithoutHistory(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	if err := queries.ChangePassword(db, user.ID, "hash-1", 3); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	// Lowering the depth to 1 drops the history that was kept
+	if err := queries.ChangePassword(db, user.ID, "hash-2", 1); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	history, err := queries.GetPasswordHistory(db, user.ID, 10)
+	if err != nil {
+		t.Fatalf("GetPasswordHistory: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("history = %v, want none", history)
+	}
+
+	if err := queries.ChangePassword(db, "00000000-0000-0000-0000-000000000000", "hash", 3); err != queries.ErrUserNotFound {
+		t.Errorf("unknown user: got %v, want ErrUserNotFound", err)
+	}
+}
@@ -3,8 +3,25 @@ package queries
 import "errors"
 
 var (
-	ErrUserNotFound     = errors.New("user not found")
-	ErrUserExists       = errors.New("user already exists")
-	ErrInvalidPassword  = errors.New("invalid password")
-	ErrRoleNotFound     = errors.New("role not found")
-)
\ No newline at end of file
+	ErrUserNotFound           = errors.New("user not found")
+	ErrUserExists             = errors.New("user already exists")
+	ErrInvalidPassword        = errors.New("invalid password")
+	ErrRoleNotFound           = errors.New("role not found")
+	ErrRefreshTokenNotFound   = errors.New("refresh token not found")
+	ErrRefreshTokenReused     = errors.New("refresh token reused")
+	ErrOAuthClientNotFound    = errors.New("oauth client not found")
+	ErrAuthCodeNotFound       = errors.New("authorization code not found")
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrDeviceCodeNotFound     = errors.New("device code not found")
+	ErrUserCodeTaken          = errors.New("user code already in use")
+	ErrResetTokenNotFound     = errors.New("password reset token not found")
+	ErrMFANotFound            = errors.New("mfa not enrolled")
+	ErrMFAAlreadyEnabled      = errors.New("mfa already enabled")
+	ErrTOTPCodeReused         = errors.New("totp code already used")
+	ErrRecoveryCodeNotFound   = errors.New("recovery code not found")
+	ErrChallengeNotFound      = errors.New("webauthn challenge not found")
+	ErrCredentialNotFound     = errors.New("webauthn credential not found")
+	ErrCredentialExists       = errors.New("webauthn credential already registered")
+	ErrCredentialCloned       = errors.New("webauthn signature counter did not increase")
+)
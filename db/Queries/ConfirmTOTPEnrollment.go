@@ -0,0 +1,45 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ConfirmTOTPEnrollment enables MFA for the user once they proved their app produces valid
+// codes, marking step as used and replacing any recovery codes with codeHashes.
+// Returns ErrMFANotFound if there is no unconfirmed enrollment.
+func ConfirmTOTPEnrollment(db *sql.DB, userID string, step int64, codeHashes []string) error {
+	query := `
+	WITH confirmed AS (
+		UPDATE user_mfa
+		SET confirmed_at = $3, last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL
+		RETURNING user_id
+	), cleared AS (
+		DELETE FROM mfa_recovery_codes
+		WHERE user_id IN (SELECT user_id FROM confirmed)
+	)
+	INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+	SELECT confirmed.user_id, code_hash, $3
+	FROM confirmed, unnest($4::text[]) AS code_hash
+	`
+
+	result, err := db.Exec(query, userID, step, time.Now(), pq.Array(codeHashes))
+	if err != nil {
+		return fmt.Errorf("failed to confirm totp enrollment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrMFANotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ConsumeAuthorizationCode atomically marks an authorization code issued to clientID as used
+// and returns it. A code presented by another client is left untouched.
+// Returns ErrAuthCodeNotFound if the code is unknown, issued to another client, already used or expired.
+func ConsumeAuthorizationCode(db *sql.DB, codeHash, clientID string) (*models.AuthorizationCode, error) {
+	code := &models.AuthorizationCode{}
+
+	query := `
+	UPDATE oauth_authorization_codes
+	SET used_at = $1
+	WHERE code_hash = $2 AND client_id = $3 AND used_at IS NULL AND expires_at > $1
+	RETURNING client_id, user_id, redirect_uri, redirect_uri_supplied, scope, code_challenge, code_challenge_method,
+		nonce, COALESCE(auth_time, created_at), amr, expires_at
+	`
+
+	err := db.QueryRow(query, time.Now(), codeHash, clientID).Scan(
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.RedirectURISupplied,
+		&code.Scope,
+		&code.CodeChallenge,
+		&code.CodeChallengeMethod,
+		&code.Nonce,
+		&code.AuthTime,
+		pq.Array(&code.AMR),
+		&code.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAuthCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,34 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+)
+
+// ConsumeDeviceCode atomically marks an approved, unexpired device code as used.
+// Returns ErrDeviceCodeNotFound if it was not approved or has already been used.
+func ConsumeDeviceCode(db *sql.DB, deviceCodeHash string) error {
+	query := `
+	UPDATE oauth_device_codes
+	SET status = $1
+	WHERE device_code_hash = $2 AND status = $3 AND expires_at > $4
+	`
+
+	result, err := db.Exec(query, models.DeviceCodeConsumed, deviceCodeHash, models.DeviceCodeApproved, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to consume device code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceCodeNotFound
+	}
+
+	return nil
+}
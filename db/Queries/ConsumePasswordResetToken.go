@@ -0,0 +1,38 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ConsumePasswordResetToken atomically marks a reset token as used, together with every
+// other outstanding reset token of the same user, and returns the user's ID.
+// Returns ErrResetTokenNotFound if the token is unknown, already used or expired.
+func ConsumePasswordResetToken(db *sql.DB, tokenHash string) (string, error) {
+	var userID string
+
+	query := `
+	WITH consumed AS (
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING user_id
+	), others AS (
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE user_id IN (SELECT user_id FROM consumed) AND token_hash <> $2 AND used_at IS NULL
+	)
+	SELECT user_id FROM consumed
+	`
+
+	err := db.QueryRow(query, time.Now(), tokenHash).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrResetTokenNotFound
+		}
+		return "", fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,40 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+)
+
+// ConsumeRefreshToken atomically marks an active refresh token as used and returns it.
+// Returns ErrRefreshTokenNotFound if the token is unknown, already used, revoked or expired.
+func ConsumeRefreshToken(db *sql.DB, tokenHash string) (*models.RefreshTokenRecord, error) {
+	record := &models.RefreshTokenRecord{}
+
+	query := `
+	UPDATE refresh_tokens
+	SET used_at = $1
+	WHERE token_hash = $2 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > $1
+	RETURNING id, user_id, family_id, expires_at, used_at, revoked_at, created_at
+	`
+
+	err := db.QueryRow(query, time.Now(), tokenHash).Scan(
+		&record.ID,
+		&record.UserID,
+		&record.FamilyID,
+		&record.ExpiresAt,
+		&record.UsedAt,
+		&record.RevokedAt,
+		&record.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to consume refresh token: %w", err)
+	}
+
+	return record, nil
+}
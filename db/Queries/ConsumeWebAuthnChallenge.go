@@ -0,0 +1,31 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ConsumeWebAuthnChallenge atomically marks a ceremony challenge as used and returns the
+// user it was issued to (empty for login challenges).
+// Returns ErrChallengeNotFound if it is unknown, already used, expired or for another ceremony.
+func ConsumeWebAuthnChallenge(db *sql.DB, challengeHash, ceremony string) (string, error) {
+	var userID string
+
+	query := `
+	UPDATE webauthn_challenges
+	SET used_at = $1
+	WHERE challenge_hash = $2 AND ceremony = $3 AND used_at IS NULL AND expires_at > $1
+	RETURNING COALESCE(user_id::text, '')
+	`
+
+	err := db.QueryRow(query, time.Now(), challengeHash, ceremony).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrChallengeNotFound
+		}
+		return "", fmt.Errorf("failed to consume webauthn challenge: %w", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,52 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"testing"
+	"time"
+)
+
+func TestConsumeWebAuthnChallenge(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	create := func(ceremony, userID string, expiresAt time.Time) string {
+		t.Helper()
+		hash := secure.HashToken(secure.RandomToken(32))
+		if err := queries.CreateWebAuthnChallenge(db, hash, ceremony, userID, expiresAt); err != nil {
+			t.Fatalf("CreateWebAuthnChallenge: %v", err)
+		}
+		return hash
+	}
+	later := time.Now().Add(time.Minute)
+
+	registration := create(models.WebAuthnCeremonyRegistration, user.ID, later)
+	login := create(models.WebAuthnCeremonyLogin, "", later)
+	expired := create(models.WebAuthnCeremonyLogin, "", time.Now().Add(-time.Second))
+
+	// A challenge only completes the ceremony it was issued for
+	if _, err := queries.ConsumeWebAuthnChallenge(db, registration, models.WebAuthnCeremonyLogin); err != queries.ErrChallengeNotFound {
+		t.Fatalf("other ceremony: got %v, want ErrChallengeNotFound", err)
+	}
+	if userID, err := queries.ConsumeWebAuthnChallenge(db, registration, models.WebAuthnCeremonyRegistration); err != nil || userID != user.ID {
+		t.Fatalf("registration: got %q, %v, want %q", userID, err, user.ID)
+	}
+	if userID, err := queries.ConsumeWebAuthnChallenge(db, login, models.WebAuthnCeremonyLogin); err != nil || userID != "" {
+		t.Fatalf("login: got %q, %v, want no user", userID, err)
+	}
+
+	for name, hash := range map[string]string{"used registration": registration, "used login": login, "expired": expired} {
+		for _, ceremony := range []string{models.WebAuthnCeremonyRegistration, models.WebAuthnCeremonyLogin} {
+			if _, err := queries.ConsumeWebAuthnChallenge(db, hash, ceremony); err != queries.ErrChallengeNotFound {
+				t.Errorf("%s as %s: got %v, want ErrChallengeNotFound", name, ceremony, err)
+			}
+		}
+	}
+
+	if err := queries.DeleteExpiredWebAuthnChallenges(db); err != nil {
+		t.Fatalf("DeleteExpiredWebAuthnChallenges: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// CountRecoveryCodes returns how many of the user's recovery codes are still unused
+func CountRecoveryCodes(db *sql.DB, userID string) (int, error) {
+	var count int
+
+	query := `
+	SELECT COUNT(*)
+	FROM mfa_recovery_codes
+	WHERE user_id = $1 AND used_at IS NULL
+	`
+
+	err := db.QueryRow(query, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
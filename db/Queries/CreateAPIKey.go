@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateAPIKey stores a new API key by its hash and fills in its ID and creation time
+func CreateAPIKey(db *sql.DB, key *models.APIKey, keyHash string) error {
+	key.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO api_keys (user_id, name, prefix, key_hash, role, scopes, expires_at, created_by, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id
+	`
+
+	err := db.QueryRow(query, key.UserID, key.Name, key.Prefix, keyHash, key.Role, pq.Array(key.Scopes),
+		key.ExpiresAt, key.CreatedBy, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
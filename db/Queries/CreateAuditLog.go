@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-auth/models"
+	"time"
+)
+
+// CreateAuditLog records an audit log entry
+func CreateAuditLog(db *sql.DB, entry *models.AuditLogEntry) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	if entry.Details == nil {
+		details = []byte("{}")
+	}
+
+	query := `
+	INSERT INTO audit_log (action, actor_id, subject_id, details, ip_address, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = db.Exec(query, entry.Action, entry.ActorID, entry.SubjectID, details, entry.IPAddress, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", err)
+	}
+
+	return nil
+}
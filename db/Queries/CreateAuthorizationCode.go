@@ -0,0 +1,27 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateAuthorizationCode stores the hash of a newly issued authorization code
+func CreateAuthorizationCode(db *sql.DB, codeHash string, code *models.AuthorizationCode) error {
+	query := `
+	INSERT INTO oauth_authorization_codes
+		(code_hash, client_id, user_id, redirect_uri, redirect_uri_supplied, scope, code_challenge, code_challenge_method, nonce, auth_time, amr, expires_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := db.Exec(query, codeHash, code.ClientID, code.UserID, code.RedirectURI, code.RedirectURISupplied, code.Scope,
+		code.CodeChallenge, code.CodeChallengeMethod, code.Nonce, code.AuthTime, pq.Array(code.AMR), code.ExpiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create authorization code: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+)
+
+// CreateDeviceCode stores the hash of a new device code together with its user code.
+// Returns ErrUserCodeTaken if another device code still has the same user code.
+func CreateDeviceCode(db *sql.DB, deviceCodeHash string, code *models.DeviceCode) error {
+	query := `
+	INSERT INTO oauth_device_codes (device_code_hash, user_code, client_id, scope, status, poll_interval, expires_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (user_code) DO NOTHING
+	`
+
+	result, err := db.Exec(query, deviceCodeHash, code.UserCode, code.ClientID, code.Scope,
+		models.DeviceCodePending, code.Interval, code.ExpiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create device code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserCodeTaken
+	}
+
+	return nil
+}
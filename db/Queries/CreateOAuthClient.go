@@ -0,0 +1,36 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateOAuthClient registers a new OAuth client. An empty secretHash registers a public client.
+func CreateOAuthClient(db *sql.DB, clientID, secretHash, name string, redirectURIs, allowedScopes []string) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{
+		ClientID:      clientID,
+		SecretHash:    secretHash,
+		Name:          name,
+		RedirectURIs:  redirectURIs,
+		AllowedScopes: allowedScopes,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	query := `
+	INSERT INTO oauth_clients (client_id, client_secret_hash, name, redirect_uris, allowed_scopes, created_at, updated_at)
+	VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7)
+	RETURNING id
+	`
+
+	err := db.QueryRow(query, clientID, secretHash, name, pq.Array(redirectURIs), pq.Array(allowedScopes), client.CreatedAt, client.UpdatedAt).Scan(&client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create oauth client: %w", err)
+	}
+
+	return client, nil
+}
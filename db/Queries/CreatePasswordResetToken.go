@@ -0,0 +1,22 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreatePasswordResetToken stores the hash of a password reset token for a user
+func CreatePasswordResetToken(db *sql.DB, tokenHash, userID string, expiresAt time.Time) error {
+	query := `
+	INSERT INTO password_reset_tokens (token_hash, user_id, expires_at)
+	VALUES ($1, $2, $3)
+	`
+
+	_, err := db.Exec(query, tokenHash, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
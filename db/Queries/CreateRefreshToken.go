@@ -0,0 +1,22 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateRefreshToken stores the hash of a newly issued refresh token in its family
+func CreateRefreshToken(db *sql.DB, userID, familyID, tokenHash string, expiresAt time.Time) error {
+	query := `
+	INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := db.Exec(query, userID, familyID, tokenHash, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
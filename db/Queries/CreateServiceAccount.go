@@ -0,0 +1,36 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateServiceAccount inserts a new service account with a hashed client secret
+func CreateServiceAccount(db *sql.DB, clientID, secretHash, name, role string, scopes []string) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{
+		ClientID:   clientID,
+		SecretHash: secretHash,
+		Name:       name,
+		Role:       role,
+		Scopes:     scopes,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	query := `
+	INSERT INTO service_accounts (client_id, secret_hash, name, role, scopes, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id
+	`
+
+	err := db.QueryRow(query, clientID, secretHash, name, role, pq.Array(scopes), account.CreatedAt, account.UpdatedAt).Scan(&account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	return account, nil
+}
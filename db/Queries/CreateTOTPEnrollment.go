@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateTOTPEnrollment stores a new, unconfirmed TOTP secret for the user, replacing any
+// earlier unconfirmed one. Returns ErrMFAAlreadyEnabled if the user has confirmed MFA.
+func CreateTOTPEnrollment(db *sql.DB, userID, secret string) error {
+	query := `
+	INSERT INTO user_mfa (user_id, totp_secret, created_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id) DO UPDATE
+	SET totp_secret = EXCLUDED.totp_secret, last_used_step = 0, created_at = EXCLUDED.created_at
+	WHERE user_mfa.confirmed_at IS NULL
+	`
+
+	result, err := db.Exec(query, userID, secret, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create totp enrollment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
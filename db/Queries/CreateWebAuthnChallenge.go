@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateWebAuthnChallenge stores the hash of a ceremony challenge. userID is the registering
+// user, or empty for a login where the user is not known yet.
+func CreateWebAuthnChallenge(db *sql.DB, challengeHash, ceremony, userID string, expiresAt time.Time) error {
+	query := `
+	INSERT INTO webauthn_challenges (challenge_hash, ceremony, user_id, expires_at)
+	VALUES ($1, $2, NULLIF($3, '')::uuid, $4)
+	`
+
+	_, err := db.Exec(query, challengeHash, ceremony, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webauthn challenge: %w", err)
+	}
+
+	return nil
+}
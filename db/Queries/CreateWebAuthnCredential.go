@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CreateWebAuthnCredential stores a newly registered credential and fills in its creation time.
+// Returns ErrCredentialExists if the credential ID is already registered.
+func CreateWebAuthnCredential(db *sql.DB, credential *models.WebAuthnCredential) error {
+	credential.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO webauthn_credentials (id, user_id, name, public_key, sign_count, transports, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (id) DO NOTHING
+	`
+
+	result, err := db.Exec(query, credential.ID, credential.UserID, credential.Name, credential.PublicKey,
+		int64(credential.SignCount), pq.Array(credential.Transports), credential.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webauthn credential: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCredentialExists
+	}
+
+	return nil
+}
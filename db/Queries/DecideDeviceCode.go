@@ -0,0 +1,41 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DecideDeviceCode records a user's approval or denial of a pending device authorization,
+// along with how and when the user authenticated. Returns ErrDeviceCodeNotFound if the code is unknown, expired or already decided.
+func DecideDeviceCode(db *sql.DB, userCode, userID string, approved bool, amr []string) error {
+	status := models.DeviceCodeDenied
+	if approved {
+		status = models.DeviceCodeApproved
+	}
+
+	query := `
+	UPDATE oauth_device_codes
+	SET status = $1, user_id = $2, amr = $3, auth_time = $6
+	WHERE user_code = $4 AND status = $5 AND expires_at > $6
+	`
+
+	result, err := db.Exec(query, status, userID, pq.Array(amr), userCode, models.DeviceCodePending, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update device code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrDeviceCodeNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeleteExpiredDeviceCodes removes device codes that expired before the given time, freeing
+// their user codes. Keeping them for a while lets late polls still get expired_token.
+func DeleteExpiredDeviceCodes(db *sql.DB, before time.Time) error {
+	query := `
+	DELETE FROM oauth_device_codes
+	WHERE expires_at <= $1
+	`
+
+	_, err := db.Exec(query, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired device codes: %w", err)
+	}
+
+	return nil
+}
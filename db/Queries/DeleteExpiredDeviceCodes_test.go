@@ -0,0 +1,82 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"testing"
+	"time"
+)
+
+func TestDeviceCodeUserCodes(t *testing.T) {
+	db := dbtest.Open(t)
+	client := dbtest.CreateOAuthClient(t, db, "https://app.example.com/callback")
+
+	userCode := "TEST" + secure.RandomCode("BCDFGHJKLMNPQRSTVWXZ", 4)
+	create := func(expiresAt time.Time) error {
+		return queries.CreateDeviceCode(db, secure.HashToken(secure.RandomToken(32)), &models.DeviceCode{
+			ClientID:  client.ClientID,
+			UserCode:  userCode,
+			Interval:  5,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	// An expired code keeps its user code until it is purged
+	if err := create(time.Now().Add(-2 * time.Hour)); err != nil {
+		t.Fatalf("CreateDeviceCode: %v", err)
+	}
+	if err := create(time.Now().Add(models.DeviceCodeDuration)); err != queries.ErrUserCodeTaken {
+		t.Fatalf("user code in use: got %v, want ErrUserCodeTaken", err)
+	}
+
+	if err := queries.DeleteExpiredDeviceCodes(db, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("DeleteExpiredDeviceCodes: %v", err)
+	}
+	if err := create(time.Now().Add(models.DeviceCodeDuration)); err != nil {
+		t.Fatalf("user code after purge: %v", err)
+	}
+
+	// Unexpired codes are never purged
+	if err := queries.DeleteExpiredDeviceCodes(db, time.Now()); err != nil {
+		t.Fatalf("DeleteExpiredDeviceCodes: %v", err)
+	}
+	if _, err := queries.GetPendingDeviceCode(db, userCode); err != nil {
+		t.Fatalf("GetPendingDeviceCode after purge: %v", err)
+	}
+}
+
+func TestDecideDeviceCodeRecordsAuthTime(t *testing.T) {
+	db := dbtest.Open(t)
+	client := dbtest.CreateOAuthClient(t, db, "https://app.example.com/callback")
+	user := dbtest.CreateUser(t, db)
+
+	deviceCodeHash := secure.HashToken(secure.RandomToken(32))
+	userCode := "TEST" + secure.RandomCode("BCDFGHJKLMNPQRSTVWXZ", 4)
+	err := queries.CreateDeviceCode(db, deviceCodeHash, &models.DeviceCode{
+		ClientID:  client.ClientID,
+		UserCode:  userCode,
+		Interval:  5,
+		ExpiresAt: time.Now().Add(models.DeviceCodeDuration),
+	})
+	if err != nil {
+		t.Fatalf("CreateDeviceCode: %v", err)
+	}
+
+	decidedAt := time.Now()
+	if err := queries.DecideDeviceCode(db, userCode, user.ID, true, models.PasswordAMR); err != nil {
+		t.Fatalf("DecideDeviceCode: %v", err)
+	}
+
+	code, err := queries.PollDeviceCode(db, deviceCodeHash)
+	if err != nil {
+		t.Fatalf("PollDeviceCode: %v", err)
+	}
+	if code.Status != models.DeviceCodeApproved || code.UserID != user.ID {
+		t.Fatalf("got status %s for user %s", code.Status, code.UserID)
+	}
+	if code.AuthTime.Sub(decidedAt).Abs() > 5*time.Second {
+		t.Fatalf("got auth time %v, want about %v", code.AuthTime, decidedAt)
+	}
+}
@@ -0,0 +1,22 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeleteExpiredRevokedTokens removes denylist entries whose tokens have expired anyway
+func DeleteExpiredRevokedTokens(db *sql.DB) error {
+	query := `
+	DELETE FROM revoked_tokens
+	WHERE expires_at <= $1
+	`
+
+	_, err := db.Exec(query, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired revoked tokens: %w", err)
+	}
+
+	return nil
+}
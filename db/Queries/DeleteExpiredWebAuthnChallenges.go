@@ -0,0 +1,22 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeleteExpiredWebAuthnChallenges removes ceremony challenges that can no longer be used
+func DeleteExpiredWebAuthnChallenges(db *sql.DB) error {
+	query := `
+	DELETE FROM webauthn_challenges
+	WHERE expires_at <= $1
+	`
+
+	_, err := db.Exec(query, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired webauthn challenges: %w", err)
+	}
+
+	return nil
+}
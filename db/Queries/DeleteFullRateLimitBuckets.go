@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DeleteFullRateLimitBuckets removes token buckets that have refilled completely.
+// A missing bucket starts out full, so this does not change any limit.
+func DeleteFullRateLimitBuckets(db *sql.DB) error {
+	query := `
+	DELETE FROM rate_limit_buckets
+	WHERE full_at <= $1
+	`
+
+	_, err := db.Exec(query, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete full rate limit buckets: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DeleteOAuthClient removes an OAuth client and its outstanding authorization codes
+func DeleteOAuthClient(db *sql.DB, clientID string) error {
+	query := `DELETE FROM oauth_clients WHERE client_id = $1`
+
+	result, err := db.Exec(query, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete oauth client: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrOAuthClientNotFound
+	}
+
+	return nil
+}
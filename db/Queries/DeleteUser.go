@@ -10,7 +10,7 @@ import (
 func DeleteUser(db *sql.DB, userID string) error {
 	query := `
 	UPDATE users
-	SET deleted_at = $1, updated_at = $2
+	SET deleted_at = $1, token_version = token_version + 1, updated_at = $2
 	WHERE id = $3 AND deleted_at IS NULL
 	`
 
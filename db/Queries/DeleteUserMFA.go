@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DeleteUserMFA turns MFA off for the user, removing the TOTP secret and all recovery codes.
+// Returns ErrMFANotFound if the user had no enrollment.
+func DeleteUserMFA(db *sql.DB, userID string) error {
+	query := `
+	WITH cleared AS (
+		DELETE FROM mfa_recovery_codes
+		WHERE user_id = $1
+	)
+	DELETE FROM user_mfa
+	WHERE user_id = $1
+	`
+
+	result, err := db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user mfa: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrMFANotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DeleteWebAuthnCredential removes one of a user's credentials
+func DeleteWebAuthnCredential(db *sql.DB, id, userID string) error {
+	query := `
+	DELETE FROM webauthn_credentials
+	WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := db.Exec(query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webauthn credential: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCredentialNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DisableServiceAccount stops a service account from getting tokens and invalidates its current ones
+func DisableServiceAccount(db *sql.DB, id string) error {
+	query := `
+	UPDATE service_accounts
+	SET disabled_at = $1, token_version = token_version + 1, updated_at = $1
+	WHERE id = $2 AND disabled_at IS NULL
+	`
+
+	result, err := db.Exec(query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to disable service account: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrServiceAccountNotFound
+	}
+
+	return nil
+}
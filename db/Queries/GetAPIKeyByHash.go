@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetAPIKeyByHash retrieves an API key (including revoked and expired ones) by its hash
+func GetAPIKeyByHash(db *sql.DB, keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+
+	query := `
+	SELECT id, user_id, name, prefix, role, scopes, expires_at, last_used_at, revoked_at, created_by, created_at
+	FROM api_keys
+	WHERE key_hash = $1
+	`
+
+	err := db.QueryRow(query, keyHash).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.Role,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,53 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetAPIKeys retrieves the API keys owned by a user, or every key when userID is empty
+func GetAPIKeys(db *sql.DB, userID string) ([]*models.APIKey, error) {
+	query := `
+	SELECT id, user_id, name, prefix, role, scopes, expires_at, last_used_at, revoked_at, created_by, created_at
+	FROM api_keys
+	WHERE ($1 = '' OR user_id::text = $1)
+	ORDER BY created_at DESC
+	`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key := &models.APIKey{}
+		err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.Prefix,
+			&key.Role,
+			pq.Array(&key.Scopes),
+			&key.ExpiresAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+			&key.CreatedBy,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
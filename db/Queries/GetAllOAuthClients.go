@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetAllOAuthClients retrieves all registered OAuth clients
+func GetAllOAuthClients(db *sql.DB) ([]*models.OAuthClient, error) {
+	query := `
+	SELECT id, client_id, COALESCE(client_secret_hash, ''), name, redirect_uris, allowed_scopes, created_at, updated_at
+	FROM oauth_clients
+	ORDER BY created_at DESC
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query oauth clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []*models.OAuthClient
+	for rows.Next() {
+		client := &models.OAuthClient{}
+		err := rows.Scan(
+			&client.ID,
+			&client.ClientID,
+			&client.SecretHash,
+			&client.Name,
+			pq.Array(&client.RedirectURIs),
+			pq.Array(&client.AllowedScopes),
+			&client.CreatedAt,
+			&client.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan oauth client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating oauth clients: %w", err)
+	}
+
+	return clients, nil
+}
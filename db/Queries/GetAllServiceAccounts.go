@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetAllServiceAccounts retrieves all service accounts, including disabled ones
+func GetAllServiceAccounts(db *sql.DB) ([]*models.ServiceAccount, error) {
+	query := `
+	SELECT id, client_id, name, role, scopes, disabled_at, created_at, updated_at
+	FROM service_accounts
+	ORDER BY created_at DESC
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*models.ServiceAccount
+	for rows.Next() {
+		account := &models.ServiceAccount{}
+		err := rows.Scan(
+			&account.ID,
+			&account.ClientID,
+			&account.Name,
+			&account.Role,
+			pq.Array(&account.Scopes),
+			&account.DisabledAt,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan service account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating service accounts: %w", err)
+	}
+
+	return accounts, nil
+}
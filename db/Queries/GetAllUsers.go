@@ -9,7 +9,8 @@ import (
 // GetAllUsers retrieves all users from the database (excludes soft-deleted users)
 func GetAllUsers(db *sql.DB) ([]*models.User, error) {
 	query := `
-	SELECT id, username, email, password, role, deleted_at, created_at, updated_at
+	SELECT id, username, email, password, role, must_change_password,
+		failed_login_attempts, last_failed_login_at, locked_until, email_verified_at, deleted_at, created_at, updated_at
 	FROM users
 	WHERE deleted_at IS NULL
 	ORDER BY created_at DESC
@@ -30,6 +31,11 @@ func GetAllUsers(db *sql.DB) ([]*models.User, error) {
 			&user.Email,
 			&user.Password,
 			&user.Role,
+		&user.MustChangePassword,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.EmailVerifiedAt,
 			&user.DeletedAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
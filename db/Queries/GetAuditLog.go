@@ -0,0 +1,55 @@
+package queries
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-auth/models"
+)
+
+// GetAuditLog retrieves the most recent audit log entries matching the filter
+func GetAuditLog(db *sql.DB, filter models.AuditLogFilter) ([]*models.AuditLogEntry, error) {
+	query := `
+	SELECT id, action, actor_id, subject_id, details, ip_address, created_at
+	FROM audit_log
+	WHERE ($1 = '' OR actor_id = $1)
+	  AND ($2 = '' OR subject_id = $2)
+	  AND ($3 = '' OR action = $3)
+	ORDER BY created_at DESC
+	LIMIT $4
+	`
+
+	rows, err := db.Query(query, filter.ActorID, filter.SubjectID, filter.Action, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditLogEntry
+	for rows.Next() {
+		entry := &models.AuditLogEntry{}
+		var details []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&entry.ActorID,
+			&entry.SubjectID,
+			&details,
+			&entry.IPAddress,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode audit details: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+
+	return entries, nil
+}
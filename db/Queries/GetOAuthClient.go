@@ -0,0 +1,40 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetOAuthClient retrieves a registered OAuth client by its client_id
+func GetOAuthClient(db *sql.DB, clientID string) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{}
+
+	query := `
+	SELECT id, client_id, COALESCE(client_secret_hash, ''), name, redirect_uris, allowed_scopes, created_at, updated_at
+	FROM oauth_clients
+	WHERE client_id = $1
+	`
+
+	err := db.QueryRow(query, clientID).Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.SecretHash,
+		&client.Name,
+		pq.Array(&client.RedirectURIs),
+		pq.Array(&client.AllowedScopes),
+		&client.CreatedAt,
+		&client.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+
+	return client, nil
+}
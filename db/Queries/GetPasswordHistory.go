@@ -0,0 +1,38 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetPasswordHistory returns up to limit of a user's previous password hashes, newest first
+func GetPasswordHistory(db *sql.DB, userID string, limit int) ([]string, error) {
+	query := `
+	SELECT password_hash
+	FROM password_history
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2
+	`
+
+	rows, err := db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query password history: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating password history: %w", err)
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetPasswordResetToken returns the ID of the user a reset token belongs to, without using it up.
+// Returns ErrResetTokenNotFound if the token is unknown, already used or expired.
+func GetPasswordResetToken(db *sql.DB, tokenHash string) (string, error) {
+	var userID string
+
+	query := `
+	SELECT user_id
+	FROM password_reset_tokens
+	WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+	`
+
+	err := db.QueryRow(query, tokenHash, time.Now()).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrResetTokenNotFound
+		}
+		return "", fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	return userID, nil
+}
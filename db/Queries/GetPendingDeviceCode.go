@@ -0,0 +1,37 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+)
+
+// GetPendingDeviceCode retrieves an unexpired device authorization awaiting a decision by its user code
+func GetPendingDeviceCode(db *sql.DB, userCode string) (*models.DeviceCode, error) {
+	code := &models.DeviceCode{}
+
+	query := `
+	SELECT client_id, user_code, scope, status, poll_interval, expires_at
+	FROM oauth_device_codes
+	WHERE user_code = $1 AND status = $2 AND expires_at > $3
+	`
+
+	err := db.QueryRow(query, userCode, models.DeviceCodePending, time.Now()).Scan(
+		&code.ClientID,
+		&code.UserCode,
+		&code.Scope,
+		&code.Status,
+		&code.Interval,
+		&code.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDeviceCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get device code: %w", err)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,37 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+)
+
+// GetRefreshTokenByHash retrieves a stored refresh token regardless of its state
+func GetRefreshTokenByHash(db *sql.DB, tokenHash string) (*models.RefreshTokenRecord, error) {
+	record := &models.RefreshTokenRecord{}
+
+	query := `
+	SELECT id, user_id, family_id, expires_at, used_at, revoked_at, created_at
+	FROM refresh_tokens
+	WHERE token_hash = $1
+	`
+
+	err := db.QueryRow(query, tokenHash).Scan(
+		&record.ID,
+		&record.UserID,
+		&record.FamilyID,
+		&record.ExpiresAt,
+		&record.UsedAt,
+		&record.RevokedAt,
+		&record.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,58 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetServiceAccount retrieves a service account (including disabled ones) by its ID
+func GetServiceAccount(db *sql.DB, id string) (*models.ServiceAccount, error) {
+	query := `
+	SELECT id, client_id, secret_hash, name, role, scopes, token_version, disabled_at, created_at, updated_at
+	FROM service_accounts
+	WHERE id = $1
+	`
+
+	return scanServiceAccount(db.QueryRow(query, id))
+}
+
+// GetServiceAccountByClientID retrieves a service account (including disabled ones) by its client_id
+func GetServiceAccountByClientID(db *sql.DB, clientID string) (*models.ServiceAccount, error) {
+	query := `
+	SELECT id, client_id, secret_hash, name, role, scopes, token_version, disabled_at, created_at, updated_at
+	FROM service_accounts
+	WHERE client_id = $1
+	`
+
+	return scanServiceAccount(db.QueryRow(query, clientID))
+}
+
+// scanServiceAccount scans a single service_accounts row
+func scanServiceAccount(row *sql.Row) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{}
+
+	err := row.Scan(
+		&account.ID,
+		&account.ClientID,
+		&account.SecretHash,
+		&account.Name,
+		&account.Role,
+		pq.Array(&account.Scopes),
+		&account.TokenVersion,
+		&account.DisabledAt,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	return account, nil
+}
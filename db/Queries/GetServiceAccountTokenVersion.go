@@ -0,0 +1,27 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetServiceAccountTokenVersion retrieves an active service account's current token_version
+func GetServiceAccountTokenVersion(db *sql.DB, id string) (int, error) {
+	var version int
+
+	query := `
+	SELECT token_version
+	FROM service_accounts
+	WHERE id = $1 AND disabled_at IS NULL
+	`
+
+	err := db.QueryRow(query, id).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrServiceAccountNotFound
+		}
+		return 0, fmt.Errorf("failed to get service account token version: %w", err)
+	}
+
+	return version, nil
+}
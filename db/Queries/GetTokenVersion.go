@@ -0,0 +1,27 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetTokenVersion retrieves a user's current token_version (excludes soft-deleted users)
+func GetTokenVersion(db *sql.DB, userID string) (int, error) {
+	var version int
+
+	query := `
+	SELECT token_version
+	FROM users
+	WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := db.QueryRow(query, userID).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("failed to get token version: %w", err)
+	}
+
+	return version, nil
+}
@@ -11,7 +11,8 @@ func GetUserByEmail(db *sql.DB, email string) (*models.User, error) {
 	user := &models.User{}
 
 	query := `
-	SELECT id, username, email, password, role, deleted_at, created_at, updated_at
+	SELECT id, username, email, password, role, token_version, must_change_password, password_changed_at,
+		failed_login_attempts, last_failed_login_at, locked_until, email_verified_at, deleted_at, created_at, updated_at
 	FROM users
 	WHERE email = $1 AND deleted_at IS NULL
 	`
@@ -22,6 +23,13 @@ func GetUserByEmail(db *sql.DB, email string) (*models.User, error) {
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.TokenVersion,
+		&user.MustChangePassword,
+		&user.PasswordChangedAt,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.EmailVerifiedAt,
 		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
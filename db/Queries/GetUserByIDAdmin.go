@@ -11,7 +11,8 @@ func GetUserByIDAdmin(db *sql.DB, id string) (*models.User, error) {
 	user := &models.User{}
 
 	query := `
-	SELECT id, username, email, password, role, deleted_at, created_at, updated_at
+	SELECT id, username, email, password, role, must_change_password,
+		failed_login_attempts, last_failed_login_at, locked_until, email_verified_at, deleted_at, created_at, updated_at
 	FROM users
 	WHERE id = $1
 	`
@@ -22,6 +23,11 @@ func GetUserByIDAdmin(db *sql.DB, id string) (*models.User, error) {
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.MustChangePassword,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.EmailVerifiedAt,
 		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -0,0 +1,36 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+)
+
+// GetUserMFA retrieves the user's TOTP enrollment, confirmed or not.
+// Returns ErrMFANotFound if the user never started enrolling.
+func GetUserMFA(db *sql.DB, userID string) (*models.UserMFA, error) {
+	mfa := &models.UserMFA{}
+
+	query := `
+	SELECT user_id, totp_secret, confirmed_at, last_used_step, created_at
+	FROM user_mfa
+	WHERE user_id = $1
+	`
+
+	err := db.QueryRow(query, userID).Scan(
+		&mfa.UserID,
+		&mfa.TOTPSecret,
+		&mfa.ConfirmedAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMFANotFound
+		}
+		return nil, fmt.Errorf("failed to get user mfa: %w", err)
+	}
+
+	return mfa, nil
+}
@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetWebAuthnCredential retrieves a credential by its (base64url) credential ID
+func GetWebAuthnCredential(db *sql.DB, id string) (*models.WebAuthnCredential, error) {
+	credential := &models.WebAuthnCredential{}
+	var signCount int64
+
+	query := `
+	SELECT id, user_id, name, public_key, sign_count, transports, last_used_at, created_at
+	FROM webauthn_credentials
+	WHERE id = $1
+	`
+
+	err := db.QueryRow(query, id).Scan(
+		&credential.ID,
+		&credential.UserID,
+		&credential.Name,
+		&credential.PublicKey,
+		&signCount,
+		pq.Array(&credential.Transports),
+		&credential.LastUsedAt,
+		&credential.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCredentialNotFound
+		}
+		return nil, fmt.Errorf("failed to get webauthn credential: %w", err)
+	}
+
+	credential.SignCount = uint32(signCount)
+
+	return credential, nil
+}
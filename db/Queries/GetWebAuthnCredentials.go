@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+
+	"github.com/lib/pq"
+)
+
+// GetWebAuthnCredentials retrieves the credentials registered by a user
+func GetWebAuthnCredentials(db *sql.DB, userID string) ([]*models.WebAuthnCredential, error) {
+	query := `
+	SELECT id, user_id, name, public_key, sign_count, transports, last_used_at, created_at
+	FROM webauthn_credentials
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webauthn credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []*models.WebAuthnCredential
+	for rows.Next() {
+		credential := &models.WebAuthnCredential{}
+		var signCount int64
+		err := rows.Scan(
+			&credential.ID,
+			&credential.UserID,
+			&credential.Name,
+			&credential.PublicKey,
+			&signCount,
+			pq.Array(&credential.Transports),
+			&credential.LastUsedAt,
+			&credential.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webauthn credential: %w", err)
+		}
+		credential.SignCount = uint32(signCount)
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webauthn credentials: %w", err)
+	}
+
+	return credentials, nil
+}
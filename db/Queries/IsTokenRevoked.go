@@ -0,0 +1,26 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// IsTokenRevoked reports whether a token ID is on the denylist and not yet expired
+func IsTokenRevoked(db *sql.DB, jti string) (bool, error) {
+	var revoked bool
+
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM revoked_tokens
+		WHERE jti = $1 AND expires_at > $2
+	)
+	`
+
+	err := db.QueryRow(query, jti, time.Now()).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check revoked token: %w", err)
+	}
+
+	return revoked, nil
+}
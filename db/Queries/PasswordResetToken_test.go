@@ -0,0 +1,47 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/utils/secure"
+	"testing"
+	"time"
+)
+
+func TestPasswordResetToken(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	create := func(expiresAt time.Time) string {
+		t.Helper()
+		hash := secure.HashToken(secure.RandomToken(32))
+		if err := queries.CreatePasswordResetToken(db, hash, user.ID, expiresAt); err != nil {
+			t.Fatalf("CreatePasswordResetToken: %v", err)
+		}
+		return hash
+	}
+	first := create(time.Now().Add(time.Hour))
+	second := create(time.Now().Add(time.Hour))
+	expired := create(time.Now().Add(-time.Minute))
+
+	// Looking a token up doesn't use it
+	for i := 0; i < 2; i++ {
+		if userID, err := queries.GetPasswordResetToken(db, first); err != nil || userID != user.ID {
+			t.Fatalf("GetPasswordResetToken: got %q, %v", userID, err)
+		}
+	}
+
+	if userID, err := queries.ConsumePasswordResetToken(db, first); err != nil || userID != user.ID {
+		t.Fatalf("ConsumePasswordResetToken: got %q, %v", userID, err)
+	}
+
+	// The used token, the user's other outstanding token and an expired one are all rejected
+	for name, hash := range map[string]string{"used": first, "other": second, "expired": expired, "unknown": secure.HashToken("unknown")} {
+		if _, err := queries.GetPasswordResetToken(db, hash); err != queries.ErrResetTokenNotFound {
+			t.Errorf("get %s token: got %v, want ErrResetTokenNotFound", name, err)
+		}
+		if _, err := queries.ConsumePasswordResetToken(db, hash); err != queries.ErrResetTokenNotFound {
+			t.Errorf("consume %s token: got %v, want ErrResetTokenNotFound", name, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PollDeviceCode records a token request for a device code and returns the code as it was
+// before this poll, so LastPolledAt can be used to enforce the polling interval
+func PollDeviceCode(db *sql.DB, deviceCodeHash string) (*models.DeviceCode, error) {
+	code := &models.DeviceCode{}
+
+	query := `
+	UPDATE oauth_device_codes AS d
+	SET last_polled_at = $1
+	FROM (
+		SELECT device_code_hash, last_polled_at
+		FROM oauth_device_codes
+		WHERE device_code_hash = $2
+		FOR UPDATE
+	) AS previous
+	WHERE d.device_code_hash = previous.device_code_hash
+	RETURNING d.client_id, d.user_code, COALESCE(d.user_id::text, ''), d.scope, d.status,
+		d.amr, COALESCE(d.auth_time, d.created_at), d.poll_interval, d.expires_at, previous.last_polled_at
+	`
+
+	err := db.QueryRow(query, time.Now(), deviceCodeHash).Scan(
+		&code.ClientID,
+		&code.UserCode,
+		&code.UserID,
+		&code.Scope,
+		&code.Status,
+		pq.Array(&code.AMR),
+		&code.AuthTime,
+		&code.Interval,
+		&code.ExpiresAt,
+		&code.LastPolledAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDeviceCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to poll device code: %w", err)
+	}
+
+	return code, nil
+}
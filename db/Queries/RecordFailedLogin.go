@@ -0,0 +1,41 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RecordFailedLogin counts a failed login and locks the account until lockUntil once the
+// count reaches threshold (0 never locks). The count restarts after an expired lockout.
+// Returns the new count.
+func RecordFailedLogin(db *sql.DB, userID string, threshold int, lockUntil time.Time) (int, error) {
+	var attempts int
+
+	query := `
+	UPDATE users u
+	SET failed_login_attempts = c.attempts,
+		last_failed_login_at = $2,
+		locked_until = CASE WHEN $3 > 0 AND c.attempts >= $3 THEN $4::timestamp ELSE NULL END
+	FROM (
+		SELECT id,
+			CASE WHEN locked_until IS NOT NULL AND locked_until <= $2 THEN 1
+			ELSE failed_login_attempts + 1 END AS attempts
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	) c
+	WHERE u.id = c.id
+	RETURNING u.failed_login_attempts
+	`
+
+	err := db.QueryRow(query, userID, time.Now(), threshold, lockUntil).Scan(&attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,68 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"testing"
+	"time"
+)
+
+func TestRecordFailedLogin(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	record := func(threshold int, lockUntil time.Time) int {
+		t.Helper()
+		attempts, err := queries.RecordFailedLogin(db, user.ID, threshold, lockUntil)
+		if err != nil {
+			t.Fatalf("RecordFailedLogin: %v", err)
+		}
+		return attempts
+	}
+	lockedUntil := func() *time.Time {
+		t.Helper()
+		stored, err := queries.GetUserByID(db, user.ID)
+		if err != nil {
+			t.Fatalf("GetUserByID: %v", err)
+		}
+		return stored.LockedUntil
+	}
+
+	for want := 1; want <= 2; want++ {
+		if got := record(3, time.Now().Add(time.Hour)); got != want {
+			t.Fatalf("attempts = %d, want %d", got, want)
+		}
+		if lockedUntil() != nil {
+			t.Fatalf("locked after %d of 3 failures", want)
+		}
+	}
+	if got := record(3, time.Now().Add(time.Hour)); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+	if until := lockedUntil(); until == nil || time.Until(*until) < 59*time.Minute {
+		t.Fatalf("locked until %v, want an hour from now", until)
+	}
+
+	// Once the lockout has expired the count starts over
+	if err := queries.ResetFailedLogins(db, user.ID); err != nil {
+		t.Fatalf("ResetFailedLogins: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		record(3, time.Now().Add(-time.Minute))
+	}
+	if got := record(3, time.Now().Add(time.Hour)); got != 1 {
+		t.Errorf("after an expired lockout: attempts = %d, want 1", got)
+	}
+
+	// A threshold of 0 counts without locking
+	for i := 0; i < 20; i++ {
+		record(0, time.Now().Add(time.Hour))
+	}
+	if until := lockedUntil(); until != nil {
+		t.Errorf("locked until %v with lockout disabled", until)
+	}
+
+	if err := queries.ResetFailedLogins(db, "00000000-0000-0000-0000-000000000000"); err != queries.ErrUserNotFound {
+		t.Errorf("unknown user: got %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ReplaceRecoveryCodes swaps all of the user's recovery codes, used or not, for codeHashes.
+// Returns ErrMFANotFound if the user doesn't have MFA enabled.
+func ReplaceRecoveryCodes(db *sql.DB, userID string, codeHashes []string) error {
+	query := `
+	WITH enabled AS (
+		SELECT user_id
+		FROM user_mfa
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL
+	), cleared AS (
+		DELETE FROM mfa_recovery_codes
+		WHERE user_id IN (SELECT user_id FROM enabled)
+	)
+	INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+	SELECT enabled.user_id, code_hash, $2
+	FROM enabled, unnest($3::text[]) AS code_hash
+	`
+
+	result, err := db.Exec(query, userID, time.Now(), pq.Array(codeHashes))
+	if err != nil {
+		return fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrMFANotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ResetFailedLogins clears a user's failed login count and any lockout
+func ResetFailedLogins(db *sql.DB, userID string) error {
+	query := `
+	UPDATE users
+	SET failed_login_attempts = 0, last_failed_login_at = NULL, locked_until = NULL
+	WHERE id = $1
+	`
+
+	result, err := db.Exec(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
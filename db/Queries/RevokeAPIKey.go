@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RevokeAPIKey revokes an active API key. A non-empty userID restricts it to keys that user owns.
+func RevokeAPIKey(db *sql.DB, keyID, userID string) error {
+	query := `
+	UPDATE api_keys
+	SET revoked_at = $1
+	WHERE id = $2 AND ($3 = '' OR user_id::text = $3) AND revoked_at IS NULL
+	`
+
+	result, err := db.Exec(query, time.Now(), keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
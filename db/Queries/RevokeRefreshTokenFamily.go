@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RevokeRefreshTokenFamily revokes every refresh token descending from the same login
+func RevokeRefreshTokenFamily(db *sql.DB, familyID string) error {
+	query := `
+	UPDATE refresh_tokens
+	SET revoked_at = $1
+	WHERE family_id = $2 AND revoked_at IS NULL
+	`
+
+	_, err := db.Exec(query, time.Now(), familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RevokeToken adds a token ID to the denylist until the token's own expiry
+func RevokeToken(db *sql.DB, jti string, expiresAt time.Time) error {
+	query := `
+	INSERT INTO revoked_tokens (jti, expires_at, revoked_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (jti) DO NOTHING
+	`
+
+	_, err := db.Exec(query, jti, expiresAt, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
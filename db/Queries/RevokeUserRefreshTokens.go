@@ -0,0 +1,23 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RevokeUserRefreshTokens revokes every active refresh token belonging to a user
+func RevokeUserRefreshTokens(db *sql.DB, userID string) error {
+	query := `
+	UPDATE refresh_tokens
+	SET revoked_at = $1
+	WHERE user_id = $2 AND revoked_at IS NULL
+	`
+
+	_, err := db.Exec(query, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+
+	return nil
+}
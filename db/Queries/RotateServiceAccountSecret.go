@@ -0,0 +1,42 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"go-auth/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RotateServiceAccountSecret replaces an active service account's secret hash.
+// The token version is bumped so tokens obtained with the old secret stop working.
+func RotateServiceAccountSecret(db *sql.DB, id, secretHash string) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{}
+
+	query := `
+	UPDATE service_accounts
+	SET secret_hash = $1, token_version = token_version + 1, updated_at = $2
+	WHERE id = $3 AND disabled_at IS NULL
+	RETURNING id, client_id, name, role, scopes, created_at, updated_at
+	`
+
+	err := db.QueryRow(query, secretHash, time.Now(), id).Scan(
+		&account.ID,
+		&account.ClientID,
+		&account.Name,
+		&account.Role,
+		pq.Array(&account.Scopes),
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to rotate service account secret: %w", err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SetMustChangePassword flags (or clears the flag on) a user who has to pick a new password
+func SetMustChangePassword(db *sql.DB, userID string, mustChange bool) error {
+	query := `
+	UPDATE users
+	SET must_change_password = $1, updated_at = $2
+	WHERE id = $3
+	`
+
+	result, err := db.Exec(query, mustChange, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update must_change_password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
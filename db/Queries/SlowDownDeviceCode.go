@@ -0,0 +1,22 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SlowDownDeviceCode increases a device code's polling interval by 5 seconds (RFC 8628 section 3.5)
+func SlowDownDeviceCode(db *sql.DB, deviceCodeHash string) error {
+	query := `
+	UPDATE oauth_device_codes
+	SET poll_interval = poll_interval + 5
+	WHERE device_code_hash = $1
+	`
+
+	_, err := db.Exec(query, deviceCodeHash)
+	if err != nil {
+		return fmt.Errorf("failed to slow down device code: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TakeRateLimitToken refills the token bucket for key (capacity tokens, refilled at
+// refillRate per second) and removes one token if there is one left. A missing bucket
+// starts out full. Returns whether a token was taken and how many are left.
+func TakeRateLimitToken(db *sql.DB, key string, capacity, refillRate float64) (bool, float64, error) {
+	var tokens float64
+	var allowed bool
+
+	query := `
+	WITH bucket AS (
+		SELECT COALESCE((
+			SELECT LEAST($2::float8, tokens + EXTRACT(EPOCH FROM ($4::timestamp - updated_at))::float8 * $3::float8)
+			FROM rate_limit_buckets
+			WHERE bucket_key = $1
+			FOR UPDATE
+		), $2::float8) AS tokens
+	), taken AS (
+		INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at, full_at)
+		SELECT $1, tokens - 1, $4, $4::timestamp + make_interval(secs => ($2::float8 - tokens + 1) / $3::float8)
+		FROM bucket
+		WHERE tokens >= 1
+		ON CONFLICT (bucket_key) DO UPDATE
+		SET tokens = EXCLUDED.tokens, updated_at = EXCLUDED.updated_at, full_at = EXCLUDED.full_at
+		RETURNING tokens
+	)
+	SELECT bucket.tokens, EXISTS (SELECT 1 FROM taken)
+	FROM bucket
+	`
+
+	err := db.QueryRow(query, key, capacity, refillRate, time.Now()).Scan(&tokens, &allowed)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	if allowed {
+		tokens--
+	}
+
+	return allowed, tokens, nil
+}
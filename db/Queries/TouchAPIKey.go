@@ -0,0 +1,26 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TouchAPIKey records that an API key was just used.
+// The write is skipped if the key was already marked as used within the last minute.
+func TouchAPIKey(db *sql.DB, keyID string) error {
+	now := time.Now()
+
+	query := `
+	UPDATE api_keys
+	SET last_used_at = $1
+	WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+
+	_, err := db.Exec(query, now, keyID, now.Add(-time.Minute))
+	if err != nil {
+		return fmt.Errorf("failed to update api key last used: %w", err)
+	}
+
+	return nil
+}
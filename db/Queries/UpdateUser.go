@@ -7,15 +7,18 @@ import (
 	"time"
 )
 
-// UpdateUser updates a user's username and/or email
+// UpdateUser updates a user's username and/or email. A changed email has to be verified again,
+// and the token version is bumped so tokens carrying the old email and its verified flag stop working.
 func UpdateUser(db *sql.DB, userID string, username, email string) (*models.User, error) {
 	user := &models.User{}
 
 	query := `
 	UPDATE users
-	SET username = $1, email = $2, updated_at = $3
+	SET username = $1, email = $2, email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
+		token_version = CASE WHEN email = $2 THEN token_version ELSE token_version + 1 END, updated_at = $3
 	WHERE id = $4 AND deleted_at IS NULL
-	RETURNING id, username, email, password, role, deleted_at, created_at, updated_at
+	RETURNING id, username, email, password, role, token_version, must_change_password,
+		failed_login_attempts, last_failed_login_at, locked_until, email_verified_at, deleted_at, created_at, updated_at
 	`
 
 	err := db.QueryRow(query, username, email, time.Now(), userID).Scan(
@@ -24,6 +27,12 @@ func UpdateUser(db *sql.DB, userID string, username, email string) (*models.User
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.TokenVersion,
+		&user.MustChangePassword,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.EmailVerifiedAt,
 		&user.DeletedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -7,15 +7,16 @@ import (
 	"time"
 )
 
-// UpdateUserRole updates a user's role
+// UpdateUserRole updates a user's role and invalidates tokens carrying the old role
 func UpdateUserRole(db *sql.DB, userID string, newRole string) (*models.User, error) {
 	user := &models.User{}
 
 	query := `
 	UPDATE users
-	SET role = $1, updated_at = $2
+	SET role = $1, token_version = token_version + 1, updated_at = $2
 	WHERE id = $3
-	RETURNING id, username, email, password, role, created_at, updated_at
+	RETURNING id, username, email, password, role, must_change_password,
+		failed_login_attempts, last_failed_login_at, locked_until, email_verified_at, created_at, updated_at
 	`
 
 	err := db.QueryRow(query, newRole, time.Now(), userID).Scan(
@@ -24,6 +25,11 @@ func UpdateUserRole(db *sql.DB, userID string, newRole string) (*models.User, er
 		&user.Email,
 		&user.Password,
 		&user.Role,
+		&user.MustChangePassword,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
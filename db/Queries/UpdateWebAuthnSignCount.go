@@ -0,0 +1,34 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UpdateWebAuthnSignCount records a successful use of a credential. The signature counter must
+// have increased, unless the authenticator doesn't keep one (both counters zero); otherwise the
+// credential may have been cloned and ErrCredentialCloned is returned.
+func UpdateWebAuthnSignCount(db *sql.DB, id string, signCount uint32) error {
+	query := `
+	UPDATE webauthn_credentials
+	SET sign_count = $1, last_used_at = $2
+	WHERE id = $3 AND (sign_count < $1 OR (sign_count = 0 AND $1 = 0))
+	`
+
+	result, err := db.Exec(query, int64(signCount), time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update webauthn sign count: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrCredentialCloned
+	}
+
+	return nil
+}
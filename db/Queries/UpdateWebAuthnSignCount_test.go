@@ -0,0 +1,68 @@
+package queries_test
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"testing"
+)
+
+func TestUpdateWebAuthnSignCount(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	newCredential := func(signCount uint32) string {
+		credential := &models.WebAuthnCredential{
+			ID:        secure.RandomToken(16),
+			UserID:    user.ID,
+			PublicKey: []byte{0xa0},
+			SignCount: signCount,
+		}
+		if err := queries.CreateWebAuthnCredential(db, credential); err != nil {
+			t.Fatalf("CreateWebAuthnCredential: %v", err)
+		}
+		return credential.ID
+	}
+
+	steps := []struct {
+		name      string
+		signCount uint32
+		want      error
+	}{
+		{"increase", 5, nil},
+		{"replayed counter", 5, queries.ErrCredentialCloned},
+		{"counter went backwards", 3, queries.ErrCredentialCloned},
+		{"increase again", 6, nil},
+		{"zero after counting", 0, queries.ErrCredentialCloned},
+		{"largest counter", 1<<32 - 1, nil},
+	}
+	counting := newCredential(1)
+	for _, step := range steps {
+		if err := queries.UpdateWebAuthnSignCount(db, counting, step.signCount); err != step.want {
+			t.Fatalf("%s (%d): got %v, want %v", step.name, step.signCount, err, step.want)
+		}
+	}
+
+	// Authenticators without a counter always report zero
+	noCounter := newCredential(0)
+	for i := range 2 {
+		if err := queries.UpdateWebAuthnSignCount(db, noCounter, 0); err != nil {
+			t.Fatalf("zero counter, use %d: %v", i+1, err)
+		}
+	}
+	if err := queries.UpdateWebAuthnSignCount(db, noCounter, 1); err != nil {
+		t.Fatalf("counter started: %v", err)
+	}
+	if err := queries.UpdateWebAuthnSignCount(db, noCounter, 0); err != queries.ErrCredentialCloned {
+		t.Fatalf("zero after counter started: got %v, want ErrCredentialCloned", err)
+	}
+
+	credential, err := queries.GetWebAuthnCredential(db, counting)
+	if err != nil {
+		t.Fatalf("GetWebAuthnCredential: %v", err)
+	}
+	if credential.SignCount != 1<<32-1 || credential.LastUsedAt == nil {
+		t.Fatalf("got sign count %d, last used %v", credential.SignCount, credential.LastUsedAt)
+	}
+}
@@ -0,0 +1,33 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UseRecoveryCode marks one of the user's recovery codes as used.
+// Returns ErrRecoveryCodeNotFound if the code is unknown or already used.
+func UseRecoveryCode(db *sql.DB, userID, codeHash string) error {
+	query := `
+	UPDATE mfa_recovery_codes
+	SET used_at = $3
+	WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := db.Exec(query, userID, codeHash, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
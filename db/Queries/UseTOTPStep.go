@@ -0,0 +1,33 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// UseTOTPStep records that the code for step was used. Codes are only accepted for steps
+// after the last used one, so an intercepted code can't be replayed.
+// Returns ErrTOTPCodeReused if step (or a later one) was already used.
+func UseTOTPStep(db *sql.DB, userID string, step int64) error {
+	query := `
+	UPDATE user_mfa
+	SET last_used_step = $2
+	WHERE user_id = $1 AND confirmed_at IS NOT NULL AND last_used_step < $2
+	`
+
+	result, err := db.Exec(query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to use totp step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrTOTPCodeReused
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// VerifyEmail marks the user's email as verified, keeping the original time if it already was.
+// The email must still be the user's current one; otherwise ErrUserNotFound is returned.
+func VerifyEmail(db *sql.DB, userID, email string) error {
+	query := `
+	UPDATE users
+	SET email_verified_at = COALESCE(email_verified_at, $1)
+	WHERE id = $2 AND email = $3 AND deleted_at IS NULL
+	`
+
+	result, err := db.Exec(query, time.Now(), userID, email)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
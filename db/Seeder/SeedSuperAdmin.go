@@ -28,7 +28,7 @@ func SeedSuperAdmin(db *sql.DB, email, password, superAdminRole string) error {
 	}
 
 	// Create super admin user
-	_, err = queries.CreateUser(db, "superadmin", email, hashedPassword, superAdminRole)
+	user, err := queries.CreateUser(db, "superadmin", email, hashedPassword, superAdminRole)
 	if err != nil {
 		if err == queries.ErrUserExists {
 			fmt.Printf("Super admin user already exists\n")
@@ -37,6 +37,11 @@ func SeedSuperAdmin(db *sql.DB, email, password, superAdminRole string) error {
 		return fmt.Errorf("failed to create super admin user: %w", err)
 	}
 
+	// The address comes from the deployment's own configuration
+	if err := queries.VerifyEmail(db, user.ID, email); err != nil {
+		return fmt.Errorf("failed to verify super admin email: %w", err)
+	}
+
 	fmt.Printf("Super admin user created successfully: %s\n", email)
 	return nil
 }
\ No newline at end of file
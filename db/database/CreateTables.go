@@ -40,5 +40,360 @@ func CreateTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create users table: %w", err)
 	}
 
+	// Add token_version to users tables created before it existed
+	_, err = db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0`)
+	if err != nil {
+		return fmt.Errorf("failed to add token_version column: %w", err)
+	}
+
+	// Users flagged here (e.g. found in a breach corpus at login) must pick a new password
+	_, err = db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE`)
+	if err != nil {
+		return fmt.Errorf("failed to add must_change_password column: %w", err)
+	}
+
+	// Start of the password's age; existing users start counting when the column is added
+	_, err = db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`)
+	if err != nil {
+		return fmt.Errorf("failed to add password_changed_at column: %w", err)
+	}
+
+	// Failed login tracking for progressive delays and account lockout
+	_, err = db.Exec(`
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS last_failed_login_at TIMESTAMP;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add login lockout columns: %w", err)
+	}
+
+	// When the user proved they own their email. The default marks users that existed before the
+	// column as verified and is then dropped, so new users start unverified
+	_, err = db.Exec(`
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+	ALTER TABLE users ALTER COLUMN email_verified_at DROP DEFAULT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add email_verified_at column: %w", err)
+	}
+
+	// Create refresh tokens table (hashed tokens grouped into rotation families)
+	createRefreshTokensTable := `
+	CREATE TABLE IF NOT EXISTS refresh_tokens (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		family_id UUID NOT NULL,
+		token_hash CHAR(64) UNIQUE NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		used_at TIMESTAMP,
+		revoked_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+	CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+	`
+
+	_, err = db.Exec(createRefreshTokensTable)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh_tokens table: %w", err)
+	}
+
+	// Create revoked tokens table (jti denylist, rows are useless once expires_at passes)
+	createRevokedTokensTable := `
+	CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti VARCHAR(64) PRIMARY KEY,
+		expires_at TIMESTAMP NOT NULL,
+		revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+	`
+
+	_, err = db.Exec(createRevokedTokensTable)
+	if err != nil {
+		return fmt.Errorf("failed to create revoked_tokens table: %w", err)
+	}
+
+	// Create OAuth clients table (secret hash is NULL for public clients)
+	createOAuthClientsTable := `
+	CREATE TABLE IF NOT EXISTS oauth_clients (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		client_id VARCHAR(64) UNIQUE NOT NULL,
+		client_secret_hash CHAR(64),
+		name VARCHAR(255) NOT NULL,
+		redirect_uris TEXT[] NOT NULL,
+		allowed_scopes TEXT[] NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err = db.Exec(createOAuthClientsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth_clients table: %w", err)
+	}
+
+	// Create OAuth authorization codes table (hashed, single-use, short-lived)
+	createAuthorizationCodesTable := `
+	CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+		code_hash CHAR(64) PRIMARY KEY,
+		client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		redirect_uri TEXT NOT NULL,
+		scope TEXT NOT NULL DEFAULT '',
+		code_challenge VARCHAR(128) NOT NULL,
+		code_challenge_method VARCHAR(10) NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		used_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err = db.Exec(createAuthorizationCodesTable)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth_authorization_codes table: %w", err)
+	}
+
+	// Add OpenID Connect columns to authorization code tables created before them
+	_, err = db.Exec(`
+	ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS nonce TEXT NOT NULL DEFAULT '';
+	ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS auth_time TIMESTAMP;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add oidc columns to oauth_authorization_codes: %w", err)
+	}
+
+	// Record whether the authorization request named redirect_uri (RFC 6749 section 4.1.3)
+	_, err = db.Exec(`
+	ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS redirect_uri_supplied BOOLEAN NOT NULL DEFAULT FALSE;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add redirect_uri_supplied column to oauth_authorization_codes: %w", err)
+	}
+
+	// Create audit log table (actor and subject are kept as plain IDs so history survives deletions)
+	createAuditLogTable := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		action VARCHAR(100) NOT NULL,
+		actor_id VARCHAR(64) NOT NULL DEFAULT '',
+		subject_id VARCHAR(64) NOT NULL DEFAULT '',
+		details JSONB NOT NULL DEFAULT '{}',
+		ip_address VARCHAR(45) NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log (actor_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_subject_id ON audit_log (subject_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+	`
+
+	_, err = db.Exec(createAuditLogTable)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	// Create service accounts table (machine principals for the client_credentials grant)
+	createServiceAccountsTable := `
+	CREATE TABLE IF NOT EXISTS service_accounts (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		client_id VARCHAR(64) UNIQUE NOT NULL,
+		secret_hash CHAR(64) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		role VARCHAR(100) NOT NULL DEFAULT '',
+		scopes TEXT[] NOT NULL DEFAULT '{}',
+		token_version INTEGER NOT NULL DEFAULT 0,
+		disabled_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err = db.Exec(createServiceAccountsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create service_accounts table: %w", err)
+	}
+
+	// Create API keys table (hashed long-lived credentials owned by a user)
+	createAPIKeysTable := `
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR(255) NOT NULL,
+		prefix VARCHAR(16) NOT NULL,
+		key_hash CHAR(64) UNIQUE NOT NULL,
+		role VARCHAR(100) NOT NULL DEFAULT '',
+		scopes TEXT[] NOT NULL DEFAULT '{}',
+		expires_at TIMESTAMP,
+		last_used_at TIMESTAMP,
+		revoked_at TIMESTAMP,
+		created_by UUID NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+	`
+
+	_, err = db.Exec(createAPIKeysTable)
+	if err != nil {
+		return fmt.Errorf("failed to create api_keys table: %w", err)
+	}
+
+	// Create OAuth device codes table (RFC 8628 device authorization grant)
+	createDeviceCodesTable := `
+	CREATE TABLE IF NOT EXISTS oauth_device_codes (
+		device_code_hash CHAR(64) PRIMARY KEY,
+		user_code VARCHAR(16) UNIQUE NOT NULL,
+		client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+		user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+		scope TEXT NOT NULL DEFAULT '',
+		status VARCHAR(16) NOT NULL DEFAULT 'pending',
+		poll_interval INTEGER NOT NULL,
+		last_polled_at TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err = db.Exec(createDeviceCodesTable)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth_device_codes table: %w", err)
+	}
+
+	// Create password history table (previous hashes, to prevent password reuse)
+	createPasswordHistoryTable := `
+	CREATE TABLE IF NOT EXISTS password_history (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		password_hash VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history (user_id, created_at DESC);
+	`
+
+	_, err = db.Exec(createPasswordHistoryTable)
+	if err != nil {
+		return fmt.Errorf("failed to create password_history table: %w", err)
+	}
+
+	// Create password reset tokens table (hashed, single-use)
+	createPasswordResetTokensTable := `
+	CREATE TABLE IF NOT EXISTS password_reset_tokens (
+		token_hash CHAR(64) PRIMARY KEY,
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		expires_at TIMESTAMP NOT NULL,
+		used_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+	`
+
+	_, err = db.Exec(createPasswordResetTokensTable)
+	if err != nil {
+		return fmt.Errorf("failed to create password_reset_tokens table: %w", err)
+	}
+
+	// Create rate limit buckets table (shared token buckets for multi-instance deployments)
+	createRateLimitBucketsTable := `
+	CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+		bucket_key VARCHAR(255) PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		full_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_full_at ON rate_limit_buckets (full_at);
+	`
+
+	_, err = db.Exec(createRateLimitBucketsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create rate_limit_buckets table: %w", err)
+	}
+
+	// Create user MFA table (one TOTP enrollment per user, confirmed once a code was entered)
+	createUserMFATable := `
+	CREATE TABLE IF NOT EXISTS user_mfa (
+		user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		totp_secret VARCHAR(64) NOT NULL,
+		confirmed_at TIMESTAMP,
+		last_used_step BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err = db.Exec(createUserMFATable)
+	if err != nil {
+		return fmt.Errorf("failed to create user_mfa table: %w", err)
+	}
+
+	// Create MFA recovery codes table (hashed, single-use)
+	createRecoveryCodesTable := `
+	CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		code_hash CHAR(64) NOT NULL,
+		used_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, code_hash)
+	);
+	`
+
+	_, err = db.Exec(createRecoveryCodesTable)
+	if err != nil {
+		return fmt.Errorf("failed to create mfa_recovery_codes table: %w", err)
+	}
+
+	// Record how the user authenticated (amr) on OAuth codes so tokens can carry it
+	_, err = db.Exec(`
+	ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS amr TEXT[] NOT NULL DEFAULT '{}';
+	ALTER TABLE oauth_device_codes ADD COLUMN IF NOT EXISTS amr TEXT[] NOT NULL DEFAULT '{}';
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add amr columns to oauth code tables: %w", err)
+	}
+
+	// Add the approving user's authentication time to device code tables created before it
+	_, err = db.Exec(`
+	ALTER TABLE oauth_device_codes ADD COLUMN IF NOT EXISTS auth_time TIMESTAMP;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to add auth_time column to oauth_device_codes: %w", err)
+	}
+
+	// Create WebAuthn credentials table (passkeys and security keys, one row per credential)
+	createWebAuthnCredentialsTable := `
+	CREATE TABLE IF NOT EXISTS webauthn_credentials (
+		id VARCHAR(1400) PRIMARY KEY,
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL DEFAULT '',
+		public_key BYTEA NOT NULL,
+		sign_count BIGINT NOT NULL DEFAULT 0,
+		transports TEXT[] NOT NULL DEFAULT '{}',
+		last_used_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials (user_id);
+	`
+
+	_, err = db.Exec(createWebAuthnCredentialsTable)
+	if err != nil {
+		return fmt.Errorf("failed to create webauthn_credentials table: %w", err)
+	}
+
+	// Create WebAuthn challenges table (hashed, single-use ceremony challenges)
+	createWebAuthnChallengesTable := `
+	CREATE TABLE IF NOT EXISTS webauthn_challenges (
+		challenge_hash CHAR(64) PRIMARY KEY,
+		ceremony VARCHAR(16) NOT NULL,
+		user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+		expires_at TIMESTAMP NOT NULL,
+		used_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_webauthn_challenges_expires_at ON webauthn_challenges (expires_at);
+	`
+
+	_, err = db.Exec(createWebAuthnChallengesTable)
+	if err != nil {
+		return fmt.Errorf("failed to create webauthn_challenges table: %w", err)
+	}
+
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package dbtest
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"testing"
+)
+
+// CreateOAuthClient registers a public client; it is removed again when the test ends
+func CreateOAuthClient(t *testing.T, db *sql.DB, redirectURI string) *models.OAuthClient {
+	t.Helper()
+
+	client, err := queries.CreateOAuthClient(db, "test-"+secure.RandomToken(8), "", "Test", []string{redirectURI}, []string{"openid", "read"})
+	if err != nil {
+		t.Fatalf("CreateOAuthClient: %v", err)
+	}
+	t.Cleanup(func() { queries.DeleteOAuthClient(db, client.ClientID) })
+	return client
+}
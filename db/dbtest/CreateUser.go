@@ -0,0 +1,22 @@
+package dbtest
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"testing"
+)
+
+// CreateUser inserts a user with a unique email; it is removed again when the test ends
+func CreateUser(t *testing.T, db *sql.DB) *models.User {
+	t.Helper()
+
+	name := "test_" + secure.RandomToken(8)
+	user, err := queries.CreateUser(db, name, name+"@example.com", "not-a-real-hash", "User")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+	return user
+}
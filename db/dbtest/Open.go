@@ -0,0 +1,31 @@
+// Package dbtest connects tests to a real Postgres database
+package dbtest
+
+import (
+	"database/sql"
+	"go-auth/db/database"
+	"os"
+	"testing"
+)
+
+// Open connects to the Postgres database in TEST_DB_SOURCE and creates the schema.
+// Tests that need a database are skipped when it isn't set.
+func Open(t *testing.T) *sql.DB {
+	t.Helper()
+
+	source := os.Getenv("TEST_DB_SOURCE")
+	if source == "" {
+		t.Skip("TEST_DB_SOURCE is not set")
+	}
+
+	db, err := database.InitDB("postgres", source)
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.CreateTables(db); err != nil {
+		t.Fatalf("CreateTables: %v", err)
+	}
+	return db
+}
@@ -8,3 +8,5 @@ require (
 	github.com/lib/pq v1.10.9
 	golang.org/x/crypto v0.43.0
 )
+
+require golang.org/x/sys v0.37.0 // indirect
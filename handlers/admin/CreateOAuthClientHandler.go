@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CreateOAuthClientHandler registers a new OAuth client (Super Admin only).
+// Confidential clients get a secret that is only returned in this response.
+func CreateOAuthClientHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Parse request body
+		var req models.CreateOAuthClientRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Name == "" || len(req.RedirectURIs) == 0 {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "name and redirect_uris are required"})
+			return
+		}
+
+		for _, uri := range req.RedirectURIs {
+			if !isValidRedirectURI(uri) {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid redirect uri: " + uri})
+				return
+			}
+		}
+
+		allowedScopes := []string{}
+		for _, scope := range req.AllowedScopes {
+			if !handlers.IsValidScope(scope) {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid scope: " + scope})
+				return
+			}
+			allowedScopes = append(allowedScopes, scope)
+		}
+
+		// Generate credentials; only the secret hash is stored
+		clientID := secure.RandomToken(16)
+		var clientSecret, secretHash string
+		if !req.Public {
+			clientSecret = secure.RandomToken(32)
+			secretHash = secure.HashToken(clientSecret)
+		}
+
+		client, err := queries.CreateOAuthClient(database, clientID, secretHash, req.Name, req.RedirectURIs, allowedScopes)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create oauth client"})
+			return
+		}
+
+		response := models.CreateOAuthClientResponse{
+			Client:       client,
+			ClientSecret: clientSecret,
+		}
+
+		handlers.RespondJSON(w, http.StatusCreated, response)
+	}
+}
+
+// isValidRedirectURI accepts absolute URIs without a fragment that use https,
+// http on a loopback address, or a private-use scheme for native apps (RFC 8252)
+func isValidRedirectURI(raw string) bool {
+	uri, err := url.Parse(raw)
+	if err != nil || uri.Fragment != "" || strings.Contains(raw, "#") || uri.Scheme == "" {
+		return false
+	}
+
+	switch uri.Scheme {
+	case "https":
+		return uri.Host != ""
+	case "http":
+		host := uri.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	case "javascript", "data", "file", "vbscript":
+		return false
+	default:
+		// Private-use schemes must be reverse domain names, e.g. com.example.app:/callback
+		return strings.Contains(uri.Scheme, ".")
+	}
+}
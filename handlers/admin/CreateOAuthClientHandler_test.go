@@ -0,0 +1,25 @@
+package admin
+
+import "testing"
+
+func TestIsValidRedirectURI(t *testing.T) {
+	tests := map[string]bool{
+		"https://app.example.com/callback":      true,
+		"http://localhost:3000/callback":        true,
+		"http://127.0.0.1:8000/callback":        true,
+		"http://[::1]:8000/callback":            true,
+		"com.example.app:/callback":             true,
+		"http://app.example.com/callback":       false,
+		"https:///callback":                     false,
+		"https://app.example.com/callback#frag": false,
+		"/callback":                             false,
+		"javascript:alert(1)":                   false,
+		"data:text/html,hi":                     false,
+		"myapp:/callback":                       false,
+	}
+	for uri, want := range tests {
+		if got := isValidRedirectURI(uri); got != want {
+			t.Errorf("isValidRedirectURI(%q) = %v, want %v", uri, got, want)
+		}
+	}
+}
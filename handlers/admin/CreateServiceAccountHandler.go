@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/secure"
+	"net/http"
+)
+
+// CreateServiceAccountHandler creates a service account for the client_credentials grant (Super Admin only).
+// The client secret is only returned in this response.
+func CreateServiceAccountHandler(database *sql.DB, availableRoles []string, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Parse request body
+		var req models.CreateServiceAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Name == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+			return
+		}
+		if req.Role == "" && len(req.Scopes) == 0 {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "role or scopes are required"})
+			return
+		}
+
+		// Validate role exists in available roles
+		if req.Role != "" {
+			roleExists := false
+			for _, role := range availableRoles {
+				if role == req.Role {
+					roleExists = true
+					break
+				}
+			}
+			if !roleExists {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid role"})
+				return
+			}
+		}
+
+		scopes := []string{}
+		for _, scope := range req.Scopes {
+			if !handlers.IsValidScope(scope) {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid scope: " + scope})
+				return
+			}
+			scopes = append(scopes, scope)
+		}
+
+		// Generate credentials; only the secret hash is stored
+		clientSecret := secure.RandomToken(32)
+		account, err := queries.CreateServiceAccount(database, "svc_"+secure.RandomToken(16), secure.HashToken(clientSecret), req.Name, req.Role, scopes)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create service account"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditServiceAccountCreated,
+			ActorID:   claims.UserID,
+			SubjectID: account.ID,
+			Details:   map[string]string{"client_id": account.ClientID, "role": account.Role},
+		})
+
+		response := models.ServiceAccountCredentialsResponse{
+			ServiceAccount: account,
+			ClientSecret:   clientSecret,
+		}
+
+		handlers.RespondJSON(w, http.StatusCreated, response)
+	}
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/handlers/user"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+	"strings"
+)
+
+// CreateUserAPIKeyHandler creates an API key on a user's behalf (Super Admin only).
+// The key is only returned in this response.
+func CreateUserAPIKeyHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		if claims.IsAPIKey() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "api keys can only be created from a login session"})
+			return
+		}
+
+		// Extract user ID from URL path
+		// Expected format: /admin/api-keys/create/{uuid}
+		userID := strings.TrimPrefix(r.URL.Path, "/admin/api-keys/create/")
+		if userID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+			return
+		}
+
+		var req models.CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		owner, err := queries.GetUserByID(database, userID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		if err := user.ValidateAPIKeyRequest(&req, owner); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		apiKey, key, err := user.IssueAPIKey(database, owner, &req, claims.UserID)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create api key"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditAPIKeyCreated,
+			ActorID:   claims.UserID,
+			SubjectID: owner.ID,
+			Details:   map[string]string{"api_key_id": apiKey.ID, "prefix": apiKey.Prefix},
+		})
+
+		response := models.CreateAPIKeyResponse{
+			APIKey: apiKey,
+			Key:    key,
+		}
+
+		handlers.RespondJSON(w, http.StatusCreated, response)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"go-auth/handlers"
 	"go-auth/utils/password"
 	"net/http"
+	"time"
 )
 
 // CreateUserRequest is the payload for creating a new user by admin
@@ -15,10 +16,13 @@ type CreateUserRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
+
+	// MustChangePassword makes the user pick their own password on first login (default true)
+	MustChangePassword *bool `json:"must_change_password"`
 }
 
 // CreateUserHandler creates a new user (Super Admin only)
-func CreateUserHandler(database *sql.DB, availableRoles []string) http.HandlerFunc {
+func CreateUserHandler(database *sql.DB, availableRoles []string, passwordRules *handlers.PasswordRules) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -51,6 +55,17 @@ func CreateUserHandler(database *sql.DB, availableRoles []string) http.HandlerFu
 			return
 		}
 
+		// Enforce the password policy and breach screening
+		errs, err := passwordRules.Check("password", req.Password, req.Username, req.Email)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to check password"})
+			return
+		}
+		if len(errs) > 0 {
+			handlers.RespondValidationError(w, errs)
+			return
+		}
+
 		// Hash password
 		hashedPassword, err := password.HashPassword(req.Password)
 		if err != nil {
@@ -69,6 +84,23 @@ func CreateUserHandler(database *sql.DB, availableRoles []string) http.HandlerFu
 			return
 		}
 
+		// The admin chose this password, so by default the user must replace it
+		if req.MustChangePassword == nil || *req.MustChangePassword {
+			if err := queries.SetMustChangePassword(database, user.ID, true); err != nil {
+				handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create user"})
+				return
+			}
+			user.MustChangePassword = true
+		}
+
+		// The admin vouches for the address, so the user doesn't have to verify it
+		if err := queries.VerifyEmail(database, user.ID, user.Email); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create user"})
+			return
+		}
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+
 		handlers.RespondJSON(w, http.StatusCreated, user)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"net/http"
+	"strings"
+)
+
+// DeleteOAuthClientHandler removes an OAuth client (Super Admin only).
+// Refresh tokens issued to the client stop working because it can no longer authenticate.
+func DeleteOAuthClientHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Extract client ID from URL path
+		// Expected format: /admin/oauth/clients/delete/{client_id}
+		clientID := strings.TrimPrefix(r.URL.Path, "/admin/oauth/clients/delete/")
+		if clientID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid client id"})
+			return
+		}
+
+		err := queries.DeleteOAuthClient(database, clientID)
+		if err != nil {
+			if err == queries.ErrOAuthClientNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "oauth client not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete oauth client"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "oauth client deleted successfully"})
+	}
+}
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+	"strings"
+)
+
+// DisableServiceAccountHandler permanently disables a service account (Super Admin only)
+func DisableServiceAccountHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Extract service account ID from URL path
+		// Expected format: /admin/service-accounts/disable/{uuid}
+		accountID := strings.TrimPrefix(r.URL.Path, "/admin/service-accounts/disable/")
+		if accountID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid service account id"})
+			return
+		}
+
+		err = queries.DisableServiceAccount(database, accountID)
+		if err != nil {
+			if err == queries.ErrServiceAccountNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "service account not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to disable service account"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditServiceAccountDisabled,
+			ActorID:   claims.UserID,
+			SubjectID: accountID,
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "service account disabled successfully"})
+	}
+}
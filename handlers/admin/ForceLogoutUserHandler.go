@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"net/http"
+	"strings"
+)
+
+// ForceLogoutUserHandler invalidates every token a user holds (Super Admin only)
+func ForceLogoutUserHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Extract user ID from URL path
+		// Expected format: /admin/users/logout/{uuid}
+		userID := strings.TrimPrefix(r.URL.Path, "/admin/users/logout/")
+		if userID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+			return
+		}
+
+		// Bump the token version so existing access and refresh tokens are rejected
+		if err := queries.BumpTokenVersion(database, userID); err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to log out user"})
+			return
+		}
+
+		// Revoke stored refresh tokens as well
+		if err := queries.RevokeUserRefreshTokens(database, userID); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to log out user"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "user logged out of all sessions"})
+	}
+}
@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"net/http"
+)
+
+// GetAllAPIKeysHandler returns every API key, or one user's with ?user_id= (Super Admin only)
+func GetAllAPIKeysHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		keys, err := queries.GetAPIKeys(database, r.URL.Query().Get("user_id"))
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get api keys"})
+			return
+		}
+
+		response := models.GetAPIKeysResponse{
+			Total:   len(keys),
+			APIKeys: keys,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"net/http"
+)
+
+// GetAllOAuthClientsHandler returns all registered OAuth clients (Super Admin only)
+func GetAllOAuthClientsHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		clients, err := queries.GetAllOAuthClients(database)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get oauth clients"})
+			return
+		}
+
+		response := models.GetAllOAuthClientsResponse{
+			Total:   len(clients),
+			Clients: clients,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
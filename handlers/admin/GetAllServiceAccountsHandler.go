@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"net/http"
+)
+
+// GetAllServiceAccountsHandler returns all service accounts (Super Admin only)
+func GetAllServiceAccountsHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		accounts, err := queries.GetAllServiceAccounts(database)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get service accounts"})
+			return
+		}
+
+		response := models.GetAllServiceAccountsResponse{
+			Total:           len(accounts),
+			ServiceAccounts: accounts,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"net/http"
+	"strconv"
+)
+
+// GetAuditLogHandler returns the most recent audit log entries (Super Admin only).
+// Supports actor_id, subject_id, action and limit query parameters.
+func GetAuditLogHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		query := r.URL.Query()
+		filter := models.AuditLogFilter{
+			ActorID:   query.Get("actor_id"),
+			SubjectID: query.Get("subject_id"),
+			Action:    query.Get("action"),
+			Limit:     100,
+		}
+
+		if limit := query.Get("limit"); limit != "" {
+			parsed, err := strconv.Atoi(limit)
+			if err != nil || parsed < 1 || parsed > 1000 {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+				return
+			}
+			filter.Limit = parsed
+		}
+
+		entries, err := queries.GetAuditLog(database, filter)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get audit log"})
+			return
+		}
+
+		response := models.GetAuditLogResponse{
+			Total:   len(entries),
+			Entries: entries,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
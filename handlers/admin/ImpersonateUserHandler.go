@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/jwt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ImpersonateUserHandler lets a Super Admin act as a user (Super Admin only).
+// The issued access token is short-lived, carries an act claim naming the admin and
+// never comes with a refresh token. Every impersonation is recorded in the audit log.
+func ImpersonateUserHandler(database *sql.DB, keys *jwt.KeySet, auditLog *audit.Logger, superAdminRole string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// An impersonation token cannot be used to start another impersonation
+		if claims.IsImpersonated() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "impersonation tokens cannot impersonate again"})
+			return
+		}
+
+		// Impersonation is for people signed in interactively, not service accounts or API keys
+		if claims.IsService() || claims.IsAPIKey() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "impersonation requires a login session"})
+			return
+		}
+
+		// Extract user ID from URL path
+		// Expected format: /admin/users/impersonate/{uuid}
+		userID := strings.TrimPrefix(r.URL.Path, "/admin/users/impersonate/")
+		if userID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+			return
+		}
+		if userID == claims.UserID {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "cannot impersonate yourself"})
+			return
+		}
+
+		// Body is optional
+		var req models.ImpersonateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Optional audience must be one of the configured audiences
+		var audience []string
+		if req.Audience != "" {
+			if !keys.HasAudience(req.Audience) {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience"})
+				return
+			}
+			audience = []string{req.Audience}
+		}
+
+		target, err := queries.GetUserByID(database, userID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		// Super Admins cannot be impersonated
+		if target.Role == superAdminRole {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "cannot impersonate a super admin"})
+			return
+		}
+
+		impersonationClaims := jwt.NewImpersonationClaims(target, claims)
+		impersonationClaims.Audience = audience
+		accessToken, err := jwt.SignClaims(impersonationClaims, keys)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate token"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditImpersonationStarted,
+			ActorID:   claims.UserID,
+			SubjectID: target.ID,
+			Details: map[string]string{
+				"jti":        impersonationClaims.ID,
+				"expires_at": impersonationClaims.ExpiresAt.Format(time.RFC3339),
+				"reason":     req.Reason,
+			},
+		})
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		handlers.RespondJSON(w, http.StatusOK, models.ImpersonationResponse{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   int(models.ImpersonationTokenDuration.Seconds()),
+		})
+	}
+}
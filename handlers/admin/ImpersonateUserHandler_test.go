@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/middleware/constants"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/jwt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// impersonateRequest calls handler as the middleware would for the admin's verified claims
+func impersonateRequest(handler http.HandlerFunc, admin *models.Claims, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/impersonate/"+userID, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), constants.UserContextKey, admin))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+const impersonationBody = `{"reason":"support ticket 1234"}`
+
+func TestImpersonateUserRejectsInvalidRequests(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	// Every request here is rejected before the target user is looked up
+	handler := ImpersonateUserHandler(nil, keys, nil, "Super Admin")
+
+	const adminID = "7d0c5c8e-0000-4000-8000-000000000001"
+	const targetID = "7d0c5c8e-0000-4000-8000-000000000002"
+	admin := &models.Claims{UserID: adminID, Role: "Super Admin", TokenType: models.AccessToken, Principal: models.PrincipalUser}
+
+	impersonating := *admin
+	impersonating.Act = &models.Actor{Subject: "7d0c5c8e-0000-4000-8000-000000000003"}
+	service := *admin
+	service.Principal = models.PrincipalService
+	apiKey := *admin
+	apiKey.APIKeyID = "key-1"
+
+	tests := []struct {
+		name   string
+		claims *models.Claims
+		userID string
+		body   string
+		status int
+		error  string
+	}{
+		{"impersonation token", &impersonating, targetID, impersonationBody, http.StatusForbidden, "impersonation tokens cannot impersonate again"},
+		{"service account", &service, targetID, impersonationBody, http.StatusForbidden, "impersonation requires a login session"},
+		{"api key", &apiKey, targetID, impersonationBody, http.StatusForbidden, "impersonation requires a login session"},
+		{"unknown audience", admin, targetID, `{"audience":"other-app"}`, http.StatusBadRequest, "invalid audience"},
+		{"malformed body", admin, targetID, `{"reason":`, http.StatusBadRequest, "invalid request body"},
+		{"no user", admin, "", impersonationBody, http.StatusBadRequest, "invalid user id"},
+		{"self", admin, adminID, impersonationBody, http.StatusBadRequest, "cannot impersonate yourself"},
+	}
+	for _, tt := range tests {
+		rec := impersonateRequest(handler, tt.claims, tt.userID, tt.body)
+		if rec.Code != tt.status {
+			t.Errorf("%s: got status %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+			continue
+		}
+		var response map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response["error"] != tt.error {
+			t.Errorf("%s: got %s, want error %q", tt.name, rec.Body, tt.error)
+		}
+	}
+}
+
+func TestImpersonateUser(t *testing.T) {
+	db := dbtest.Open(t)
+	adminUser := dbtest.CreateUser(t, db)
+	target := dbtest.CreateUser(t, db)
+	superAdmin := dbtest.CreateUser(t, db)
+	if _, err := queries.UpdateUserRole(db, superAdmin.ID, "Super Admin"); err != nil {
+		t.Fatalf("UpdateUserRole: %v", err)
+	}
+
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	handler := ImpersonateUserHandler(db, keys, audit.NewLogger(db), "Super Admin")
+	admin := jwt.NewClaims(adminUser, models.AccessToken)
+
+	if rec := impersonateRequest(handler, admin, superAdmin.ID, impersonationBody); rec.Code != http.StatusForbidden {
+		t.Errorf("super admin target: got status %d, want 403", rec.Code)
+	}
+	if rec := impersonateRequest(handler, admin, "00000000-0000-4000-8000-000000000000", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown target: got status %d, want 404", rec.Code)
+	}
+
+	rec := impersonateRequest(handler, admin, target.ID, impersonationBody)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	var response models.ImpersonationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if response.TokenType != "Bearer" || response.ExpiresIn != int(models.ImpersonationTokenDuration.Seconds()) {
+		t.Errorf("got token_type %q expires_in %d", response.TokenType, response.ExpiresIn)
+	}
+
+	claims, err := jwt.VerifyToken(response.AccessToken, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if claims.UserID != target.ID || claims.Act == nil || claims.Act.Subject != adminUser.ID {
+		t.Fatalf("got sub %q act %+v, want the target acted on by the admin", claims.UserID, claims.Act)
+	}
+
+	entries, err := queries.GetAuditLog(db, models.AuditLogFilter{
+		ActorID:   adminUser.ID,
+		SubjectID: target.ID,
+		Action:    models.AuditImpersonationStarted,
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("GetAuditLog: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Details["jti"] != claims.ID || entries[0].Details["reason"] != "support ticket 1234" {
+		t.Fatalf("got audit entries %+v, want one for the issued token", entries)
+	}
+}
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+	"strings"
+)
+
+// ResetUserMFAHandler removes a user's MFA, e.g. after they lost their authenticator and
+// recovery codes (Super Admin only). The user can sign in with their password and enroll again.
+func ResetUserMFAHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Extract user ID from URL path
+		// Expected format: /admin/users/mfa/reset/{uuid}
+		userID := strings.TrimPrefix(r.URL.Path, "/admin/users/mfa/reset/")
+		if userID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+			return
+		}
+
+		if err := queries.DeleteUserMFA(database, userID); err != nil {
+			if err == queries.ErrMFANotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "mfa is not enabled for this user"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to reset mfa"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditMFAReset,
+			ActorID:   claims.UserID,
+			SubjectID: userID,
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "mfa reset"})
+	}
+}
@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/secure"
+	"net/http"
+	"strings"
+)
+
+// RotateServiceAccountSecretHandler issues a new client secret for a service account (Super Admin only).
+// The old secret stops working immediately.
+func RotateServiceAccountSecretHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Extract service account ID from URL path
+		// Expected format: /admin/service-accounts/rotate/{uuid}
+		accountID := strings.TrimPrefix(r.URL.Path, "/admin/service-accounts/rotate/")
+		if accountID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid service account id"})
+			return
+		}
+
+		clientSecret := secure.RandomToken(32)
+		account, err := queries.RotateServiceAccountSecret(database, accountID, secure.HashToken(clientSecret))
+		if err != nil {
+			if err == queries.ErrServiceAccountNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "service account not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to rotate secret"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditServiceAccountRotated,
+			ActorID:   claims.UserID,
+			SubjectID: account.ID,
+			Details:   map[string]string{"client_id": account.ClientID},
+		})
+
+		response := models.ServiceAccountCredentialsResponse{
+			ServiceAccount: account,
+			ClientSecret:   clientSecret,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
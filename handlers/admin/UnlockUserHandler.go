@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"net/http"
+	"strings"
+)
+
+// UnlockUserHandler clears a user's lockout and failed login count (Super Admin only)
+func UnlockUserHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Extract user ID from URL path
+		// Expected format: /admin/users/unlock/{uuid}
+		userID := strings.TrimPrefix(r.URL.Path, "/admin/users/unlock/")
+		if userID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+			return
+		}
+
+		if err := queries.ResetFailedLogins(database, userID); err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to unlock user"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "user unlocked"})
+	}
+}
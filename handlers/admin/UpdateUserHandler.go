@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	queries "go-auth/db/Queries"
 	"go-auth/handlers"
+	authhandler "go-auth/handlers/auth"
 	"go-auth/models"
+	"go-auth/utils/jwt"
 	"net/http"
 	"strings"
 )
 
-// UpdateUserHandler updates a user's username and/or email (Super Admin only)
-func UpdateUserHandler(database *sql.DB) http.HandlerFunc {
+// UpdateUserHandler updates a user's username and/or email (Super Admin only).
+// A changed email has to be verified again, so a verification link is sent to the new address.
+func UpdateUserHandler(database *sql.DB, keys *jwt.KeySet, verification *authhandler.EmailVerification) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPatch {
 			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -76,6 +79,10 @@ func UpdateUserHandler(database *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		if updatedUser.Email != currentUser.Email {
+			go authhandler.SendVerificationEmail(keys, verification, updatedUser)
+		}
+
 		handlers.RespondJSON(w, http.StatusOK, updatedUser)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"go-auth/db/dbtest"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/utils/jwt"
+	"go-auth/utils/mailer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// channelMailer hands every message to the test
+type channelMailer chan *mailer.Message
+
+func (m channelMailer) Send(msg *mailer.Message) error {
+	m <- msg
+	return nil
+}
+
+func TestUpdateUserHandlerSendsVerificationEmail(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	sent := make(channelMailer, 1)
+	handler := UpdateUserHandler(db, keys, &authhandler.EmailVerification{Mailer: sent})
+
+	update := func(body string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPatch, "/admin/users/update/"+user.ID, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", body, rec.Code, rec.Body)
+		}
+	}
+
+	// Renaming the user leaves the email alone, so nothing is sent
+	update(`{"username": "` + user.Username + `_renamed"}`)
+	select {
+	case msg := <-sent:
+		t.Fatalf("username change sent an email to %s", msg.To)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	newEmail := "changed_" + user.Email
+	update(`{"email": "` + newEmail + `"}`)
+	select {
+	case msg := <-sent:
+		if msg.To != newEmail {
+			t.Fatalf("verification email sent to %s, want %s", msg.To, newEmail)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no verification email sent to the new address")
+	}
+}
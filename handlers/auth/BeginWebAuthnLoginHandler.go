@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"go-auth/utils/webauthn"
+	"net/http"
+	"time"
+)
+
+// BeginWebAuthnLoginHandler starts a passwordless login. The response is passed to
+// navigator.credentials.get(), which lets the user pick one of their passkeys for this
+// service, and the result to /login/webauthn/finish.
+func BeginWebAuthnLoginHandler(database *sql.DB, rp *webauthn.RelyingParty) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Only the challenge's hash is stored; the user is only known once it is answered
+		challenge := webauthn.NewChallenge()
+		err := queries.CreateWebAuthnChallenge(database, secure.HashToken(challenge), models.WebAuthnCeremonyLogin,
+			"", time.Now().Add(models.WebAuthnChallengeDuration))
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start passkey login"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, models.WebAuthnRequestOptionsResponse{
+			PublicKey: rp.RequestOptions(challenge),
+		})
+	}
+}
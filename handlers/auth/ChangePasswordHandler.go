@@ -7,12 +7,15 @@ import (
 	"go-auth/handlers"
 	"go-auth/middleware/auth"
 	"go-auth/models"
+	"go-auth/utils/jwt"
 	"go-auth/utils/password"
 	"net/http"
 )
 
-// ChangePasswordHandler handles password changes (requires authentication)
-func ChangePasswordHandler(database *sql.DB) http.HandlerFunc {
+// ChangePasswordHandler handles password changes (requires authentication).
+// Every previously issued token is invalidated and a fresh token pair is returned.
+// It also accepts the restricted token LoginHandler issues when a change is required.
+func ChangePasswordHandler(database *sql.DB, keys *jwt.KeySet, passwordRules *handlers.PasswordRules) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -55,6 +58,38 @@ func ChangePasswordHandler(database *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		// Enforce the password policy and breach screening
+		errs, err := passwordRules.Check("new_password", req.NewPassword, user.Username, user.Email)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to check password"})
+			return
+		}
+		if len(errs) > 0 {
+			handlers.RespondValidationError(w, errs)
+			return
+		}
+
+		// A forced change must pick a different password even with the history check disabled
+		if claims.TokenType == models.PasswordChangeToken && password.VerifyPassword(user.Password, req.NewPassword) {
+			handlers.RespondValidationError(w, []models.FieldError{{
+				Field:   "new_password",
+				Code:    "reused",
+				Message: "must differ from the current password",
+			}})
+			return
+		}
+
+		// Reject recently used passwords
+		errs, err = passwordRules.CheckHistory(database, user, "new_password", req.NewPassword)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to check password"})
+			return
+		}
+		if len(errs) > 0 {
+			handlers.RespondValidationError(w, errs)
+			return
+		}
+
 		// Hash new password
 		hashedPassword, err := password.HashPassword(req.NewPassword)
 		if err != nil {
@@ -62,12 +97,34 @@ func ChangePasswordHandler(database *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Update password
-		if err := queries.UpdatePassword(database, claims.UserID, hashedPassword); err != nil {
+		// Update password, keeping the old hash in the history (also clears must_change_password)
+		if err := queries.ChangePassword(database, claims.UserID, hashedPassword, passwordRules.Policy.HistoryDepth); err != nil {
 			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update password"})
 			return
 		}
 
-		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "password changed successfully"})
+		// Invalidate tokens issued with the old password
+		if err := queries.BumpTokenVersion(database, claims.UserID); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke old tokens"})
+			return
+		}
+
+		// Issue a new token pair so this session continues with the same audience and login details
+		user, err = queries.GetUserByID(database, claims.UserID)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+		tokens, err := IssueTokenPair(database, keys, user, "", claims.SessionOptions())
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate tokens"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{
+			"message":       "password changed successfully",
+			"access_token":  tokens.AccessToken,
+			"refresh_token": tokens.RefreshToken,
+		})
 	}
 }
\ No newline at end of file
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"go-auth/models"
+	"time"
+)
+
+// maxDelayShift caps the exponent of the progressive delay so the shift can't overflow
+const maxDelayShift = 20
+
+// CheckLoginThrottle reports whether a login attempt for user has to be refused before the
+// password is even checked. It returns the error code (models.LoginErrorAccountLocked or
+// models.LoginErrorThrottled) and how long to wait, or "" if the attempt may proceed.
+func CheckLoginThrottle(user *models.User, policy *models.LockoutPolicy) (string, time.Duration) {
+	now := time.Now()
+
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return models.LoginErrorAccountLocked, user.LockedUntil.Sub(now)
+	}
+
+	// An expired lockout starts a fresh count
+	if user.LockedUntil != nil || policy.DelayAfter == 0 || user.FailedLoginAttempts < policy.DelayAfter || user.LastFailedLoginAt == nil {
+		return "", 0
+	}
+
+	shift := user.FailedLoginAttempts - policy.DelayAfter
+	delay := policy.DelayMax
+	if shift < maxDelayShift {
+		delay = min(policy.DelayBase<<shift, policy.DelayMax)
+	}
+
+	if wait := user.LastFailedLoginAt.Add(delay).Sub(now); wait > 0 {
+		return models.LoginErrorThrottled, wait
+	}
+	return "", 0
+}
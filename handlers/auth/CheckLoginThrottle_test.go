@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"go-auth/models"
+	"testing"
+	"time"
+)
+
+func TestCheckLoginThrottle(t *testing.T) {
+	policy := &models.LockoutPolicy{Threshold: 10, Duration: 15 * time.Minute, DelayAfter: 3, DelayBase: time.Second, DelayMax: 30 * time.Second}
+	now := time.Now()
+	at := func(offset time.Duration) *time.Time {
+		when := now.Add(offset)
+		return &when
+	}
+
+	tests := []struct {
+		name     string
+		user     models.User
+		wantCode string
+		wantWait time.Duration // Upper bound; the wait shrinks while the test runs
+	}{
+		{"no failures", models.User{}, "", 0},
+		{"below delay threshold", models.User{FailedLoginAttempts: 2, LastFailedLoginAt: at(0)}, "", 0},
+		{"first delay", models.User{FailedLoginAttempts: 3, LastFailedLoginAt: at(0)}, models.LoginErrorThrottled, time.Second},
+		{"delay doubles", models.User{FailedLoginAttempts: 5, LastFailedLoginAt: at(0)}, models.LoginErrorThrottled, 4 * time.Second},
+		{"delay capped", models.User{FailedLoginAttempts: 9, LastFailedLoginAt: at(0)}, models.LoginErrorThrottled, 30 * time.Second},
+		{"shift capped", models.User{FailedLoginAttempts: 500, LastFailedLoginAt: at(0)}, models.LoginErrorThrottled, 30 * time.Second},
+		{"delay elapsed", models.User{FailedLoginAttempts: 5, LastFailedLoginAt: at(-5 * time.Second)}, "", 0},
+		{"locked", models.User{FailedLoginAttempts: 10, LastFailedLoginAt: at(0), LockedUntil: at(15 * time.Minute)}, models.LoginErrorAccountLocked, 15 * time.Minute},
+		{"lockout expired", models.User{FailedLoginAttempts: 10, LastFailedLoginAt: at(-16 * time.Minute), LockedUntil: at(-time.Minute)}, "", 0},
+	}
+	for _, tt := range tests {
+		code, wait := CheckLoginThrottle(&tt.user, policy)
+		if code != tt.wantCode {
+			t.Errorf("%s: code = %q, want %q", tt.name, code, tt.wantCode)
+		}
+		if wait > tt.wantWait || (tt.wantWait > 0 && wait < tt.wantWait-time.Second) {
+			t.Errorf("%s: wait = %v, want about %v", tt.name, wait, tt.wantWait)
+		}
+	}
+
+	// Without delays only lockouts refuse attempts
+	disabled := &models.LockoutPolicy{Threshold: 10, Duration: 15 * time.Minute}
+	if code, _ := CheckLoginThrottle(&models.User{FailedLoginAttempts: 9, LastFailedLoginAt: at(0)}, disabled); code != "" {
+		t.Errorf("delays disabled: code = %q, want none", code)
+	}
+}
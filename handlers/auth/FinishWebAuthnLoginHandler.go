@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/secure"
+	"go-auth/utils/webauthn"
+	"log"
+	"net/http"
+)
+
+// FinishWebAuthnLoginHandler completes a passwordless login with the assertion from
+// navigator.credentials.get(). The user is identified by the credential, and the response
+// is the same as from LoginHandler. Passkeys require user verification, so no second
+// factor is asked for.
+func FinishWebAuthnLoginHandler(database *sql.DB, keys *jwt.KeySet, rp *webauthn.RelyingParty, opts *LoginOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req models.FinishWebAuthnLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		credential := req.Credential
+		if credential.Type != "public-key" || len(credential.RawID) == 0 || len(credential.Response.ClientDataJSON) == 0 ||
+			len(credential.Response.AuthenticatorData) == 0 || len(credential.Response.Signature) == 0 {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "credential is required"})
+			return
+		}
+
+		// Optional audience must be one of the configured audiences
+		var audience []string
+		if req.Audience != "" {
+			if !keys.HasAudience(req.Audience) {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience"})
+				return
+			}
+			audience = []string{req.Audience}
+		}
+
+		clientData, err := webauthn.ParseClientData(credential.Response.ClientDataJSON)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid credential"})
+			return
+		}
+
+		// Each challenge answers a single login
+		_, err = queries.ConsumeWebAuthnChallenge(database, secure.HashToken(clientData.Challenge), models.WebAuthnCeremonyLogin)
+		if err != nil {
+			if err == queries.ErrChallengeNotFound {
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or expired challenge"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify passkey"})
+			return
+		}
+
+		stored, err := queries.GetWebAuthnCredential(database, base64.RawURLEncoding.EncodeToString(credential.RawID))
+		if err != nil {
+			if err == queries.ErrCredentialNotFound {
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get passkey"})
+			return
+		}
+
+		// A discoverable credential returns the user handle it was created with
+		if len(credential.Response.UserHandle) > 0 && string(credential.Response.UserHandle) != stored.UserID {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+			return
+		}
+
+		signCount, err := rp.VerifyAssertion(clientData.Challenge, stored.PublicKey, credential.Response.ClientDataJSON,
+			credential.Response.AuthenticatorData, credential.Response.Signature)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+			return
+		}
+
+		user, err := queries.GetUserByID(database, stored.UserID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		// Locked accounts stay locked whichever way the user signs in
+		if code, wait := CheckLoginThrottle(user, opts.Lockout); code != "" {
+			respondLoginThrottled(w, code, wait)
+			return
+		}
+
+		// A counter that went backwards means the credential may have been cloned
+		if err := queries.UpdateWebAuthnSignCount(database, stored.ID, signCount); err != nil {
+			if err == queries.ErrCredentialCloned {
+				log.Printf("Rejected passkey %s of user %s: signature counter %d not above %d", stored.ID, user.ID, signCount, stored.SignCount)
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update passkey"})
+			return
+		}
+
+		completeLogin(w, database, keys, user, opts, audience, models.WebAuthnAMR)
+	}
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/breach"
+	"log"
+)
+
+// FlagBreachedPassword sets must_change_password when the password is in the breach corpus.
+// Like rehashPassword it is best effort and never fails the login.
+func FlagBreachedPassword(database *sql.DB, breaches breach.Checker, user *models.User, plainPassword string) {
+	breached, err := breaches.IsBreached(plainPassword)
+	if err != nil {
+		log.Printf("Failed to check password of user %s against breach corpus: %v", user.ID, err)
+		return
+	}
+	if !breached {
+		return
+	}
+	if err := queries.SetMustChangePassword(database, user.ID, true); err != nil {
+		log.Printf("Failed to flag breached password of user %s: %v", user.ID, err)
+		return
+	}
+	user.MustChangePassword = true
+}
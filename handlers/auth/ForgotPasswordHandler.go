@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/mailer"
+	"go-auth/utils/secure"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ForgotPasswordHandler emails a single-use password reset link. The response is the same
+// whether or not the email belongs to an account, and the lookup and email happen in the
+// background so response times don't reveal it either.
+// resetURL is the frontend page that takes the token; when empty the email contains the raw token.
+func ForgotPasswordHandler(database *sql.DB, mail mailer.Mailer, resetURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req models.ForgotPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Email == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "email is required"})
+			return
+		}
+
+		go sendPasswordReset(database, mail, resetURL, req.Email)
+
+		handlers.RespondJSON(w, http.StatusAccepted, map[string]string{"message": "if an account exists for this email, a password reset link has been sent"})
+	}
+}
+
+// sendPasswordReset creates a reset token for the account with the given email, if any,
+// and emails it. Failures are logged, never reported to the caller.
+func sendPasswordReset(database *sql.DB, mail mailer.Mailer, resetURL, email string) {
+	user, err := queries.GetUserByEmail(database, email)
+	if err != nil {
+		if err != queries.ErrUserNotFound {
+			log.Printf("Failed to look up user for password reset: %v", err)
+		}
+		return
+	}
+
+	// Only the token hash is stored
+	token := secure.RandomToken(32)
+	expiresAt := time.Now().Add(models.PasswordResetTokenDuration)
+	if err := queries.CreatePasswordResetToken(database, secure.HashToken(token), user.ID, expiresAt); err != nil {
+		log.Printf("Failed to create password reset token for user %s: %v", user.ID, err)
+		return
+	}
+
+	var instructions string
+	if link, err := url.Parse(resetURL); err == nil && resetURL != "" {
+		query := link.Query()
+		query.Set("token", token)
+		link.RawQuery = query.Encode()
+		instructions = "Reset your password here:\n\n" + link.String()
+	} else {
+		instructions = "Use this token to reset your password:\n\n" + token
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account.\n\n%s\n\n"+
+			"This expires in %d minutes and can only be used once. If you didn't ask for this, you can ignore this email.\n",
+			user.Username, instructions, int(models.PasswordResetTokenDuration.Minutes())),
+	}
+	if err := mail.Send(msg); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"strings"
+)
+
+// recoveryCodeAlphabet avoids look-alike characters; codes are case-insensitive
+const recoveryCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// GenerateRecoveryCodes returns a fresh set of recovery codes (e.g. K7QMX-3RT9W) to show the
+// user once, and their hashes to store
+func GenerateRecoveryCodes() ([]string, []string) {
+	codes := make([]string, models.RecoveryCodeCount)
+	hashes := make([]string, models.RecoveryCodeCount)
+	for i := range codes {
+		code := secure.RandomCode(recoveryCodeAlphabet, 10)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(code)
+	}
+	return codes, hashes
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, dashes and spaces
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(c rune) rune {
+		if c == '-' || c == ' ' {
+			return -1
+		}
+		return c
+	}, strings.ToUpper(code))
+	return secure.HashToken(normalized)
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"go-auth/models"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes := GenerateRecoveryCodes()
+	if len(codes) != models.RecoveryCodeCount || len(hashes) != models.RecoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), models.RecoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[` + recoveryCodeAlphabet + `]{5}-[` + recoveryCodeAlphabet + `]{5}$`)
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q doesn't look like K7QMX-3RT9W", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q issued twice", code)
+		}
+		seen[code] = true
+
+		if hashes[i] != hashRecoveryCode(code) {
+			t.Errorf("hash %d doesn't match code %q", i, code)
+		}
+		if strings.Contains(hashes[i], code) {
+			t.Errorf("hash %d contains the code", i)
+		}
+	}
+}
+
+func TestHashRecoveryCodeIgnoresFormatting(t *testing.T) {
+	want := hashRecoveryCode("K7QMX-3RT9W")
+	for _, code := range []string{"K7QMX3RT9W", "k7qmx-3rt9w", "K7QMX 3RT9W", " K7QMX-3RT9W "} {
+		if got := hashRecoveryCode(code); got != want {
+			t.Errorf("%q hashes differently", code)
+		}
+	}
+	if hashRecoveryCode("K7QMX-3RT9X") == want {
+		t.Error("different codes hash the same")
+	}
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/secure"
+	"time"
+)
+
+// IssueTokenPair generates an access token and a refresh token for the user and
+// stores the refresh token hash. An empty familyID starts a new token family.
+// Both tokens carry the session claims in opts.
+func IssueTokenPair(database *sql.DB, keys *jwt.KeySet, user *models.User, familyID string, opts models.TokenOptions) (*models.TokenPair, error) {
+	accessToken, err := jwt.SignClaims(jwt.NewSessionClaims(user, models.AccessToken, opts), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwt.SignClaims(jwt.NewSessionClaims(user, models.RefreshToken, opts), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if familyID == "" {
+		familyID = secure.NewID()
+	}
+
+	expiresAt := time.Now().Add(models.RefreshTokenDuration)
+	if err := queries.CreateRefreshToken(database, user.ID, familyID, secure.HashToken(refreshToken), expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
\ No newline at end of file
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"go-auth/handlers"
+	"go-auth/utils/jwt"
+	"net/http"
+)
+
+// JWKSHandler publishes the public signing keys so other services can verify tokens
+func JWKSHandler(keys *jwt.KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Let verifiers cache the key set, but pick up rotations within minutes
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
+		handlers.RespondJSON(w, http.StatusOK, keys.PublicJWKS())
+	}
+}
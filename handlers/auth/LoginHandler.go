@@ -6,13 +6,27 @@ import (
 	queries "go-auth/db/Queries"
 	"go-auth/handlers"
 	"go-auth/models"
+	"go-auth/utils/breach"
 	"go-auth/utils/jwt"
-	"go-auth/utils/password"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
-// LoginHandler handles user login
-func LoginHandler(database *sql.DB, secretKey string) http.HandlerFunc {
+// LoginOptions are the account checks LoginHandler applies around the password check
+type LoginOptions struct {
+	Breaches             breach.Checker           // When set, users whose password is in the corpus are flagged to change it
+	PasswordMaxAge       map[string]time.Duration // Per role; roles not listed never expire
+	Lockout              *models.LockoutPolicy
+	RequireVerifiedEmail bool // Users who haven't verified their email can't sign in
+}
+
+// LoginHandler handles user login. Locked and throttled accounts are refused before the
+// password is checked. Users with MFA enabled get a challenge token to complete the login
+// at /login/mfa. Users flagged to change their password, and users whose password is
+// older than the maximum age for their role, only get a restricted password change token.
+func LoginHandler(database *sql.DB, keys *jwt.KeySet, opts *LoginOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -31,6 +45,16 @@ func LoginHandler(database *sql.DB, secretKey string) http.HandlerFunc {
 			return
 		}
 
+		// Optional audience must be one of the configured audiences
+		var audience []string
+		if req.Audience != "" {
+			if !keys.HasAudience(req.Audience) {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience"})
+				return
+			}
+			audience = []string{req.Audience}
+		}
+
 		// Get user by email
 		user, err := queries.GetUserByEmail(database, req.Email)
 		if err != nil {
@@ -42,34 +66,112 @@ func LoginHandler(database *sql.DB, secretKey string) http.HandlerFunc {
 			return
 		}
 
+		// Refuse attempts on locked or throttled accounts before checking the password
+		if code, wait := CheckLoginThrottle(user, opts.Lockout); code != "" {
+			respondLoginThrottled(w, code, wait)
+			return
+		}
+
 		// Verify password
-		if !password.VerifyPassword(user.Password, req.Password) {
+		if !VerifyLoginPassword(database, user, req.Password) {
+			RecordLoginAttempt(database, user, opts.Lockout, false)
 			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
 			return
 		}
 
-		// Generate tokens
-		accessToken, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role, models.AccessToken, secretKey)
-		if err != nil {
-			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate access token"})
+		// Flag accounts whose password has since shown up in a breach
+		if opts.Breaches != nil && !user.MustChangePassword {
+			FlagBreachedPassword(database, opts.Breaches, user, req.Password)
+		}
+
+		// Users with MFA enabled complete the login at /login/mfa. The failed login count is
+		// only cleared once they do, so guessing codes can't be reset with the password.
+		mfa, err := queries.GetUserMFA(database, user.ID)
+		if err != nil && err != queries.ErrMFANotFound {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
 			return
 		}
+		if mfa != nil && mfa.Enabled() {
+			token, err := jwt.SignClaims(jwt.NewMFAChallengeClaims(user, audience), keys)
+			if err != nil {
+				handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate tokens"})
+				return
+			}
 
-		refreshToken, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role, models.RefreshToken, secretKey)
+			handlers.RespondJSON(w, http.StatusOK, models.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    token,
+				ExpiresIn:   int(models.MFAChallengeTokenDuration.Seconds()),
+			})
+			return
+		}
+
+		completeLogin(w, database, keys, user, opts, audience, models.PasswordAMR)
+	}
+}
+
+// completeLogin finishes a successful login: it clears the failed login count and responds
+// with a token pair, or only a password change token if the user must change their password.
+// Unverified users are refused when verification is required.
+func completeLogin(w http.ResponseWriter, database *sql.DB, keys *jwt.KeySet, user *models.User, opts *LoginOptions, audience, amr []string) {
+	RecordLoginAttempt(database, user, opts.Lockout, true)
+
+	if opts.RequireVerifiedEmail && !user.EmailVerified() {
+		handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "email not verified", "code": models.LoginErrorEmailNotVerified})
+		return
+	}
+
+	// Users who must change their password can't do anything else yet
+	if reason := PasswordChangeReason(user, opts.PasswordMaxAge); reason != "" {
+		token, err := jwt.SignClaims(jwt.NewPasswordChangeClaims(user, audience, amr), keys)
 		if err != nil {
-			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate refresh token"})
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate tokens"})
 			return
 		}
 
-		// Don't expose password in response
 		user.Password = ""
 
-		response := models.AuthResponse{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
-			User:         *user,
-		}
+		handlers.RespondJSON(w, http.StatusOK, models.PasswordChangeRequiredResponse{
+			PasswordChangeRequired: true,
+			Reason:                 reason,
+			PasswordChangeToken:    token,
+			ExpiresIn:              int(models.PasswordChangeTokenDuration.Seconds()),
+			User:                   *user,
+		})
+		return
+	}
+
+	// Generate tokens (starts a new refresh token family)
+	tokens, err := IssueTokenPair(database, keys, user, "", models.TokenOptions{
+		Audience: audience,
+		AuthTime: time.Now(),
+		AMR:      amr,
+	})
+	if err != nil {
+		handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate tokens"})
+		return
+	}
+
+	// Don't expose password in response
+	user.Password = ""
+
+	response := models.AuthResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         *user,
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, response)
+}
+
+// respondLoginThrottled refuses a login attempt with 423 (locked) or 429 (throttled)
+// and a Retry-After header in whole seconds
+func respondLoginThrottled(w http.ResponseWriter, code string, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
 
-		handlers.RespondJSON(w, http.StatusOK, response)
+	if code == models.LoginErrorAccountLocked {
+		handlers.RespondJSON(w, http.StatusLocked, map[string]string{"error": "account locked", "code": code})
+		return
 	}
-}
\ No newline at end of file
+	handlers.RespondJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many failed login attempts, try again later", "code": code})
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"log"
+	"net/http"
+)
+
+// LoginMFAHandler completes a login for a user with MFA enabled. It takes the challenge token
+// returned by /login and a code from the user's authenticator app or a recovery code.
+// Failed codes count towards the account lockout like failed passwords.
+func LoginMFAHandler(database *sql.DB, verifier *auth.TokenVerifier, opts *LoginOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req models.LoginMFARequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.MFAToken == "" || req.Code == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "mfa_token and code are required"})
+			return
+		}
+
+		claims, err := verifier.Verify(req.MFAToken, models.MFAChallengeToken)
+		if err != nil {
+			switch err {
+			case jwt.ErrExpiredToken:
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "mfa token expired"})
+			case jwt.ErrInvalidToken, jwt.ErrInvalidClaims, jwt.ErrInvalidTokenType, revocation.ErrTokenRevoked:
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid mfa token"})
+			default:
+				handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify mfa token"})
+			}
+			return
+		}
+
+		user, err := queries.GetUserByID(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid mfa token"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		// A password change or forced logout since the challenge was issued voids it
+		if claims.TokenVersion < user.TokenVersion {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid mfa token"})
+			return
+		}
+
+		if code, wait := CheckLoginThrottle(user, opts.Lockout); code != "" {
+			respondLoginThrottled(w, code, wait)
+			return
+		}
+
+		mfa, err := queries.GetUserMFA(database, user.ID)
+		if err != nil && err != queries.ErrMFANotFound {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+		if mfa == nil || !mfa.Enabled() {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "mfa is not enabled"})
+			return
+		}
+
+		method, err := VerifyMFACode(database, mfa, req.Code)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify mfa code"})
+			return
+		}
+		if method == "" {
+			RecordLoginAttempt(database, user, opts.Lockout, false)
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid mfa code"})
+			return
+		}
+
+		// The challenge token is single-use
+		if err := verifier.Denylist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+			log.Printf("Failed to revoke mfa token of user %s: %v", user.ID, err)
+		}
+
+		completeLogin(w, database, verifier.Keys, user, opts, claims.LoginAudience, models.SecondFactorAMR(method))
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/utils/revocation"
+	"net/http"
+)
+
+// LogoutAllHandler revokes the current access token and every refresh token
+// the user holds, ending all sessions on all devices (requires authentication)
+func LogoutAllHandler(database *sql.DB, denylist revocation.Denylist) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Only users have sessions; API keys are revoked explicitly
+		if claims.IsService() {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "service accounts have no sessions to log out"})
+			return
+		}
+		if claims.IsAPIKey() {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "api keys cannot log out, revoke the key instead"})
+			return
+		}
+
+		// Invalidate every access token issued so far (enforced by the online check)
+		if err := queries.BumpTokenVersion(database, claims.UserID); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke sessions"})
+			return
+		}
+
+		// Revoke every refresh token so no session can mint new access tokens
+		if err := queries.RevokeUserRefreshTokens(database, claims.UserID); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke sessions"})
+			return
+		}
+
+		// Revoke the access token used for this request
+		if err := denylist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke token"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "logged out of all sessions"})
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"go-auth/middleware/constants"
+	"go-auth/models"
+	"go-auth/utils/revocation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLogoutAllRefusesPrincipalsWithoutSessions(t *testing.T) {
+	// Refused before the token version is bumped, so no database is needed
+	handler := LogoutAllHandler(nil, revocation.NewMemoryDenylist())
+
+	tests := map[string]*models.Claims{
+		"service account": {UserID: "svc-1", TokenType: models.AccessToken, Principal: models.PrincipalService, ClientID: "svc-client"},
+		"api key":         {UserID: "7d0c5c8e-0000-4000-8000-000000000001", TokenType: models.AccessToken, APIKeyID: "key-1"},
+	}
+	for name, claims := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/logout/all", nil)
+		req = req.WithContext(context.WithValue(req.Context(), constants.UserContextKey, claims))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", name, rec.Code)
+		}
+	}
+}
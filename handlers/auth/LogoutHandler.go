@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"io"
+	"net/http"
+)
+
+// LogoutHandler revokes the current access token (requires authentication).
+// If a refresh token is posted too, its token family is revoked.
+func LogoutHandler(database *sql.DB, verifier *auth.TokenVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// API keys are revoked explicitly, not by logging out
+		if claims.IsAPIKey() {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "api keys cannot log out, revoke the key instead"})
+			return
+		}
+
+		// Body is optional
+		var req models.LogoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Revoke the refresh token's family, if it belongs to the same user
+		if req.RefreshToken != "" {
+			refreshClaims, err := verifier.Verify(req.RefreshToken, models.RefreshToken)
+			if err == nil && refreshClaims.UserID == claims.UserID {
+				record, err := queries.GetRefreshTokenByHash(database, secure.HashToken(req.RefreshToken))
+				if err == nil {
+					if err := queries.RevokeRefreshTokenFamily(database, record.FamilyID); err != nil {
+						handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke refresh token"})
+						return
+					}
+				}
+				if err := verifier.Denylist.Revoke(refreshClaims.ID, refreshClaims.ExpiresAt.Time); err != nil {
+					handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke refresh token"})
+					return
+				}
+			}
+		}
+
+		// Revoke the access token used for this request
+		if err := verifier.Denylist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke token"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "logged out successfully"})
+	}
+}
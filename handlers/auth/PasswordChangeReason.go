@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"go-auth/models"
+	"time"
+)
+
+// PasswordChangeReason reports why the user has to change their password before signing in
+// (models.PasswordChangeReasonRequired or models.PasswordChangeReasonExpired), or "" if they don't
+func PasswordChangeReason(user *models.User, passwordMaxAge map[string]time.Duration) string {
+	if user.MustChangePassword {
+		return models.PasswordChangeReasonRequired
+	}
+	if maxAge, ok := passwordMaxAge[user.Role]; ok && time.Since(user.PasswordChangedAt) > maxAge {
+		return models.PasswordChangeReasonExpired
+	}
+	return ""
+}
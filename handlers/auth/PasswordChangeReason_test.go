@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"go-auth/models"
+	"testing"
+	"time"
+)
+
+func TestPasswordChangeReason(t *testing.T) {
+	maxAge := map[string]time.Duration{"User": 90 * 24 * time.Hour}
+
+	tests := []struct {
+		name string
+		user models.User
+		want string
+	}{
+		{"current password", models.User{Role: "User", PasswordChangedAt: time.Now()}, ""},
+		{"flagged", models.User{Role: "User", MustChangePassword: true, PasswordChangedAt: time.Now()}, models.PasswordChangeReasonRequired},
+		{"expired", models.User{Role: "User", PasswordChangedAt: time.Now().Add(-91 * 24 * time.Hour)}, models.PasswordChangeReasonExpired},
+		{"flagged and expired", models.User{Role: "User", MustChangePassword: true, PasswordChangedAt: time.Now().Add(-91 * 24 * time.Hour)}, models.PasswordChangeReasonRequired},
+		{"role without max age", models.User{Role: "Manager", PasswordChangedAt: time.Now().Add(-365 * 24 * time.Hour)}, ""},
+	}
+	for _, tt := range tests {
+		if got := PasswordChangeReason(&tt.user, maxAge); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"go-auth/handlers"
+	"go-auth/models"
+	"net/http"
+)
+
+// PasswordPolicyHandler returns the password rules so frontends can validate before submitting
+func PasswordPolicyHandler(policy *models.PasswordPolicy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, policy)
+	}
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"log"
+	"time"
+)
+
+// RecordLoginAttempt updates the user's failed login count after a password check: failures
+// are counted (locking the account at the policy threshold) and a success clears the count.
+// It is best effort, so a database error never changes the login's outcome.
+func RecordLoginAttempt(database *sql.DB, user *models.User, policy *models.LockoutPolicy, succeeded bool) {
+	if succeeded {
+		if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+			return
+		}
+		if err := queries.ResetFailedLogins(database, user.ID); err != nil {
+			log.Printf("Failed to reset failed logins for user %s: %v", user.ID, err)
+		}
+		return
+	}
+
+	if policy.Threshold == 0 && policy.DelayAfter == 0 {
+		return
+	}
+	if _, err := queries.RecordFailedLogin(database, user.ID, policy.Threshold, time.Now().Add(policy.Duration)); err != nil {
+		log.Printf("Failed to record failed login for user %s: %v", user.ID, err)
+	}
+}
@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	queries "go-auth/db/Queries"
 	"go-auth/handlers"
+	"go-auth/middleware/auth"
 	"go-auth/models"
 	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
 	"net/http"
 )
 
-// RefreshTokenHandler handles token refresh
-func RefreshTokenHandler(database *sql.DB, secretKey string) http.HandlerFunc {
+// RefreshTokenHandler handles token refresh, rotating the refresh token on every call
+func RefreshTokenHandler(database *sql.DB, verifier *auth.TokenVerifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -29,45 +31,28 @@ func RefreshTokenHandler(database *sql.DB, secretKey string) http.HandlerFunc {
 			return
 		}
 
-		// Verify refresh token
-		claims, err := jwt.VerifyToken(req.RefreshToken, secretKey)
+		// Exchange the refresh token for a new pair
+		tokens, _, err := RotateRefreshToken(database, verifier, req.RefreshToken, "")
 		if err != nil {
-			if err == jwt.ErrExpiredToken {
+			switch err {
+			case jwt.ErrExpiredToken:
 				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "refresh token expired"})
-				return
-			}
-			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid refresh token"})
-			return
-		}
-
-		// Verify it's a refresh token
-		if claims.TokenType != models.RefreshToken {
-			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token type"})
-			return
-		}
-
-		// Get user to ensure they still exist
-		user, err := queries.GetUserByID(database, claims.UserID)
-		if err != nil {
-			if err == queries.ErrUserNotFound {
+			case jwt.ErrInvalidTokenType:
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token type"})
+			case revocation.ErrTokenRevoked:
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "refresh token revoked"})
+			case queries.ErrRefreshTokenReused:
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "refresh token reuse detected, session revoked"})
+			case queries.ErrUserNotFound:
 				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "user not found"})
-				return
+			case jwt.ErrInvalidToken, jwt.ErrInvalidClaims, queries.ErrRefreshTokenNotFound:
+				handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid refresh token"})
+			default:
+				handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to refresh token"})
 			}
-			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
 			return
 		}
 
-		// Generate new access token
-		accessToken, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role, models.AccessToken, secretKey)
-		if err != nil {
-			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate access token"})
-			return
-		}
-
-		response := map[string]string{
-			"access_token": accessToken,
-		}
-
-		handlers.RespondJSON(w, http.StatusOK, response)
+		handlers.RespondJSON(w, http.StatusOK, tokens)
 	}
-}
\ No newline at end of file
+}
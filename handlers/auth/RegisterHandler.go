@@ -11,8 +11,9 @@ import (
 	"net/http"
 )
 
-// RegisterHandler handles user registration
-func RegisterHandler(database *sql.DB, secretKey, defaultRole string) http.HandlerFunc {
+// RegisterHandler handles user registration and emails the new user a verification link.
+// When verification is required, no tokens are issued until the email is verified.
+func RegisterHandler(database *sql.DB, keys *jwt.KeySet, defaultRole string, passwordRules *handlers.PasswordRules, verification *EmailVerification) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -31,6 +32,17 @@ func RegisterHandler(database *sql.DB, secretKey, defaultRole string) http.Handl
 			return
 		}
 
+		// Enforce the password policy and breach screening
+		errs, err := passwordRules.Check("password", req.Password, req.Username, req.Email)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to check password"})
+			return
+		}
+		if len(errs) > 0 {
+			handlers.RespondValidationError(w, errs)
+			return
+		}
+
 		// Hash password
 		hashedPassword, err := password.HashPassword(req.Password)
 		if err != nil {
@@ -49,25 +61,29 @@ func RegisterHandler(database *sql.DB, secretKey, defaultRole string) http.Handl
 			return
 		}
 
-		// Generate tokens
-		accessToken, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role, models.AccessToken, secretKey)
-		if err != nil {
-			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate access token"})
+		// Don't expose password in response
+		user.Password = ""
+
+		go SendVerificationEmail(keys, verification, user)
+
+		if verification.Required {
+			handlers.RespondJSON(w, http.StatusCreated, models.EmailVerificationRequiredResponse{
+				EmailVerificationRequired: true,
+				User:                      *user,
+			})
 			return
 		}
 
-		refreshToken, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role, models.RefreshToken, secretKey)
+		// Generate tokens (starts a new refresh token family)
+		tokens, err := IssueTokenPair(database, keys, user, "", models.TokenOptions{})
 		if err != nil {
-			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate refresh token"})
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate tokens"})
 			return
 		}
 
-		// Don't expose password in response
-		user.Password = ""
-
 		response := models.AuthResponse{
-			AccessToken:  accessToken,
-			RefreshToken: refreshToken,
+			AccessToken:  tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
 			User:         *user,
 		}
 
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"log"
+	"net/http"
+)
+
+// ResendVerificationHandler sends another verification email. It needs no token, since users
+// may not be able to sign in before verifying. Like ForgotPasswordHandler, the response is the
+// same whether or not the email belongs to an unverified account and the email is sent in the background.
+func ResendVerificationHandler(database *sql.DB, keys *jwt.KeySet, verification *EmailVerification) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req models.ResendVerificationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Email == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "email is required"})
+			return
+		}
+
+		go resendVerification(database, keys, verification, req.Email)
+
+		handlers.RespondJSON(w, http.StatusAccepted, map[string]string{"message": "if an unverified account exists for this email, a verification link has been sent"})
+	}
+}
+
+// resendVerification emails a new verification link to the account with the given email,
+// unless there is none or it is already verified
+func resendVerification(database *sql.DB, keys *jwt.KeySet, verification *EmailVerification, email string) {
+	user, err := queries.GetUserByEmail(database, email)
+	if err != nil {
+		if err != queries.ErrUserNotFound {
+			log.Printf("Failed to look up user for email verification: %v", err)
+		}
+		return
+	}
+	if user.EmailVerified() {
+		return
+	}
+
+	SendVerificationEmail(keys, verification, user)
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/password"
+	"go-auth/utils/secure"
+	"net/http"
+)
+
+// ResetPasswordHandler sets a new password using a token from ForgotPasswordHandler.
+// The token is single-use, and every session the user had is revoked.
+func ResetPasswordHandler(database *sql.DB, passwordRules *handlers.PasswordRules) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req models.ResetPasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Token == "" || req.NewPassword == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "token and new password are required"})
+			return
+		}
+
+		// Look the token up first so a password the policy rejects doesn't use it up
+		tokenHash := secure.HashToken(req.Token)
+		userID, err := queries.GetPasswordResetToken(database, tokenHash)
+		if err != nil {
+			if err == queries.ErrResetTokenNotFound {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired reset token"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to reset password"})
+			return
+		}
+
+		user, err := queries.GetUserByID(database, userID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired reset token"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		// Enforce the password policy, breach screening and password history
+		errs, err := passwordRules.Check("new_password", req.NewPassword, user.Username, user.Email)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to check password"})
+			return
+		}
+		if len(errs) > 0 {
+			handlers.RespondValidationError(w, errs)
+			return
+		}
+		errs, err = passwordRules.CheckHistory(database, user, "new_password", req.NewPassword)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to check password"})
+			return
+		}
+		if len(errs) > 0 {
+			handlers.RespondValidationError(w, errs)
+			return
+		}
+
+		// Hash new password
+		hashedPassword, err := password.HashPassword(req.NewPassword)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to hash password"})
+			return
+		}
+
+		// Use up the token (and any other outstanding ones); fails if a concurrent request got there first
+		if _, err := queries.ConsumePasswordResetToken(database, tokenHash); err != nil {
+			if err == queries.ErrResetTokenNotFound {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired reset token"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to reset password"})
+			return
+		}
+
+		// Update password, keeping the old hash in the history (also clears must_change_password)
+		if err := queries.ChangePassword(database, user.ID, hashedPassword, passwordRules.Policy.HistoryDepth); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update password"})
+			return
+		}
+
+		// Whoever knew the old password must not stay signed in
+		if err := queries.BumpTokenVersion(database, user.ID); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke old tokens"})
+			return
+		}
+		if err := queries.RevokeUserRefreshTokens(database, user.ID); err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke old tokens"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "password reset successfully"})
+	}
+}
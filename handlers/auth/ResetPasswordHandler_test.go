@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/mailer"
+	"go-auth/utils/password"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// channelMailer hands every message to the test
+type channelMailer chan *mailer.Message
+
+func (m channelMailer) Send(msg *mailer.Message) error {
+	m <- msg
+	return nil
+}
+
+// postJSON sends body to handler and returns the status and decoded response
+func postJSON(t *testing.T, handler http.HandlerFunc, body string) (int, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	var resp map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestPasswordResetRejectsInvalidRequests(t *testing.T) {
+	rules := &handlers.PasswordRules{Policy: &models.PasswordPolicy{MinLength: 8}}
+	forgot := ForgotPasswordHandler(nil, channelMailer(make(chan *mailer.Message, 1)), "")
+	reset := ResetPasswordHandler(nil, rules)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+		want    string
+	}{
+		{"forgot without email", forgot, `{}`, "email is required"},
+		{"forgot with invalid body", forgot, `{`, "invalid request body"},
+		{"reset without token", reset, `{"new_password":"a-new-password"}`, "token and new password are required"},
+		{"reset without password", reset, `{"token":"abc"}`, "token and new password are required"},
+	}
+	for _, tt := range tests {
+		status, resp := postJSON(t, tt.handler, tt.body)
+		if status != http.StatusBadRequest || resp["error"] != tt.want {
+			t.Errorf("%s: got %d %v, want 400 %q", tt.name, status, resp, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	forgot(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status = %d, want 405", rec.Code)
+	}
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	rules := &handlers.PasswordRules{Policy: &models.PasswordPolicy{MinLength: 8, HistoryDepth: 1}}
+	mail := make(channelMailer, 1)
+	forgot := ForgotPasswordHandler(db, mail, "https://app.example.com/reset")
+	reset := ResetPasswordHandler(db, rules)
+
+	// Unknown and known emails get the same answer, but only the account is emailed
+	status, unknown := postJSON(t, forgot, `{"email":"nobody-`+user.ID+`@example.com"}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("unknown email: status = %d, want 202", status)
+	}
+	status, known := postJSON(t, forgot, `{"email":"`+user.Email+`"}`)
+	if status != http.StatusAccepted || known["message"] != unknown["message"] {
+		t.Fatalf("known email: got %d %v, want the same answer as %v", status, known, unknown)
+	}
+
+	var msg *mailer.Message
+	select {
+	case msg = <-mail:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reset email was sent")
+	}
+	if msg.To != user.Email {
+		t.Fatalf("email sent to %q, want %q", msg.To, user.Email)
+	}
+	select {
+	case other := <-mail:
+		t.Fatalf("unexpected email to %q", other.To)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(msg.Body))
+	if err != nil {
+		t.Fatalf("no reset link in %q: %v", msg.Body, err)
+	}
+	token := link.Query().Get("token")
+	if token == "" {
+		t.Fatalf("reset link %q has no token", link)
+	}
+
+	// A password the policy rejects doesn't use the token up
+	status, resp := postJSON(t, reset, `{"token":"`+token+`","new_password":"short"}`)
+	if status != http.StatusBadRequest || resp["fields"] == nil {
+		t.Fatalf("short password: got %d %v, want field errors", status, resp)
+	}
+
+	status, resp = postJSON(t, reset, `{"token":"`+token+`","new_password":"a-brand-new-password"}`)
+	if status != http.StatusOK {
+		t.Fatalf("reset: got %d %v", status, resp)
+	}
+	updated, err := queries.GetUserByID(db, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if !password.VerifyPassword(updated.Password, "a-brand-new-password") {
+		t.Error("the new password was not stored")
+	}
+	if updated.TokenVersion != user.TokenVersion+1 {
+		t.Errorf("token version = %d, want %d so old sessions are revoked", updated.TokenVersion, user.TokenVersion+1)
+	}
+
+	// The token is single-use
+	status, resp = postJSON(t, reset, `{"token":"`+token+`","new_password":"yet-another-password"}`)
+	if status != http.StatusBadRequest || resp["error"] != "invalid or expired reset token" {
+		t.Errorf("reused token: got %d %v", status, resp)
+	}
+}
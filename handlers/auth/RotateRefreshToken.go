@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"go-auth/utils/secure"
+)
+
+// RotateRefreshToken exchanges a refresh token for a new token pair in the same family.
+// The presented token is invalidated. Presenting an already rotated or revoked token
+// revokes the whole family and returns queries.ErrRefreshTokenReused.
+// clientID must match the OAuth client the token was issued to ("" for first-party logins).
+func RotateRefreshToken(database *sql.DB, verifier *auth.TokenVerifier, refreshToken, clientID string) (*models.TokenPair, *models.User, error) {
+	// Verify signature, expiry, type and denylist
+	claims, err := verifier.Verify(refreshToken, models.RefreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A refresh token can only be redeemed by the client it was issued to
+	if claims.ClientID != clientID {
+		return nil, nil, jwt.ErrInvalidClaims
+	}
+
+	// Mark the stored token as used
+	tokenHash := secure.HashToken(refreshToken)
+	record, err := queries.ConsumeRefreshToken(database, tokenHash)
+	if err != nil {
+		if err != queries.ErrRefreshTokenNotFound {
+			return nil, nil, err
+		}
+
+		// A known token that was already used or revoked means it leaked:
+		// revoke the whole family so neither party can keep refreshing
+		previous, lookupErr := queries.GetRefreshTokenByHash(database, tokenHash)
+		if lookupErr == nil && (previous.UsedAt != nil || previous.RevokedAt != nil) {
+			if err := queries.RevokeRefreshTokenFamily(database, previous.FamilyID); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, queries.ErrRefreshTokenReused
+		}
+		return nil, nil, queries.ErrRefreshTokenNotFound
+	}
+
+	if record.UserID != claims.UserID {
+		return nil, nil, jwt.ErrInvalidClaims
+	}
+
+	// Get user to ensure they still exist
+	user, err := queries.GetUserByID(database, claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Tokens issued before a role/password change, deletion or forced logout are dead
+	if claims.TokenVersion < user.TokenVersion {
+		if err := queries.RevokeRefreshTokenFamily(database, record.FamilyID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, revocation.ErrTokenRevoked
+	}
+
+	tokens, err := IssueTokenPair(database, verifier.Keys, user, record.FamilyID, claims.SessionOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRotateRefreshTokenDetectsReuse(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+
+	authTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	first, err := IssueTokenPair(db, keys, user, "", models.TokenOptions{AuthTime: authTime, AMR: models.PasswordAMR})
+	if err != nil {
+		t.Fatalf("IssueTokenPair: %v", err)
+	}
+
+	// Only the client the token was issued to can redeem it
+	if _, _, err := RotateRefreshToken(db, verifier, first.RefreshToken, "some-client"); err != jwt.ErrInvalidClaims {
+		t.Fatalf("other client: got %v, want ErrInvalidClaims", err)
+	}
+
+	second, _, err := RotateRefreshToken(db, verifier, first.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	claims, err := verifier.Verify(second.AccessToken, models.AccessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.AuthTime == nil || !claims.AuthTime.Time.Equal(authTime) || !slices.Equal(claims.AMR, models.PasswordAMR) {
+		t.Fatalf("rotation lost the session claims: auth_time %v, amr %v", claims.AuthTime, claims.AMR)
+	}
+
+	// Replaying the used token revokes the family, including the token that replaced it
+	if _, _, err := RotateRefreshToken(db, verifier, first.RefreshToken, ""); err != queries.ErrRefreshTokenReused {
+		t.Fatalf("replayed token: got %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := RotateRefreshToken(db, verifier, second.RefreshToken, ""); err != queries.ErrRefreshTokenReused {
+		t.Fatalf("token of the revoked family: got %v, want ErrRefreshTokenReused", err)
+	}
+
+	// Other sessions of the user are unaffected
+	other, err := IssueTokenPair(db, keys, user, "", models.TokenOptions{})
+	if err != nil {
+		t.Fatalf("IssueTokenPair: %v", err)
+	}
+	if _, _, err := RotateRefreshToken(db, verifier, other.RefreshToken, ""); err != nil {
+		t.Fatalf("other session: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"fmt"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/mailer"
+	"log"
+	"net/url"
+)
+
+// EmailVerification configures the verification emails sent to new users
+type EmailVerification struct {
+	Mailer   mailer.Mailer
+	URL      string // Frontend page that takes the token; when empty the email contains the raw token
+	Required bool   // Unverified users can't sign in
+}
+
+// SendVerificationEmail emails the user a signed link to confirm their email address.
+// It is best effort: failures are logged, never reported to the caller.
+func SendVerificationEmail(keys *jwt.KeySet, verification *EmailVerification, user *models.User) {
+	token, err := jwt.SignClaims(jwt.NewEmailVerificationClaims(user), keys)
+	if err != nil {
+		log.Printf("Failed to sign email verification token for user %s: %v", user.ID, err)
+		return
+	}
+
+	var instructions string
+	if link, err := url.Parse(verification.URL); err == nil && verification.URL != "" {
+		query := link.Query()
+		query.Set("token", token)
+		link.RawQuery = query.Encode()
+		instructions = "Confirm your email address here:\n\n" + link.String()
+	} else {
+		instructions = "Use this token to confirm your email address:\n\n" + token
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that this is your email address.\n\n%s\n\n"+
+			"This expires in %d hours. If you didn't expect this email, you can ignore it.\n",
+			user.Username, instructions, int(models.EmailVerificationTokenDuration.Hours())),
+	}
+	if err := verification.Mailer.Send(msg); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+}
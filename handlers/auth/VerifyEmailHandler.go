@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"net/http"
+)
+
+// VerifyEmailHandler confirms a user's email address with the token from their verification
+// email. Using a token again is harmless; tokens sent to a previous email are rejected.
+func VerifyEmailHandler(database *sql.DB, verifier *auth.TokenVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		var req models.VerifyEmailRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Token == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "token is required"})
+			return
+		}
+
+		claims, err := verifier.Verify(req.Token, models.EmailVerificationToken)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired verification token"})
+			return
+		}
+
+		if err := queries.VerifyEmail(database, claims.UserID, claims.Email); err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired verification token"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify email"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "email verified successfully"})
+	}
+}
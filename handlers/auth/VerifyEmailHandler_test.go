@@ -0,0 +1,99 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// receiveToken waits for the next email and returns the raw verification token it contains
+func receiveToken(t *testing.T, mail channelMailer, to string) string {
+	t.Helper()
+	select {
+	case msg := <-mail:
+		if msg.To != to {
+			t.Fatalf("email sent to %q, want %q", msg.To, to)
+		}
+		token := regexp.MustCompile(`:\n\n(\S+)`).FindStringSubmatch(msg.Body)
+		if token == nil {
+			t.Fatalf("no token in %q", msg.Body)
+		}
+		return token[1]
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no verification email sent to %s", to)
+	}
+	return ""
+}
+
+func TestEmailVerification(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	mail := make(channelMailer, 1)
+	verification := &EmailVerification{Mailer: mail}
+	verify := VerifyEmailHandler(db, auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist()))
+	resend := ResendVerificationHandler(db, keys, verification)
+
+	verifyToken := func(token string) (int, string) {
+		t.Helper()
+		status, resp := postJSON(t, verify, `{"token":"`+token+`"}`)
+		message, _ := resp["error"].(string)
+		return status, message
+	}
+
+	// A token sent to an address the user has since changed is rejected
+	SendVerificationEmail(keys, verification, user)
+	stale := receiveToken(t, mail, user.Email)
+	user, err := queries.UpdateUser(db, user.ID, user.Username, "changed_"+user.Email)
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if status, message := verifyToken(stale); status != http.StatusBadRequest {
+		t.Fatalf("token for the old email: got %d %q, want 400", status, message)
+	}
+
+	if status, _ := postJSON(t, resend, `{"email":"`+user.Email+`"}`); status != http.StatusAccepted {
+		t.Fatalf("resend: status = %d, want 202", status)
+	}
+	token := receiveToken(t, mail, user.Email)
+
+	// Other kinds of tokens don't verify anything
+	access, err := jwt.SignClaims(jwt.NewClaims(user, models.AccessToken), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	for name, bad := range map[string]string{"access token": access, "garbage": "not-a-token"} {
+		if status, _ := verifyToken(bad); status != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, status)
+		}
+	}
+
+	// Using the link twice is harmless
+	for i := 0; i < 2; i++ {
+		if status, message := verifyToken(token); status != http.StatusOK {
+			t.Fatalf("verify %d: got %d %q", i+1, status, message)
+		}
+	}
+	verified, err := queries.GetUserByID(db, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if !verified.EmailVerified() {
+		t.Fatal("email not verified")
+	}
+
+	// Verified accounts get no more emails
+	postJSON(t, resend, `{"email":"`+user.Email+`"}`)
+	select {
+	case msg := <-mail:
+		t.Fatalf("resend for a verified account emailed %s", msg.To)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/password"
+	"log"
+)
+
+// VerifyLoginPassword checks the password a user signs in with. Hashes made with an outdated
+// algorithm or parameters are upgraded while the password is known.
+func VerifyLoginPassword(database *sql.DB, user *models.User, plainPassword string) bool {
+	if !password.VerifyPassword(user.Password, plainPassword) {
+		return false
+	}
+	if password.NeedsRehash(user.Password) {
+		rehashPassword(database, user.ID, plainPassword)
+	}
+	return true
+}
+
+// rehashPassword stores a fresh hash of the password. It is best effort: a failure
+// leaves the old hash in place and the login still succeeds. The token version is
+// not bumped, since the password itself has not changed.
+func rehashPassword(database *sql.DB, userID, plainPassword string) {
+	hashedPassword, err := password.HashPassword(plainPassword)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", userID, err)
+		return
+	}
+	if err := queries.UpdatePassword(database, userID, hashedPassword); err != nil {
+		log.Printf("Failed to save rehashed password for user %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/utils/password"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifyLoginPasswordRehashes(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	legacy, err := password.NewBcryptHasher(bcrypt.MinCost).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if err := queries.UpdatePassword(db, user.ID, legacy); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+	user.Password = legacy
+
+	// A wrong password leaves the old hash alone
+	if VerifyLoginPassword(db, user, "wrong horse") {
+		t.Fatal("wrong password accepted")
+	}
+	stored, err := queries.GetUserByID(db, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if stored.Password != legacy {
+		t.Error("hash replaced after a wrong password")
+	}
+
+	// The right one upgrades it to the current algorithm
+	if !VerifyLoginPassword(db, user, "correct horse") {
+		t.Fatal("correct password rejected")
+	}
+	stored, err = queries.GetUserByID(db, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if stored.Password == legacy || password.NeedsRehash(stored.Password) || !password.VerifyPassword(stored.Password, "correct horse") {
+		t.Errorf("got hash %q, want a current hash of the password", stored.Password)
+	}
+}
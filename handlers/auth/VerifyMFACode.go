@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/totp"
+	"strings"
+	"time"
+)
+
+// VerifyMFACode checks a code from the user's authenticator app or one of their recovery
+// codes. Either kind is used up: a TOTP code can't be replayed and a recovery code is
+// marked as used. It returns the amr value of the method that accepted the code, or ""
+// when the code is invalid. The error is only set when the check itself failed.
+func VerifyMFACode(database *sql.DB, mfa *models.UserMFA, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", nil
+	}
+
+	// Six digits is a TOTP code; anything else can only be a recovery code
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		step, ok := totp.Validate(mfa.TOTPSecret, code, time.Now())
+		if !ok || step <= mfa.LastUsedStep {
+			return "", nil
+		}
+		if err := queries.UseTOTPStep(database, mfa.UserID, step); err != nil {
+			if err == queries.ErrTOTPCodeReused {
+				return "", nil
+			}
+			return "", err
+		}
+		return models.AMROTP, nil
+	}
+
+	if err := queries.UseRecoveryCode(database, mfa.UserID, hashRecoveryCode(code)); err != nil {
+		if err == queries.ErrRecoveryCodeNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return models.AMRRecoveryCode, nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/totp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyMFACode(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	secret := totp.GenerateSecret()
+	if err := queries.CreateTOTPEnrollment(db, user.ID, secret); err != nil {
+		t.Fatalf("CreateTOTPEnrollment: %v", err)
+	}
+	// Enrolled a while ago, so the current code hasn't been used yet
+	current := time.Now().Unix() / int64(totp.Period/time.Second)
+	codes, hashes := GenerateRecoveryCodes()
+	if err := queries.ConfirmTOTPEnrollment(db, user.ID, current-totp.Skew-1, hashes); err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment: %v", err)
+	}
+	code, err := totp.GenerateCode(secret, current)
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+
+	steps := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"totp code", code, models.AMROTP},
+		{"replayed totp code", code, ""},
+		{"recovery code", codes[0], models.AMRRecoveryCode},
+		{"used recovery code", codes[0], ""},
+		{"recovery code in lower case", " " + strings.ToLower(codes[1]) + " ", models.AMRRecoveryCode},
+		{"unknown recovery code", "22222-22222", ""},
+		{"empty", "", ""},
+	}
+	for _, step := range steps {
+		mfa, err := queries.GetUserMFA(db, user.ID)
+		if err != nil {
+			t.Fatalf("GetUserMFA: %v", err)
+		}
+		method, err := VerifyMFACode(db, mfa, step.code)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if method != step.want {
+			t.Fatalf("%s: got method %q, want %q", step.name, method, step.want)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"go-auth/models"
 	"net/http"
+	"strings"
 )
 
 // RespondJSON writes a JSON response
@@ -12,6 +14,20 @@ func RespondJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// RespondValidationError writes a 400 response listing every rejected field
+func RespondValidationError(w http.ResponseWriter, fields []models.FieldError) {
+	RespondJSON(w, http.StatusBadRequest, models.ValidationErrorResponse{
+		Error:  "validation failed",
+		Fields: fields,
+	})
+}
+
+// IsValidScope rejects empty scopes and characters RFC 6749 does not allow in a scope token.
+// Scopes are space-delimited in requests, so they cannot contain whitespace.
+func IsValidScope(scope string) bool {
+	return scope != "" && !strings.ContainsAny(scope, " \t\r\n\"\\")
+}
+
 // HealthCheckHandler is a simple endpoint to check if the service is running
 func HealthCheckHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
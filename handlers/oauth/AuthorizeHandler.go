@@ -0,0 +1,211 @@
+package oauth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// authorizeTemplate is the login and consent page shown to the resource owner
+var authorizeTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sign in to {{.ClientName}}</title>
+</head>
+<body>
+<h1>Sign in to continue to {{.ClientName}}</h1>
+{{if .Scopes}}<p>{{.ClientName}} is requesting access to:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="POST" action="/oauth/authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<label>Email <input type="email" name="email" value="{{.Email}}" required></label>
+<label>Password <input type="password" name="password" required></label>
+<label>Authentication code (if enabled) <input type="text" name="mfa_code" inputmode="numeric" autocomplete="one-time-code"></label>
+<button type="submit" name="decision" value="allow">Allow</button>
+<button type="submit" name="decision" value="deny" formnovalidate>Deny</button>
+</form>
+</body>
+</html>`))
+
+// authorizePage is the data rendered into authorizeTemplate
+type authorizePage struct {
+	ClientName string
+	Scopes     []string
+	Params     map[string]string
+	Email      string
+	Error      string
+}
+
+// AuthorizeHandler implements the OAuth 2.0 authorization endpoint (authorization code + PKCE).
+// GET renders a login and consent form; POST authenticates the user and redirects back
+// to the client with a single-use authorization code.
+func AuthorizeHandler(database *sql.DB, opts *authhandler.LoginOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			respondOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+			return
+		}
+
+		// Never allow the login form to be framed or cached
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+
+		if err := r.ParseForm(); err != nil {
+			respondOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request")
+			return
+		}
+
+		// The client and redirect URI must be validated before anything is sent back to
+		// the redirect URI, otherwise this endpoint becomes an open redirector
+		client, err := queries.GetOAuthClient(database, r.Form.Get("client_id"))
+		if err != nil {
+			if err == queries.ErrOAuthClientNotFound {
+				respondOAuthError(w, http.StatusBadRequest, "invalid_client", "unknown client")
+				return
+			}
+			respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to get client")
+			return
+		}
+
+		redirectURI, ok := resolveRedirectURI(client, r.Form.Get("redirect_uri"))
+		if !ok {
+			respondOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid redirect_uri")
+			return
+		}
+
+		state := r.Form.Get("state")
+
+		// From here on errors are reported to the client through the redirect URI
+		if r.Form.Get("response_type") != "code" {
+			redirectWithError(w, r, redirectURI, state, "unsupported_response_type", "response_type must be code")
+			return
+		}
+
+		scope, ok := resolveScope(client.AllowedScopes, r.Form.Get("scope"))
+		if !ok {
+			redirectWithError(w, r, redirectURI, state, "invalid_scope", "requested scope is not allowed for this client")
+			return
+		}
+
+		// PKCE is required for every client
+		codeChallenge := r.Form.Get("code_challenge")
+		if codeChallenge == "" {
+			redirectWithError(w, r, redirectURI, state, "invalid_request", "code_challenge is required")
+			return
+		}
+		if r.Form.Get("code_challenge_method") != "S256" {
+			redirectWithError(w, r, redirectURI, state, "invalid_request", "code_challenge_method must be S256")
+			return
+		}
+
+		// There is no browser session to reuse, so the user always has to sign in
+		if hasScope(r.Form.Get("prompt"), "none") {
+			redirectWithError(w, r, redirectURI, state, "login_required", "the user must sign in")
+			return
+		}
+
+		page := authorizePage{
+			ClientName: client.Name,
+			Scopes:     strings.Fields(scope),
+			Params: map[string]string{
+				"client_id":             client.ClientID,
+				"redirect_uri":          r.Form.Get("redirect_uri"), // Empty when the client relies on its only registered URI
+				"response_type":         "code",
+				"scope":                 scope,
+				"state":                 state,
+				"code_challenge":        codeChallenge,
+				"code_challenge_method": "S256",
+				"nonce":                 r.Form.Get("nonce"),
+			},
+		}
+
+		if r.Method == http.MethodGet {
+			renderAuthorizePage(w, http.StatusOK, page)
+			return
+		}
+
+		if r.PostForm.Get("decision") != "allow" {
+			redirectWithError(w, r, redirectURI, state, "access_denied", "the user denied the request")
+			return
+		}
+
+		// Authenticate the resource owner
+		user, amr, message, status := authenticateUser(database, opts, r.PostForm)
+		if user == nil {
+			page.Email = r.PostForm.Get("email")
+			page.Error = message
+			renderAuthorizePage(w, status, page)
+			return
+		}
+
+		// Issue a single-use authorization code; only its hash is stored
+		code := secure.RandomToken(32)
+		err = queries.CreateAuthorizationCode(database, secure.HashToken(code), &models.AuthorizationCode{
+			ClientID:            client.ClientID,
+			UserID:              user.ID,
+			RedirectURI:         redirectURI,
+			RedirectURISupplied: r.Form.Get("redirect_uri") != "",
+			Scope:               scope,
+			CodeChallenge:       codeChallenge,
+			CodeChallengeMethod: "S256",
+			Nonce:               r.PostForm.Get("nonce"),
+			AuthTime:            time.Now(),
+			AMR:                 amr,
+			ExpiresAt:           time.Now().Add(models.AuthorizationCodeDuration),
+		})
+		if err != nil {
+			redirectWithError(w, r, redirectURI, state, "server_error", "failed to issue authorization code")
+			return
+		}
+
+		redirectWithParams(w, r, redirectURI, url.Values{"code": {code}, "state": {state}})
+	}
+}
+
+// renderAuthorizePage writes the login and consent form
+func renderAuthorizePage(w http.ResponseWriter, status int, page authorizePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	authorizeTemplate.Execute(w, page)
+}
+
+// redirectWithError sends an RFC 6749 error response to the client's redirect URI
+func redirectWithError(w http.ResponseWriter, r *http.Request, redirectURI, state, code, description string) {
+	redirectWithParams(w, r, redirectURI, url.Values{
+		"error":             {code},
+		"error_description": {description},
+		"state":             {state},
+	})
+}
+
+// redirectWithParams redirects to the (already validated) redirect URI with params added
+// to its query string. Empty values are omitted.
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		respondOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid redirect_uri")
+		return
+	}
+
+	query := target.Query()
+	for name, values := range params {
+		for _, value := range values {
+			if value != "" {
+				query.Add(name, value)
+			}
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
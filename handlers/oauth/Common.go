@@ -0,0 +1,280 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	errInactive      = errors.New("token is not active")
+	errLookupFailed  = errors.New("token lookup failed")
+	errInvalidClient = errors.New("invalid client")
+)
+
+// authenticateStaticClient checks HTTP Basic client credentials against a
+// client_id -> secret map in constant time
+func authenticateStaticClient(r *http.Request, clients map[string]string) (string, bool) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostFormValue("client_id")
+		clientSecret = r.PostFormValue("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		return "", false
+	}
+
+	expected, exists := clients[clientID]
+	if !exists {
+		return "", false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(clientSecret), []byte(expected)) != 1 {
+		return "", false
+	}
+	return clientID, true
+}
+
+// authenticateClient identifies a registered OAuth client from HTTP Basic or form credentials.
+// Confidential clients must present their secret; public clients only send client_id.
+func authenticateClient(database *sql.DB, r *http.Request) (*models.OAuthClient, error) {
+	clientID, clientSecret := clientCredentials(r)
+	if clientID == "" {
+		return nil, errInvalidClient
+	}
+
+	client, err := queries.GetOAuthClient(database, clientID)
+	if err != nil {
+		if err == queries.ErrOAuthClientNotFound {
+			return nil, errInvalidClient
+		}
+		return nil, errLookupFailed
+	}
+
+	if client.IsPublic() {
+		if clientSecret != "" {
+			return nil, errInvalidClient
+		}
+		return client, nil
+	}
+
+	presented := secure.HashToken(clientSecret)
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(client.SecretHash)) != 1 {
+		return nil, errInvalidClient
+	}
+	return client, nil
+}
+
+// authenticateServiceAccount identifies an enabled service account from HTTP Basic or form credentials
+func authenticateServiceAccount(database *sql.DB, r *http.Request) (*models.ServiceAccount, error) {
+	clientID, clientSecret := clientCredentials(r)
+	if clientID == "" || clientSecret == "" {
+		return nil, errInvalidClient
+	}
+
+	account, err := queries.GetServiceAccountByClientID(database, clientID)
+	if err != nil {
+		if err == queries.ErrServiceAccountNotFound {
+			return nil, errInvalidClient
+		}
+		return nil, errLookupFailed
+	}
+
+	presented := secure.HashToken(clientSecret)
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(account.SecretHash)) != 1 || account.IsDisabled() {
+		return nil, errInvalidClient
+	}
+	return account, nil
+}
+
+// clientCredentials reads client credentials from HTTP Basic auth or the form body
+func clientCredentials(r *http.Request) (string, string) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		// RFC 6749 section 2.3.1: Basic credentials are form-urlencoded
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+		return clientID, clientSecret
+	}
+	return r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+}
+
+// resolveRedirectURI returns the redirect URI to use for the client. It must exactly match
+// a registered URI; it may be omitted only when the client has a single registered URI.
+func resolveRedirectURI(client *models.OAuthClient, requested string) (string, bool) {
+	if requested == "" {
+		if len(client.RedirectURIs) == 1 {
+			return client.RedirectURIs[0], true
+		}
+		return "", false
+	}
+
+	for _, uri := range client.RedirectURIs {
+		if uri == requested {
+			return uri, true
+		}
+	}
+	return "", false
+}
+
+// resolveScope checks that every requested scope is in allowedScopes and
+// returns the normalized space-delimited scope string
+func resolveScope(allowedScopes []string, requested string) (string, bool) {
+	allowed := make(map[string]bool, len(allowedScopes))
+	for _, scope := range allowedScopes {
+		allowed[scope] = true
+	}
+
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, scope := range strings.Fields(requested) {
+		if !allowed[scope] {
+			return "", false
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return strings.Join(scopes, " "), true
+}
+
+// hasScope reports whether the space-delimited scope string contains scope
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCodeChallenge checks a PKCE code_verifier against the stored S256 challenge (RFC 7636)
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, c := range verifier {
+		if !isUnreservedChar(c) {
+			return false
+		}
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// isUnreservedChar reports whether c is allowed in a PKCE code verifier
+func isUnreservedChar(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// respondOAuthError writes an RFC 6749 error response
+func respondOAuthError(w http.ResponseWriter, status int, code, description string) {
+	handlers.RespondJSON(w, status, models.OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+// authenticateUser checks the email, password and, for users with MFA enabled, the
+// authentication code posted to an HTML login page, applying the same account checks as
+// /login: lockout rules, breach screening, required email verification and forced password
+// changes. It returns the user and how they authenticated (amr), or a message to show on the
+// page and the status to render it with.
+func authenticateUser(database *sql.DB, opts *authhandler.LoginOptions, form url.Values) (*models.User, []string, string, int) {
+	user, err := queries.GetUserByEmail(database, form.Get("email"))
+	if err != nil {
+		if err == queries.ErrUserNotFound {
+			return nil, nil, "Invalid email or password.", http.StatusUnauthorized
+		}
+		return nil, nil, "Something went wrong, please try again.", http.StatusInternalServerError
+	}
+
+	if code, wait := authhandler.CheckLoginThrottle(user, opts.Lockout); code != "" {
+		return nil, nil, throttledMessage(code, wait), throttledStatus(code)
+	}
+
+	if !authhandler.VerifyLoginPassword(database, user, form.Get("password")) {
+		authhandler.RecordLoginAttempt(database, user, opts.Lockout, false)
+		return nil, nil, "Invalid email or password.", http.StatusUnauthorized
+	}
+
+	// Flag accounts whose password has since shown up in a breach
+	if opts.Breaches != nil && !user.MustChangePassword {
+		authhandler.FlagBreachedPassword(database, opts.Breaches, user, form.Get("password"))
+	}
+
+	mfa, err := queries.GetUserMFA(database, user.ID)
+	if err != nil && err != queries.ErrMFANotFound {
+		return nil, nil, "Something went wrong, please try again.", http.StatusInternalServerError
+	}
+	amr := models.PasswordAMR
+	if mfa != nil && mfa.Enabled() {
+		if form.Get("mfa_code") == "" {
+			return nil, nil, "Enter the code from your authenticator app.", http.StatusUnauthorized
+		}
+		method, err := authhandler.VerifyMFACode(database, mfa, form.Get("mfa_code"))
+		if err != nil {
+			return nil, nil, "Something went wrong, please try again.", http.StatusInternalServerError
+		}
+		if method == "" {
+			authhandler.RecordLoginAttempt(database, user, opts.Lockout, false)
+			return nil, nil, "Invalid authentication code.", http.StatusUnauthorized
+		}
+		amr = models.SecondFactorAMR(method)
+	}
+
+	authhandler.RecordLoginAttempt(database, user, opts.Lockout, true)
+
+	if opts.RequireVerifiedEmail && !user.EmailVerified() {
+		return nil, nil, "Please verify your email address before signing in. Check your inbox for the verification link.", http.StatusForbidden
+	}
+
+	// Users who must change their password only get a restricted token from /login, never OAuth tokens
+	if reason := authhandler.PasswordChangeReason(user, opts.PasswordMaxAge); reason != "" {
+		return nil, nil, passwordChangeMessage(reason), http.StatusForbidden
+	}
+
+	return user, amr, "", http.StatusOK
+}
+
+// passwordChangeMessage explains on the HTML login pages why the user has to change their password first
+func passwordChangeMessage(reason string) string {
+	if reason == models.PasswordChangeReasonExpired {
+		return "Your password has expired. Sign in to your account to choose a new one, then try again."
+	}
+	return "You have to change your password before you can continue. Sign in to your account to choose a new one, then try again."
+}
+
+// throttledStatus maps a login throttling code to its HTTP status for the HTML login pages
+func throttledStatus(code string) int {
+	if code == models.LoginErrorAccountLocked {
+		return http.StatusLocked
+	}
+	return http.StatusTooManyRequests
+}
+
+// throttledMessage explains a refused login attempt on the HTML login pages
+func throttledMessage(code string, wait time.Duration) string {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if code == models.LoginErrorAccountLocked {
+		return fmt.Sprintf("This account is temporarily locked after too many failed sign-in attempts. Try again in %d seconds.", seconds)
+	}
+	return fmt.Sprintf("Too many failed sign-in attempts. Try again in %d seconds.", seconds)
+}
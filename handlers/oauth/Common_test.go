@@ -0,0 +1,97 @@
+package oauth
+
+import (
+	"go-auth/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if !verifyCodeChallenge(verifier, challenge) {
+		t.Fatal("RFC 7636 example verifier rejected")
+	}
+
+	tests := map[string]string{
+		"wrong verifier":   strings.Replace(verifier, "d", "e", 1),
+		"too short":        verifier[:42],
+		"too long":         strings.Repeat("a", 129),
+		"reserved char":    verifier[:42] + "+",
+		"plain challenge":  challenge,
+		"empty verifier":   "",
+		"non-ascii letter": verifier[:42] + "é",
+	}
+	for name, v := range tests {
+		if verifyCodeChallenge(v, challenge) {
+			t.Errorf("%s: verifier %q accepted", name, v)
+		}
+	}
+}
+
+func TestResolveRedirectURI(t *testing.T) {
+	single := &models.OAuthClient{RedirectURIs: []string{"https://app.example.com/callback"}}
+	multiple := &models.OAuthClient{RedirectURIs: []string{"https://app.example.com/callback", "http://localhost:3000/callback"}}
+
+	tests := []struct {
+		name      string
+		client    *models.OAuthClient
+		requested string
+		want      string
+		ok        bool
+	}{
+		{"single omitted", single, "", "https://app.example.com/callback", true},
+		{"single exact", single, "https://app.example.com/callback", "https://app.example.com/callback", true},
+		{"multiple omitted", multiple, "", "", false},
+		{"multiple exact", multiple, "http://localhost:3000/callback", "http://localhost:3000/callback", true},
+		{"trailing slash", single, "https://app.example.com/callback/", "", false},
+		{"extra query", single, "https://app.example.com/callback?next=/", "", false},
+		{"other host", single, "https://evil.example.com/callback", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := resolveRedirectURI(tt.client, tt.requested)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: got %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResolveScope(t *testing.T) {
+	allowed := []string{"openid", "profile", "read"}
+
+	tests := []struct {
+		requested string
+		want      string
+		ok        bool
+	}{
+		{"", "", true},
+		{"openid read", "openid read", true},
+		{"read  openid read", "read openid", true},
+		{"openid write", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := resolveScope(allowed, tt.requested)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("resolveScope(%q) = %q, %v; want %q, %v", tt.requested, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	// Basic credentials are form-urlencoded (RFC 6749 section 2.3.1)
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", nil)
+	req.SetBasicAuth("my%3Aclient", "s%2Bcret")
+	if id, secret := clientCredentials(req); id != "my:client" || secret != "s+cret" {
+		t.Errorf("basic: got %q, %q", id, secret)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader("client_id=app&client_secret=secret"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if id, secret := clientCredentials(req); id != "app" || secret != "secret" {
+		t.Errorf("form: got %q, %q", id, secret)
+	}
+}
@@ -0,0 +1,107 @@
+package oauth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/secure"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// userCodeAlphabet avoids vowels (no accidental words) and look-alike characters
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// userCodeAttempts is how many random user codes are tried before giving up on a collision
+const userCodeAttempts = 3
+
+// DeviceAuthorizationHandler implements the RFC 8628 device authorization endpoint.
+// It returns a device code for the client to poll /oauth/token with and a user code
+// for the user to enter at the verification page, which lives under the configured issuer URL.
+func DeviceAuthorizationHandler(database *sql.DB, keys *jwt.KeySet) http.HandlerFunc {
+	verificationURI := strings.TrimSuffix(keys.Issuer, "/") + "/oauth/device"
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			respondOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		if err := r.ParseForm(); err != nil {
+			respondOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+			return
+		}
+
+		// Authenticate the client
+		client, err := authenticateClient(database, r)
+		if err != nil {
+			if err == errLookupFailed {
+				respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to authenticate client")
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			respondOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+			return
+		}
+
+		scope, ok := resolveScope(client.AllowedScopes, r.PostForm.Get("scope"))
+		if !ok {
+			respondOAuthError(w, http.StatusBadRequest, "invalid_scope", "requested scope is not allowed for this client")
+			return
+		}
+
+		// Only the device code hash is stored; the user code is short-lived and single-use
+		deviceCode := secure.RandomToken(32)
+		code := &models.DeviceCode{
+			ClientID:  client.ClientID,
+			Scope:     scope,
+			Interval:  int(models.DeviceCodePollInterval.Seconds()),
+			ExpiresAt: time.Now().Add(models.DeviceCodeDuration),
+		}
+		for attempt := 1; ; attempt++ {
+			code.UserCode = secure.RandomCode(userCodeAlphabet, 8)
+			err = queries.CreateDeviceCode(database, secure.HashToken(deviceCode), code)
+			if err != queries.ErrUserCodeTaken || attempt == userCodeAttempts {
+				break
+			}
+		}
+		if err != nil {
+			respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to create device code")
+			return
+		}
+
+		userCode := formatUserCode(code.UserCode)
+
+		handlers.RespondJSON(w, http.StatusOK, models.DeviceAuthorizationResponse{
+			DeviceCode:              deviceCode,
+			UserCode:                userCode,
+			VerificationURI:         verificationURI,
+			VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(userCode),
+			ExpiresIn:               int(models.DeviceCodeDuration.Seconds()),
+			Interval:                code.Interval,
+		})
+	}
+}
+
+// formatUserCode splits a user code into two halves for readability, e.g. WDJB-MJHT
+func formatUserCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// normalizeUserCode undoes formatting and case changes the user may have made while typing
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(c rune) rune {
+		if c == '-' || c == ' ' {
+			return -1
+		}
+		return c
+	}, code)
+}
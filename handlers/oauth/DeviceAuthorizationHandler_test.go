@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"encoding/json"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserCodeFormatting(t *testing.T) {
+	if got := formatUserCode("WDJBMJHT"); got != "WDJB-MJHT" {
+		t.Errorf("formatUserCode = %q, want WDJB-MJHT", got)
+	}
+
+	for _, typed := range []string{"WDJB-MJHT", "wdjb-mjht", "WDJBMJHT", " wdjb mjht ", "Wd-Jb-Mj-Ht"} {
+		if got := normalizeUserCode(typed); got != "WDJBMJHT" {
+			t.Errorf("normalizeUserCode(%q) = %q, want WDJBMJHT", typed, got)
+		}
+	}
+}
+
+func TestUserCodeAlphabet(t *testing.T) {
+	// No vowels, so codes never spell words, and no digits to confuse with letters
+	if strings.ContainsAny(userCodeAlphabet, "AEIOUY0123456789") {
+		t.Errorf("userCodeAlphabet %q contains vowels or digits", userCodeAlphabet)
+	}
+}
+
+func TestDeviceVerificationURIUsesIssuer(t *testing.T) {
+	db := dbtest.Open(t)
+	client := dbtest.CreateOAuthClient(t, db, "https://app.example.com/callback")
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	keys.Issuer = "https://auth.example.com/"
+	handler := DeviceAuthorizationHandler(db, keys)
+
+	// The Host header is the client's to choose, so it must never end up in the link users follow
+	form := url.Values{"client_id": {client.ClientID}, "scope": {"read"}}
+	req := httptest.NewRequest(http.MethodPost, "/oauth/device_authorization", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Host = "attacker.example.net"
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	var response models.DeviceAuthorizationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if response.VerificationURI != "https://auth.example.com/oauth/device" {
+		t.Errorf("verification_uri = %q", response.VerificationURI)
+	}
+	if !strings.HasPrefix(response.VerificationURIComplete, "https://auth.example.com/oauth/device?user_code=") {
+		t.Errorf("verification_uri_complete = %q", response.VerificationURIComplete)
+	}
+}
@@ -0,0 +1,133 @@
+package oauth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	authhandler "go-auth/handlers/auth"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// deviceTemplate is the verification page where the user enters the code shown by the device
+var deviceTemplate = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Connect a device</title>
+</head>
+<body>
+<h1>Connect a device</h1>
+{{if .Message}}<p role="status">{{.Message}}</p>{{else}}
+{{if .ClientName}}<p>{{.ClientName}} is requesting access{{if .Scopes}} to:{{end}}</p>
+{{if .Scopes}}<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}{{end}}
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="POST" action="/oauth/device">
+<label>Code <input type="text" name="user_code" value="{{.UserCode}}" autocomplete="off" required></label>
+<label>Email <input type="email" name="email" value="{{.Email}}" required></label>
+<label>Password <input type="password" name="password" required></label>
+<label>Authentication code (if enabled) <input type="text" name="mfa_code" inputmode="numeric" autocomplete="one-time-code"></label>
+<button type="submit" name="decision" value="allow">Allow</button>
+<button type="submit" name="decision" value="deny">Deny</button>
+</form>{{end}}
+</body>
+</html>`))
+
+// devicePage is the data rendered into deviceTemplate
+type devicePage struct {
+	UserCode   string
+	ClientName string
+	Scopes     []string
+	Email      string
+	Error      string
+	Message    string
+}
+
+// DeviceVerificationHandler is the RFC 8628 verification page. GET shows the code form
+// (and the requesting client when the code is prefilled); POST signs the user in and
+// approves or denies the device.
+func DeviceVerificationHandler(database *sql.DB, opts *authhandler.LoginOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			respondOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+			return
+		}
+
+		// Never allow the login form to be framed or cached
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+
+		if err := r.ParseForm(); err != nil {
+			renderDevicePage(w, http.StatusBadRequest, devicePage{Error: "Invalid request."})
+			return
+		}
+
+		page := devicePage{UserCode: r.Form.Get("user_code")}
+		userCode := normalizeUserCode(page.UserCode)
+
+		// Show who is asking so the user can spot a code they did not start
+		if userCode != "" {
+			code, err := queries.GetPendingDeviceCode(database, userCode)
+			if err != nil {
+				if err != queries.ErrDeviceCodeNotFound {
+					page.Error = "Something went wrong, please try again."
+					renderDevicePage(w, http.StatusInternalServerError, page)
+					return
+				}
+				page.Error = "This code is invalid or has expired."
+				renderDevicePage(w, http.StatusBadRequest, page)
+				return
+			}
+
+			client, err := queries.GetOAuthClient(database, code.ClientID)
+			if err != nil {
+				page.Error = "Something went wrong, please try again."
+				renderDevicePage(w, http.StatusInternalServerError, page)
+				return
+			}
+			page.ClientName = client.Name
+			page.Scopes = strings.Fields(code.Scope)
+		}
+
+		if r.Method == http.MethodGet {
+			renderDevicePage(w, http.StatusOK, page)
+			return
+		}
+
+		// Authenticate the user before recording any decision
+		page.Email = r.PostForm.Get("email")
+		user, amr, message, status := authenticateUser(database, opts, r.PostForm)
+		if user == nil {
+			page.Error = message
+			renderDevicePage(w, status, page)
+			return
+		}
+
+		approved := r.PostForm.Get("decision") == "allow"
+		if err := queries.DecideDeviceCode(database, userCode, user.ID, approved, amr); err != nil {
+			if err == queries.ErrDeviceCodeNotFound {
+				page.Error = "This code is invalid or has expired."
+				renderDevicePage(w, http.StatusBadRequest, page)
+				return
+			}
+			page.Error = "Something went wrong, please try again."
+			renderDevicePage(w, http.StatusInternalServerError, page)
+			return
+		}
+
+		if approved {
+			page.Message = "Device connected. You can return to your device."
+		} else {
+			page.Message = "Request denied. The device will not get access."
+		}
+		renderDevicePage(w, http.StatusOK, page)
+	}
+}
+
+// renderDevicePage writes the device verification page
+func renderDevicePage(w http.ResponseWriter, status int, page devicePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	deviceTemplate.Execute(w, page)
+}
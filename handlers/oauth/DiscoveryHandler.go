@@ -0,0 +1,45 @@
+package oauth
+
+import (
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"net/http"
+	"strings"
+)
+
+// DiscoveryHandler publishes the OpenID Connect discovery document.
+// Every endpoint is advertised relative to the configured issuer URL.
+func DiscoveryHandler(keys *jwt.KeySet) http.HandlerFunc {
+	issuer := strings.TrimSuffix(keys.Issuer, "/")
+
+	configuration := models.OpenIDConfiguration{
+		Issuer:                            keys.Issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint:             issuer + "/introspect",
+		DeviceAuthorizationEndpoint:       issuer + "/oauth/device_authorization",
+		ScopesSupported:                   []string{"openid", "email", "profile"},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials", deviceCodeGrantType},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{keys.Active.Method.Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "preferred_username", "amr", "acr"},
+		ACRValuesSupported:                []string{models.ACRSingleFactor, models.ACRMultiFactor},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+
+		handlers.RespondJSON(w, http.StatusOK, configuration)
+	}
+}
@@ -0,0 +1,55 @@
+package oauth
+
+import (
+	"encoding/json"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiscoveryHandler(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	keys.Issuer = "https://auth.example.com/"
+	handler := DiscoveryHandler(keys)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+
+	var configuration models.OpenIDConfiguration
+	if err := json.Unmarshal(rec.Body.Bytes(), &configuration); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if configuration.Issuer != keys.Issuer {
+		t.Errorf("issuer = %q, want %q", configuration.Issuer, keys.Issuer)
+	}
+	endpoints := map[string]string{
+		configuration.AuthorizationEndpoint: "https://auth.example.com/oauth/authorize",
+		configuration.TokenEndpoint:         "https://auth.example.com/oauth/token",
+		configuration.UserInfoEndpoint:      "https://auth.example.com/userinfo",
+		configuration.JWKSURI:               "https://auth.example.com/.well-known/jwks.json",
+	}
+	for got, want := range endpoints {
+		if got != want {
+			t.Errorf("got endpoint %q, want %q", got, want)
+		}
+	}
+	if !slices.Equal(configuration.IDTokenSigningAlgValuesSupported, []string{"HS256"}) {
+		t.Errorf("id_token_signing_alg_values_supported = %v, want [HS256]", configuration.IDTokenSigningAlgValuesSupported)
+	}
+	if !slices.Equal(configuration.CodeChallengeMethodsSupported, []string{"S256"}) {
+		t.Errorf("code_challenge_methods_supported = %v, want [S256]", configuration.CodeChallengeMethodsSupported)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/.well-known/openid-configuration", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got status %d, want 405", rec.Code)
+	}
+}
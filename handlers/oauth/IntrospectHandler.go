@@ -0,0 +1,137 @@
+package oauth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"go-auth/utils/secure"
+	"net/http"
+)
+
+// IntrospectHandler implements RFC 7662 token introspection for downstream services.
+// Callers authenticate with client credentials from INTROSPECTION_CLIENTS.
+func IntrospectHandler(database *sql.DB, verifier *auth.TokenVerifier, clients map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Authenticate the calling service
+		if _, ok := authenticateStaticClient(r, clients); !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		token := r.PostFormValue("token")
+		if token == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		claims, err := introspect(database, verifier, token)
+		if err != nil {
+			if err == errLookupFailed {
+				handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusOK, models.IntrospectionResponse{Active: false})
+			return
+		}
+
+		response := models.IntrospectionResponse{
+			Active:    true,
+			Issuer:    claims.Issuer,
+			Subject:   claims.UserID,
+			Audience:  claims.Audience,
+			Username:  claims.Username,
+			Email:     claims.Email,
+			Role:      claims.Role,
+			TokenUse:  claims.TokenType,
+			ExpiresAt: claims.ExpiresAt.Unix(),
+			IssuedAt:  claims.IssuedAt.Unix(),
+			JTI:       claims.ID,
+			Scope:     claims.Scope,
+			ClientID:  claims.ClientID,
+			Principal: claims.Principal,
+			Act:       claims.Act,
+		}
+		if claims.TokenType == models.AccessToken {
+			response.TokenType = "Bearer"
+		}
+		if claims.NotBefore != nil {
+			response.NotBefore = claims.NotBefore.Unix()
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
+
+// introspect runs every check the service itself applies to a token:
+// signature and expiry, denylist, user or service account still active, token version current,
+// and for refresh tokens that the stored token has not been rotated or revoked.
+// Restricted tokens (MFA challenge, password change, email verification) are never active.
+func introspect(database *sql.DB, verifier *auth.TokenVerifier, token string) (*models.Claims, error) {
+	claims, err := jwt.VerifyToken(token, verifier.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType.IsRestricted() {
+		return nil, errInactive
+	}
+
+	if err := verifier.CheckRevoked(claims); err != nil {
+		if err == revocation.ErrTokenRevoked {
+			return nil, errInactive
+		}
+		return nil, errLookupFailed
+	}
+
+	if claims.IsService() {
+		account, err := queries.GetServiceAccount(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrServiceAccountNotFound {
+				return nil, errInactive
+			}
+			return nil, errLookupFailed
+		}
+		if account.IsDisabled() || claims.TokenVersion < account.TokenVersion {
+			return nil, errInactive
+		}
+		return claims, nil
+	}
+
+	user, err := queries.GetUserByID(database, claims.UserID)
+	if err != nil {
+		if err == queries.ErrUserNotFound {
+			return nil, errInactive
+		}
+		return nil, errLookupFailed
+	}
+	if claims.TokenVersion < user.TokenVersion {
+		return nil, errInactive
+	}
+
+	if claims.TokenType == models.RefreshToken {
+		record, err := queries.GetRefreshTokenByHash(database, secure.HashToken(token))
+		if err != nil {
+			if err == queries.ErrRefreshTokenNotFound {
+				return nil, errInactive
+			}
+			return nil, errLookupFailed
+		}
+		if record.UsedAt != nil || record.RevokedAt != nil {
+			return nil, errInactive
+		}
+	}
+
+	return claims, nil
+}
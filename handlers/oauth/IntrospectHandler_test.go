@@ -0,0 +1,166 @@
+package oauth
+
+import (
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntrospectReportsRestrictedTokensInactive(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	user := &models.User{ID: "7d0c5c8e-0000-4000-8000-000000000001", Username: "alice", Email: "alice@example.com", Role: "User"}
+
+	// Restricted tokens are rejected before any lookup, so no database is needed
+	handler := IntrospectHandler(nil, verifier, map[string]string{"billing-api": "secret"})
+
+	restricted := []*models.Claims{
+		jwt.NewMFAChallengeClaims(user, nil),
+		jwt.NewPasswordChangeClaims(user, nil, models.PasswordAMR),
+		jwt.NewEmailVerificationClaims(user),
+	}
+	for _, claims := range restricted {
+		token, err := jwt.SignClaims(claims, keys)
+		if err != nil {
+			t.Fatalf("SignClaims: %v", err)
+		}
+
+		response := introspectToken(t, handler, token)
+		if len(response) != 1 || response["active"] != false {
+			t.Errorf("%s: got %v, want only active=false", claims.TokenType, response)
+		}
+	}
+}
+
+func TestIntrospectAuthenticatesCaller(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	handler := IntrospectHandler(nil, auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist()), map[string]string{"billing-api": "secret"})
+
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		token    string
+		want     int
+	}{
+		{"no credentials", "", "", "token", http.StatusUnauthorized},
+		{"wrong secret", "billing-api", "wrong", "token", http.StatusUnauthorized},
+		{"unknown client", "other-api", "secret", "token", http.StatusUnauthorized},
+		{"missing token", "billing-api", "secret", "", http.StatusBadRequest},
+		{"malformed token", "billing-api", "secret", "not-a-jwt", http.StatusOK},
+	}
+	for _, tt := range tests {
+		form := url.Values{"token": {tt.token}}
+		req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if tt.user != "" {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: missing WWW-Authenticate header", tt.name)
+		}
+		if tt.want == http.StatusOK && strings.TrimSpace(rec.Body.String()) != `{"active":false}` {
+			t.Errorf("%s: got %s, want an inactive response", tt.name, rec.Body)
+		}
+	}
+}
+
+func TestIntrospectActiveToken(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	handler := IntrospectHandler(db, verifier, map[string]string{"billing-api": "secret"})
+
+	claims := jwt.NewClaims(user, models.AccessToken)
+	token, err := jwt.SignClaims(claims, keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+
+	response := introspectToken(t, handler, token)
+	if response["active"] != true {
+		t.Fatalf("got %v, want an active token", response)
+	}
+	want := map[string]any{
+		"sub":        user.ID,
+		"username":   user.Username,
+		"email":      user.Email,
+		"role":       user.Role,
+		"token_type": "Bearer",
+		"token_use":  string(models.AccessToken),
+		"jti":        claims.ID,
+		"exp":        float64(claims.ExpiresAt.Unix()),
+	}
+	for name, value := range want {
+		if response[name] != value {
+			t.Errorf("%s = %v, want %v", name, response[name], value)
+		}
+	}
+
+	// A token issued before the user's token version was bumped is no longer active
+	if err := queries.BumpTokenVersion(db, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+	if response := introspectToken(t, handler, token); response["active"] != false {
+		t.Errorf("outdated token: got %v, want inactive", response)
+	}
+
+	user.TokenVersion++
+	claims = jwt.NewClaims(user, models.AccessToken)
+	token, err = jwt.SignClaims(claims, keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	if response := introspectToken(t, handler, token); response["active"] != true {
+		t.Fatalf("current token: got %v, want active", response)
+	}
+
+	if err := verifier.Denylist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if response := introspectToken(t, handler, token); response["active"] != false {
+		t.Errorf("revoked token: got %v, want inactive", response)
+	}
+}
+
+// introspectToken asks handler about token as the billing-api client and decodes the response
+func introspectToken(t *testing.T, handler http.HandlerFunc, token string) map[string]any {
+	t.Helper()
+
+	form := url.Values{"token": {token}}
+	req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("billing-api", "secret")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+	}
+	var response map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return response
+}
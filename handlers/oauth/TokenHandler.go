@@ -0,0 +1,330 @@
+package oauth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"go-auth/utils/secure"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// deviceCodeGrantType is the RFC 8628 device authorization grant
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// TokenHandler implements the OAuth 2.0 token endpoint for the authorization_code
+// (with PKCE), refresh_token and device_code grants. Tokens are the same as those issued by /login,
+// with the client_id and granted scope added. With oidc enabled, grants that include
+// the openid scope also return an ID token. Service accounts use the client_credentials grant.
+func TokenHandler(database *sql.DB, verifier *auth.TokenVerifier, oidc bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			respondOAuthError(w, http.StatusMethodNotAllowed, "invalid_request", "method not allowed")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+
+		if err := r.ParseForm(); err != nil {
+			respondOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+			return
+		}
+
+		// Service accounts authenticate against their own registry
+		if r.PostForm.Get("grant_type") == "client_credentials" {
+			issueServiceToken(w, r, database, verifier.Keys)
+			return
+		}
+
+		// Authenticate the client
+		client, err := authenticateClient(database, r)
+		if err != nil {
+			if err == errLookupFailed {
+				respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to authenticate client")
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			respondOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+			return
+		}
+
+		var grant *tokenGrant
+
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			grant, err = exchangeAuthorizationCode(database, verifier, client, r)
+		case "refresh_token":
+			grant, err = exchangeRefreshToken(database, verifier, client, r)
+		case deviceCodeGrantType:
+			grant, err = exchangeDeviceCode(database, verifier, client, r)
+		default:
+			respondOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "unsupported grant_type")
+			return
+		}
+
+		if err != nil {
+			if oauthErr, ok := err.(*grantError); ok {
+				respondOAuthError(w, http.StatusBadRequest, oauthErr.code, oauthErr.description)
+				return
+			}
+			respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue tokens")
+			return
+		}
+
+		response := models.OAuthTokenResponse{
+			AccessToken:  grant.tokens.AccessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(models.AccessTokenDuration.Seconds()),
+			RefreshToken: grant.tokens.RefreshToken,
+			Scope:        grant.scope,
+		}
+
+		if oidc && hasScope(grant.scope, "openid") {
+			response.IDToken, err = jwt.SignIDToken(grant.user, client.ClientID, grant.scope, grant.nonce, grant.authTime, grant.amr, verifier.Keys)
+			if err != nil {
+				respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue id token")
+				return
+			}
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
+
+// tokenGrant is the outcome of a successful grant
+type tokenGrant struct {
+	tokens   *models.TokenPair
+	user     *models.User
+	scope    string
+	nonce    string // Only set for the authorization_code grant
+	authTime time.Time
+	amr      []string // How the user authenticated, empty when unknown
+}
+
+// issueServiceToken handles the client_credentials grant for service accounts.
+// Only an access token is issued; the account requests a new one when it expires.
+func issueServiceToken(w http.ResponseWriter, r *http.Request, database *sql.DB, keys *jwt.KeySet) {
+	account, err := authenticateServiceAccount(database, r)
+	if err != nil {
+		if err == errLookupFailed {
+			respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to authenticate client")
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		respondOAuthError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	// Default to every scope the account holds
+	scope := strings.Join(account.Scopes, " ")
+	if requested := r.PostForm.Get("scope"); requested != "" {
+		var ok bool
+		scope, ok = resolveScope(account.Scopes, requested)
+		if !ok {
+			respondOAuthError(w, http.StatusBadRequest, "invalid_scope", "requested scope is not allowed for this client")
+			return
+		}
+	}
+
+	accessToken, err := jwt.SignClaims(jwt.NewServiceClaims(account, scope), keys)
+	if err != nil {
+		respondOAuthError(w, http.StatusInternalServerError, "server_error", "failed to issue tokens")
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, models.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(models.AccessTokenDuration.Seconds()),
+		Scope:       scope,
+	})
+}
+
+// grantError is an RFC 6749 error caused by the request rather than the server
+type grantError struct {
+	code        string
+	description string
+}
+
+func (e *grantError) Error() string {
+	return e.code + ": " + e.description
+}
+
+// exchangeAuthorizationCode redeems an authorization code, checking the client,
+// redirect URI and PKCE verifier it was issued with
+func exchangeAuthorizationCode(database *sql.DB, verifier *auth.TokenVerifier, client *models.OAuthClient, r *http.Request) (*tokenGrant, error) {
+	codeValue := r.PostForm.Get("code")
+	codeVerifier := r.PostForm.Get("code_verifier")
+	if codeValue == "" || codeVerifier == "" {
+		return nil, &grantError{"invalid_request", "code and code_verifier are required"}
+	}
+
+	// Codes are single-use: consuming it first means a replayed code always fails. Only the
+	// client it was issued to can consume it, so another client can't burn someone else's code.
+	code, err := queries.ConsumeAuthorizationCode(database, secure.HashToken(codeValue), client.ClientID)
+	if err != nil {
+		if err == queries.ErrAuthCodeNotFound {
+			return nil, &grantError{"invalid_grant", "invalid or expired authorization code"}
+		}
+		return nil, err
+	}
+
+	// redirect_uri is required if it was used in the authorization request, and must match if sent
+	redirectURI := r.PostForm.Get("redirect_uri")
+	if redirectURI == "" && code.RedirectURISupplied {
+		return nil, &grantError{"invalid_grant", "redirect_uri is required"}
+	}
+	if redirectURI != "" && redirectURI != code.RedirectURI {
+		return nil, &grantError{"invalid_grant", "redirect_uri mismatch"}
+	}
+
+	if !verifyCodeChallenge(codeVerifier, code.CodeChallenge) {
+		return nil, &grantError{"invalid_grant", "invalid code_verifier"}
+	}
+
+	user, err := queries.GetUserByID(database, code.UserID)
+	if err != nil {
+		if err == queries.ErrUserNotFound {
+			return nil, &grantError{"invalid_grant", "user not found"}
+		}
+		return nil, err
+	}
+
+	// Generate tokens (starts a new refresh token family bound to the client)
+	tokens, err := authhandler.IssueTokenPair(database, verifier.Keys, user, "", models.TokenOptions{
+		Scope:    code.Scope,
+		ClientID: client.ClientID,
+		AuthTime: code.AuthTime,
+		AMR:      code.AMR,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenGrant{
+		tokens:   tokens,
+		user:     user,
+		scope:    code.Scope,
+		nonce:    code.Nonce,
+		authTime: code.AuthTime,
+		amr:      code.AMR,
+	}, nil
+}
+
+// exchangeDeviceCode redeems an approved device code. Until the user decides, polling clients
+// get authorization_pending, or slow_down when they poll faster than the interval.
+func exchangeDeviceCode(database *sql.DB, verifier *auth.TokenVerifier, client *models.OAuthClient, r *http.Request) (*tokenGrant, error) {
+	deviceCode := r.PostForm.Get("device_code")
+	if deviceCode == "" {
+		return nil, &grantError{"invalid_request", "device_code is required"}
+	}
+	deviceCodeHash := secure.HashToken(deviceCode)
+
+	code, err := queries.PollDeviceCode(database, deviceCodeHash)
+	if err != nil {
+		if err == queries.ErrDeviceCodeNotFound {
+			return nil, &grantError{"invalid_grant", "invalid device code"}
+		}
+		return nil, err
+	}
+
+	if code.ClientID != client.ClientID {
+		return nil, &grantError{"invalid_grant", "device code was issued to another client"}
+	}
+	if !time.Now().Before(code.ExpiresAt) {
+		return nil, &grantError{"expired_token", "the device code has expired"}
+	}
+
+	switch code.Status {
+	case models.DeviceCodePending:
+		interval := time.Duration(code.Interval) * time.Second
+		if code.LastPolledAt != nil && time.Since(*code.LastPolledAt) < interval {
+			if err := queries.SlowDownDeviceCode(database, deviceCodeHash); err != nil {
+				return nil, err
+			}
+			return nil, &grantError{"slow_down", "polling too frequently"}
+		}
+		return nil, &grantError{"authorization_pending", "the user has not yet approved the request"}
+	case models.DeviceCodeDenied:
+		return nil, &grantError{"access_denied", "the user denied the request"}
+	case models.DeviceCodeConsumed:
+		return nil, &grantError{"invalid_grant", "device code has already been used"}
+	}
+
+	// Approved: the code is single-use, so consume it before issuing tokens
+	if err := queries.ConsumeDeviceCode(database, deviceCodeHash); err != nil {
+		if err == queries.ErrDeviceCodeNotFound {
+			return nil, &grantError{"invalid_grant", "device code has already been used"}
+		}
+		return nil, err
+	}
+
+	user, err := queries.GetUserByID(database, code.UserID)
+	if err != nil {
+		if err == queries.ErrUserNotFound {
+			return nil, &grantError{"invalid_grant", "user not found"}
+		}
+		return nil, err
+	}
+
+	// Generate tokens (starts a new refresh token family bound to the client)
+	tokens, err := authhandler.IssueTokenPair(database, verifier.Keys, user, "", models.TokenOptions{
+		Scope:    code.Scope,
+		ClientID: client.ClientID,
+		AuthTime: code.AuthTime,
+		AMR:      code.AMR,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenGrant{
+		tokens:   tokens,
+		user:     user,
+		scope:    code.Scope,
+		authTime: code.AuthTime,
+		amr:      code.AMR,
+	}, nil
+}
+
+// exchangeRefreshToken rotates a refresh token previously issued to the client
+func exchangeRefreshToken(database *sql.DB, verifier *auth.TokenVerifier, client *models.OAuthClient, r *http.Request) (*tokenGrant, error) {
+	refreshToken := r.PostForm.Get("refresh_token")
+	if refreshToken == "" {
+		return nil, &grantError{"invalid_request", "refresh_token is required"}
+	}
+
+	tokens, user, err := authhandler.RotateRefreshToken(database, verifier, refreshToken, client.ClientID)
+	if err != nil {
+		switch err {
+		case jwt.ErrExpiredToken, jwt.ErrInvalidToken, jwt.ErrInvalidClaims, jwt.ErrInvalidTokenType,
+			revocation.ErrTokenRevoked, queries.ErrRefreshTokenReused, queries.ErrRefreshTokenNotFound, queries.ErrUserNotFound:
+			return nil, &grantError{"invalid_grant", "invalid refresh token"}
+		}
+		return nil, err
+	}
+
+	// The scope and auth_time are carried unchanged in the rotated tokens
+	claims, err := jwt.VerifyToken(tokens.AccessToken, verifier.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &tokenGrant{
+		tokens: tokens,
+		user:   user,
+		scope:  claims.Scope,
+		amr:    claims.AMR,
+	}
+	if claims.AuthTime != nil {
+		grant.authTime = claims.AuthTime.Time
+	}
+	return grant, nil
+}
@@ -0,0 +1,295 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"go-auth/utils/secure"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExchangeAuthorizationCode(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+
+	const redirectURI = "https://app.example.com/callback"
+	client := dbtest.CreateOAuthClient(t, db, redirectURI)
+	other := dbtest.CreateOAuthClient(t, db, redirectURI)
+
+	codeVerifier := secure.RandomToken(32)
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	issueCode := func(redirectURISupplied bool) string {
+		code := secure.RandomToken(32)
+		err := queries.CreateAuthorizationCode(db, secure.HashToken(code), &models.AuthorizationCode{
+			ClientID:            client.ClientID,
+			UserID:              user.ID,
+			RedirectURI:         redirectURI,
+			RedirectURISupplied: redirectURISupplied,
+			CodeChallenge:       base64.RawURLEncoding.EncodeToString(challenge[:]),
+			CodeChallengeMethod: "S256",
+			AuthTime:            time.Now(),
+			ExpiresAt:           time.Now().Add(models.AuthorizationCodeDuration),
+		})
+		if err != nil {
+			t.Fatalf("CreateAuthorizationCode: %v", err)
+		}
+		return code
+	}
+	exchange := func(client *models.OAuthClient, code, redirectURI string) error {
+		form := url.Values{"code": {code}, "code_verifier": {codeVerifier}}
+		if redirectURI != "" {
+			form.Set("redirect_uri", redirectURI)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := req.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		_, err := exchangeAuthorizationCode(db, verifier, client, req)
+		return err
+	}
+	wantGrantError := func(name string, err error, description string) {
+		t.Helper()
+		var grantErr *grantError
+		if !errors.As(err, &grantErr) || grantErr.code != "invalid_grant" || grantErr.description != description {
+			t.Fatalf("%s: got %v, want invalid_grant: %s", name, err, description)
+		}
+	}
+
+	// Another client can't redeem the code, nor use it up
+	code := issueCode(true)
+	wantGrantError("other client", exchange(other, code, redirectURI), "invalid or expired authorization code")
+	if err := exchange(client, code, redirectURI); err != nil {
+		t.Fatalf("after another client tried it: %v", err)
+	}
+	wantGrantError("replayed", exchange(client, code, redirectURI), "invalid or expired authorization code")
+
+	wantGrantError("redirect_uri omitted", exchange(client, issueCode(true), ""), "redirect_uri is required")
+	wantGrantError("redirect_uri mismatch", exchange(client, issueCode(true), redirectURI+"/other"), "redirect_uri mismatch")
+	wantGrantError("redirect_uri mismatch when not required", exchange(client, issueCode(false), redirectURI+"/other"), "redirect_uri mismatch")
+
+	// Clients that relied on their only registered URI don't have to repeat it
+	if err := exchange(client, issueCode(false), ""); err != nil {
+		t.Fatalf("redirect_uri not supplied: %v", err)
+	}
+}
+
+func TestExchangeDeviceCodeSetsAuthTime(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	client := dbtest.CreateOAuthClient(t, db, "https://app.example.com/callback")
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+
+	deviceCode := secure.RandomToken(32)
+	userCode := secure.RandomCode(userCodeAlphabet, 8)
+	err := queries.CreateDeviceCode(db, secure.HashToken(deviceCode), &models.DeviceCode{
+		ClientID:  client.ClientID,
+		UserCode:  userCode,
+		Scope:     "read",
+		Interval:  int(models.DeviceCodePollInterval.Seconds()),
+		ExpiresAt: time.Now().Add(models.DeviceCodeDuration),
+	})
+	if err != nil {
+		t.Fatalf("CreateDeviceCode: %v", err)
+	}
+	signedIn := time.Now().Truncate(time.Second)
+	if err := queries.DecideDeviceCode(db, userCode, user.ID, true, models.PasswordAMR); err != nil {
+		t.Fatalf("DecideDeviceCode: %v", err)
+	}
+
+	form := url.Values{"device_code": {deviceCode}}
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := req.ParseForm(); err != nil {
+		t.Fatalf("ParseForm: %v", err)
+	}
+	grant, err := exchangeDeviceCode(db, verifier, client, req)
+	if err != nil {
+		t.Fatalf("exchangeDeviceCode: %v", err)
+	}
+
+	claims, err := verifier.Verify(grant.tokens.AccessToken, models.AccessToken)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.AuthTime == nil || claims.AuthTime.Time.Before(signedIn) || claims.AuthTime.Time.After(time.Now()) {
+		t.Fatalf("got auth_time %v, want the approval time", claims.AuthTime)
+	}
+	if grant.authTime.IsZero() {
+		t.Fatal("grant has no auth time for the ID token")
+	}
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	db := dbtest.Open(t)
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	verifier.Versions = revocation.NewVersionCache(db, 0)
+	handler := TokenHandler(db, verifier, false)
+
+	secret := secure.RandomToken(32)
+	account, err := queries.CreateServiceAccount(db, "svc-"+secure.RandomToken(8), secure.HashToken(secret), "Test", "", []string{"read", "write"})
+	if err != nil {
+		t.Fatalf("CreateServiceAccount: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM service_accounts WHERE id = $1`, account.ID) })
+
+	request := func(secret, scope string) *httptest.ResponseRecorder {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		if scope != "" {
+			form.Set("scope", scope)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(account.ClientID, secret)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	issue := func(scope string) *models.Claims {
+		t.Helper()
+		rec := request(secret, scope)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("scope %q: got status %d: %s", scope, rec.Code, rec.Body)
+		}
+		var response models.OAuthTokenResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if response.RefreshToken != "" {
+			t.Errorf("scope %q: got a refresh token", scope)
+		}
+		claims, err := verifier.Verify(response.AccessToken, models.AccessToken)
+		if err != nil {
+			t.Fatalf("scope %q: Verify: %v", scope, err)
+		}
+		return claims
+	}
+
+	// Every scope the account holds unless fewer are requested
+	claims := issue("")
+	if !claims.IsService() || claims.UserID != account.ID || claims.Scope != "read write" || claims.ClientID != account.ClientID {
+		t.Errorf("got principal %q sub %q scope %q client_id %q", claims.Principal, claims.UserID, claims.Scope, claims.ClientID)
+	}
+	if claims := issue("read"); claims.Scope != "read" {
+		t.Errorf("got scope %q, want read", claims.Scope)
+	}
+	if rec := request(secret, "admin"); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_scope") {
+		t.Errorf("scope not held: got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := request("wrong-secret", ""); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("wrong secret: got %d", rec.Code)
+	}
+
+	// Rotating the secret invalidates the old secret and the tokens issued with it
+	token, err := jwt.SignClaims(claims, keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	newSecret := secure.RandomToken(32)
+	if _, err := queries.RotateServiceAccountSecret(db, account.ID, secure.HashToken(newSecret)); err != nil {
+		t.Fatalf("RotateServiceAccountSecret: %v", err)
+	}
+	if rec := request(secret, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("old secret: got %d, want 401", rec.Code)
+	}
+	if _, err := verifier.Verify(token, models.AccessToken); err != revocation.ErrTokenRevoked {
+		t.Errorf("token from before the rotation: got %v, want ErrTokenRevoked", err)
+	}
+	secret = newSecret
+	issue("")
+
+	if err := queries.DisableServiceAccount(db, account.ID); err != nil {
+		t.Fatalf("DisableServiceAccount: %v", err)
+	}
+	if rec := request(secret, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("disabled account: got %d, want 401", rec.Code)
+	}
+}
+
+func TestExchangeDeviceCodeStates(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	client := dbtest.CreateOAuthClient(t, db, "https://app.example.com/callback")
+	other := dbtest.CreateOAuthClient(t, db, "https://app.example.com/callback")
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := auth.NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+
+	create := func() (string, string) {
+		t.Helper()
+		deviceCode := secure.RandomToken(32)
+		userCode := secure.RandomCode(userCodeAlphabet, 8)
+		err := queries.CreateDeviceCode(db, secure.HashToken(deviceCode), &models.DeviceCode{
+			ClientID:  client.ClientID,
+			UserCode:  userCode,
+			Interval:  int(models.DeviceCodePollInterval.Seconds()),
+			ExpiresAt: time.Now().Add(models.DeviceCodeDuration),
+		})
+		if err != nil {
+			t.Fatalf("CreateDeviceCode: %v", err)
+		}
+		return deviceCode, userCode
+	}
+	poll := func(client *models.OAuthClient, deviceCode string) (*tokenGrant, error) {
+		form := url.Values{"device_code": {deviceCode}}
+		req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if err := req.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		return exchangeDeviceCode(db, verifier, client, req)
+	}
+	wantGrantError := func(name string, err error, code string) {
+		t.Helper()
+		var grantErr *grantError
+		if !errors.As(err, &grantErr) || grantErr.code != code {
+			t.Fatalf("%s: got %v, want %s", name, err, code)
+		}
+	}
+
+	deviceCode, userCode := create()
+	_, err := poll(client, deviceCode)
+	wantGrantError("pending", err, "authorization_pending")
+	_, err = poll(client, deviceCode)
+	wantGrantError("polled too soon", err, "slow_down")
+	_, err = poll(other, deviceCode)
+	wantGrantError("other client", err, "invalid_grant")
+	_, err = poll(client, secure.RandomToken(32))
+	wantGrantError("unknown code", err, "invalid_grant")
+
+	if err := queries.DecideDeviceCode(db, userCode, user.ID, false, nil); err != nil {
+		t.Fatalf("DecideDeviceCode: %v", err)
+	}
+	_, err = poll(client, deviceCode)
+	wantGrantError("denied", err, "access_denied")
+
+	deviceCode, userCode = create()
+	if err := queries.DecideDeviceCode(db, userCode, user.ID, true, models.PasswordAMR); err != nil {
+		t.Fatalf("DecideDeviceCode: %v", err)
+	}
+	grant, err := poll(client, deviceCode)
+	if err != nil {
+		t.Fatalf("approved: %v", err)
+	}
+	if grant.user.ID != user.ID || grant.tokens.RefreshToken == "" {
+		t.Errorf("got user %q, refresh token %q", grant.user.ID, grant.tokens.RefreshToken)
+	}
+	_, err = poll(client, deviceCode)
+	wantGrantError("used", err, "invalid_grant")
+}
@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"net/http"
+	"strings"
+)
+
+// UserInfoHandler implements the OpenID Connect userinfo endpoint.
+// It requires an access token granted the openid scope; the email and profile
+// scopes control which claims are returned.
+func UserInfoHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		if !hasScope(claims.Scope, "openid") {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			respondOAuthError(w, http.StatusForbidden, "insufficient_scope", "the openid scope is required")
+			return
+		}
+
+		// Read the current user rather than the (possibly stale) token claims
+		user, err := queries.GetUserByID(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				respondOAuthError(w, http.StatusUnauthorized, "invalid_token", "user not found")
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		response := models.UserInfoResponse{Subject: user.ID}
+		for _, scope := range strings.Fields(claims.Scope) {
+			switch scope {
+			case "email":
+				emailVerified := user.EmailVerified()
+				response.Email = user.Email
+				response.EmailVerified = &emailVerified
+			case "profile":
+				response.PreferredUsername = user.Username
+			}
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
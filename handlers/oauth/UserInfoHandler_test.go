@@ -0,0 +1,71 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"go-auth/db/dbtest"
+	"go-auth/middleware/constants"
+	"go-auth/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// userInfoRequest calls handler as the middleware would after verifying a token with the scope
+func userInfoRequest(handler http.HandlerFunc, userID, scope string) *httptest.ResponseRecorder {
+	claims := &models.Claims{UserID: userID, TokenType: models.AccessToken, Scope: scope}
+	req := httptest.NewRequest(http.MethodGet, "/userinfo", nil)
+	req = req.WithContext(context.WithValue(req.Context(), constants.UserContextKey, claims))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestUserInfoRequiresOpenIDScope(t *testing.T) {
+	// The scope is checked before the user is looked up, so no database is needed
+	rec := userInfoRequest(UserInfoHandler(nil), "7d0c5c8e-0000-4000-8000-000000000001", "read email")
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got status %d, want 403", rec.Code)
+	}
+	if !strings.Contains(rec.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`) {
+		t.Errorf("WWW-Authenticate = %q", rec.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestUserInfoClaimsFollowScopes(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	handler := UserInfoHandler(db)
+
+	tests := []struct {
+		scope string
+		want  models.UserInfoResponse
+	}{
+		{"openid", models.UserInfoResponse{Subject: user.ID}},
+		{"openid email", models.UserInfoResponse{Subject: user.ID, Email: user.Email}},
+		{"openid profile", models.UserInfoResponse{Subject: user.ID, PreferredUsername: user.Username}},
+	}
+	for _, tt := range tests {
+		rec := userInfoRequest(handler, user.ID, tt.scope)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d: %s", tt.scope, rec.Code, rec.Body)
+		}
+
+		var got models.UserInfoResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: decode: %v", tt.scope, err)
+		}
+		if got.Subject != tt.want.Subject || got.Email != tt.want.Email || got.PreferredUsername != tt.want.PreferredUsername {
+			t.Errorf("%s: got %+v, want %+v", tt.scope, got, tt.want)
+		}
+		if (got.EmailVerified != nil) != (tt.want.Email != "") {
+			t.Errorf("%s: email_verified = %v", tt.scope, got.EmailVerified)
+		}
+	}
+
+	if rec := userInfoRequest(handler, "00000000-0000-4000-8000-000000000000", "openid"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unknown user: got status %d, want 401", rec.Code)
+	}
+}
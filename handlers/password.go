@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/breach"
+	"go-auth/utils/password"
+)
+
+// PasswordRules are the checks every new password must pass, shared by registration,
+// password changes and admin user creation
+type PasswordRules struct {
+	Policy   *models.PasswordPolicy
+	Breaches breach.Checker // nil disables breach screening
+}
+
+// Check validates a new password and returns the rules it breaks, reported against field.
+// The error is only set when the breach corpus could not be read.
+func (p *PasswordRules) Check(field, plainPassword, username, email string) ([]models.FieldError, error) {
+	errs := password.CheckPolicy(p.Policy, field, plainPassword, username, email)
+
+	if p.Breaches != nil {
+		breached, err := p.Breaches.IsBreached(plainPassword)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			errs = append(errs, models.FieldError{
+				Field:   field,
+				Code:    "breached",
+				Message: "has appeared in a data breach and must not be used",
+			})
+		}
+	}
+
+	return errs, nil
+}
+
+// CheckHistory rejects a new password that matches the user's current password or one of
+// the previous HistoryDepth-1 passwords. Stored hashes in any supported format are compared.
+func (p *PasswordRules) CheckHistory(database *sql.DB, user *models.User, field, plainPassword string) ([]models.FieldError, error) {
+	depth := p.Policy.HistoryDepth
+	if depth < 1 {
+		return nil, nil
+	}
+
+	hashes := []string{user.Password}
+	if depth > 1 {
+		previous, err := queries.GetPasswordHistory(database, user.ID, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, previous...)
+	}
+
+	for _, hash := range hashes {
+		if password.VerifyPassword(hash, plainPassword) {
+			return []models.FieldError{{
+				Field:   field,
+				Code:    "reused",
+				Message: fmt.Sprintf("must not match any of your last %d passwords", depth),
+			}}, nil
+		}
+	}
+
+	return nil, nil
+}
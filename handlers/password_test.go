@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/password"
+	"testing"
+)
+
+// fakeBreaches reports the passwords in its set as breached, or fails every lookup when err is set
+type fakeBreaches struct {
+	breached map[string]bool
+	err      error
+}
+
+func (f fakeBreaches) IsBreached(plainPassword string) (bool, error) {
+	return f.breached[plainPassword], f.err
+}
+
+func TestPasswordRulesCheckBreaches(t *testing.T) {
+	policy := &models.PasswordPolicy{MinLength: 8}
+	rules := &PasswordRules{Policy: policy, Breaches: fakeBreaches{breached: map[string]bool{"password1": true}}}
+
+	errs, err := rules.Check("password", "password1", "alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(errs) != 1 || errs[0].Code != "breached" || errs[0].Field != "password" {
+		t.Errorf("breached password: got %+v, want one breached error on password", errs)
+	}
+
+	errs, err = rules.Check("password", "a-fresh-password", "alice", "alice@example.com")
+	if err != nil || len(errs) != 0 {
+		t.Errorf("fresh password: got %+v, %v", errs, err)
+	}
+
+	// Policy errors are still reported alongside the breach
+	rules.Breaches = fakeBreaches{breached: map[string]bool{"short": true}}
+	errs, _ = rules.Check("new_password", "short", "alice", "alice@example.com")
+	if len(errs) < 2 || errs[len(errs)-1].Code != "breached" || errs[len(errs)-1].Field != "new_password" {
+		t.Errorf("short breached password: got %+v, want policy errors followed by breached", errs)
+	}
+
+	// A nil checker disables screening
+	rules.Breaches = nil
+	if errs, err := rules.Check("password", "password1", "alice", "alice@example.com"); err != nil || len(errs) != 0 {
+		t.Errorf("screening disabled: got %+v, %v", errs, err)
+	}
+}
+
+func TestPasswordRulesCheckCorpusError(t *testing.T) {
+	corpusErr := errors.New("corpus unreadable")
+	rules := &PasswordRules{Policy: &models.PasswordPolicy{}, Breaches: fakeBreaches{err: corpusErr}}
+
+	if _, err := rules.Check("password", "anything-goes", "", ""); err != corpusErr {
+		t.Errorf("got %v, want %v", err, corpusErr)
+	}
+}
+
+func TestPasswordRulesCheckHistory(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+	rules := &PasswordRules{Policy: &models.PasswordPolicy{HistoryDepth: 3}}
+
+	// Change the password four times: the last three are remembered
+	for _, plain := range []string{"first-password", "second-password", "third-password", "fourth-password"} {
+		hash, err := password.HashPassword(plain)
+		if err != nil {
+			t.Fatalf("HashPassword: %v", err)
+		}
+		if err := queries.ChangePassword(db, user.ID, hash, rules.Policy.HistoryDepth); err != nil {
+			t.Fatalf("ChangePassword: %v", err)
+		}
+	}
+	user, err := queries.GetUserByID(db, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+
+	tests := []struct {
+		plain  string
+		reused bool
+	}{
+		{"fourth-password", true}, // Current
+		{"third-password", true},
+		{"second-password", true},
+		{"first-password", false}, // Fell out of the history
+		{"fifth-password", false},
+	}
+	for _, tt := range tests {
+		errs, err := rules.CheckHistory(db, user, "new_password", tt.plain)
+		if err != nil {
+			t.Fatalf("CheckHistory(%s): %v", tt.plain, err)
+		}
+		if reused := len(errs) == 1 && errs[0].Code == "reused"; reused != tt.reused {
+			t.Errorf("CheckHistory(%s) = %+v, want reused %v", tt.plain, errs, tt.reused)
+		}
+	}
+
+	// A depth of 0 disables the check
+	rules.Policy.HistoryDepth = 0
+	if errs, err := rules.CheckHistory(db, user, "new_password", "fourth-password"); err != nil || len(errs) != 0 {
+		t.Errorf("history disabled: got %+v, %v", errs, err)
+	}
+}
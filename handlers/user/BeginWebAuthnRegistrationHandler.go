@@ -0,0 +1,66 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"go-auth/utils/webauthn"
+	"net/http"
+	"time"
+)
+
+// BeginWebAuthnRegistrationHandler starts registering a passkey for the current user. The
+// response is passed to navigator.credentials.create() and the result to /webauthn/register/finish.
+func BeginWebAuthnRegistrationHandler(database *sql.DB, rp *webauthn.RelyingParty) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Passkeys may only be added from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "passkeys can only be managed from a login session"})
+			return
+		}
+
+		user, err := queries.GetUserByID(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		existing, err := queries.GetWebAuthnCredentials(database, user.ID)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get passkeys"})
+			return
+		}
+
+		// Only the challenge's hash is stored; it is single-use and bound to this user
+		challenge := webauthn.NewChallenge()
+		err = queries.CreateWebAuthnChallenge(database, secure.HashToken(challenge), models.WebAuthnCeremonyRegistration,
+			user.ID, time.Now().Add(models.WebAuthnChallengeDuration))
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start passkey registration"})
+			return
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, models.WebAuthnCreationOptionsResponse{
+			PublicKey: rp.CreationOptions(user, challenge, existing),
+		})
+	}
+}
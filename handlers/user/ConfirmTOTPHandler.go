@@ -0,0 +1,91 @@
+package user
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/totp"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ConfirmTOTPHandler enables MFA once the user enters a valid code from their authenticator
+// app. The response carries the recovery codes, which are never shown again.
+func ConfirmTOTPHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Second factors may only be changed from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "mfa can only be managed from a login session"})
+			return
+		}
+
+		var req models.MFACodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Code == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "code is required"})
+			return
+		}
+
+		mfa, err := queries.GetUserMFA(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrMFANotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "no mfa enrollment in progress"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get mfa enrollment"})
+			return
+		}
+		if mfa.Enabled() {
+			handlers.RespondJSON(w, http.StatusConflict, map[string]string{"error": "mfa is already enabled"})
+			return
+		}
+
+		step, ok := totp.Validate(mfa.TOTPSecret, strings.TrimSpace(req.Code), time.Now())
+		if !ok {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid code"})
+			return
+		}
+
+		codes, hashes := authhandler.GenerateRecoveryCodes()
+		if err := queries.ConfirmTOTPEnrollment(database, claims.UserID, step, hashes); err != nil {
+			if err == queries.ErrMFANotFound {
+				handlers.RespondJSON(w, http.StatusConflict, map[string]string{"error": "mfa is already enabled"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to enable mfa"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditMFAEnabled,
+			ActorID:   claims.UserID,
+			SubjectID: claims.UserID,
+			Details:   map[string]string{"method": "totp"},
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+)
+
+// CreateAPIKeyHandler creates an API key for the current user.
+// The key is only returned in this response.
+func CreateAPIKeyHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Long-lived credentials may only be minted from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "api keys can only be created from a login session"})
+			return
+		}
+
+		var req models.CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Get user from database
+		user, err := queries.GetUserByID(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		if err := ValidateAPIKeyRequest(&req, user); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		apiKey, key, err := IssueAPIKey(database, user, &req, user.ID)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create api key"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditAPIKeyCreated,
+			ActorID:   user.ID,
+			SubjectID: user.ID,
+			Details:   map[string]string{"api_key_id": apiKey.ID, "prefix": apiKey.Prefix},
+		})
+
+		response := models.CreateAPIKeyResponse{
+			APIKey: apiKey,
+			Key:    key,
+		}
+
+		handlers.RespondJSON(w, http.StatusCreated, response)
+	}
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"context"
+	"go-auth/middleware/constants"
+	"go-auth/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// callWithClaims calls handler as the middleware would after verifying a token with claims
+func callWithClaims(handler http.HandlerFunc, method, body string, claims *models.Claims) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), constants.UserContextKey, claims))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+// notLoginSessions are tokens that act for the user without being one of their login sessions
+func notLoginSessions() map[string]*models.Claims {
+	userID := "7d0c5c8e-0000-4000-8000-000000000001"
+	return map[string]*models.Claims{
+		"api key":       {UserID: userID, TokenType: models.AccessToken, APIKeyID: "key-1"},
+		"impersonation": {UserID: userID, TokenType: models.AccessToken, Act: &models.Actor{Subject: "admin-1"}},
+		"service":       {UserID: "svc-1", TokenType: models.AccessToken, Principal: models.PrincipalService, ClientID: "svc-client"},
+		"oauth client":  {UserID: userID, TokenType: models.AccessToken, ClientID: "third-party-app", Scope: "openid"},
+	}
+}
+
+func TestCreateAPIKeyRequiresLoginSession(t *testing.T) {
+	// The guard runs before the user is looked up, so no database is needed
+	handler := CreateAPIKeyHandler(nil, nil)
+	for name, claims := range notLoginSessions() {
+		rec := callWithClaims(handler, http.MethodPost, `{"name":"ci"}`, claims)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: got status %d, want 403", name, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+	"strings"
+)
+
+// DeleteWebAuthnCredentialHandler removes one of the current user's passkeys
+func DeleteWebAuthnCredentialHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Passkeys may only be removed from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "passkeys can only be managed from a login session"})
+			return
+		}
+
+		// Extract credential ID from URL path
+		// Expected format: /webauthn/credentials/delete/{credential_id}
+		credentialID := strings.TrimPrefix(r.URL.Path, "/webauthn/credentials/delete/")
+		if credentialID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid credential id"})
+			return
+		}
+
+		err = queries.DeleteWebAuthnCredential(database, credentialID, claims.UserID)
+		if err != nil {
+			if err == queries.ErrCredentialNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "passkey not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete passkey"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditWebAuthnCredentialRemoved,
+			ActorID:   claims.UserID,
+			SubjectID: claims.UserID,
+			Details:   map[string]string{"credential_id": credentialID},
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "passkey deleted successfully"})
+	}
+}
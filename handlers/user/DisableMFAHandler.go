@@ -0,0 +1,99 @@
+package user
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/password"
+	"net/http"
+)
+
+// DisableMFAHandler turns MFA off for the current user. Both the password and a TOTP or
+// recovery code are required, so a stolen session alone can't remove the second factor.
+func DisableMFAHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Second factors may only be changed from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "mfa can only be managed from a login session"})
+			return
+		}
+
+		var req models.DisableMFARequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Password == "" || req.Code == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "password and code are required"})
+			return
+		}
+
+		// Get user from database
+		user, err := queries.GetUserByID(database, claims.UserID)
+		if err != nil {
+			if err == queries.ErrUserNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get user"})
+			return
+		}
+
+		if !password.VerifyPassword(user.Password, req.Password) {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid password"})
+			return
+		}
+
+		mfa, err := queries.GetUserMFA(database, claims.UserID)
+		if err != nil && err != queries.ErrMFANotFound {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get mfa status"})
+			return
+		}
+		if mfa == nil || !mfa.Enabled() {
+			handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "mfa is not enabled"})
+			return
+		}
+
+		method, err := authhandler.VerifyMFACode(database, mfa, req.Code)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify mfa code"})
+			return
+		}
+		if method == "" {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid mfa code"})
+			return
+		}
+
+		if err := queries.DeleteUserMFA(database, claims.UserID); err != nil && err != queries.ErrMFANotFound {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to disable mfa"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditMFADisabled,
+			ActorID:   claims.UserID,
+			SubjectID: claims.UserID,
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "mfa disabled"})
+	}
+}
@@ -0,0 +1,53 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/totp"
+	"net/http"
+)
+
+// EnrollTOTPHandler starts TOTP enrollment for the current user. The returned secret (or
+// otpauth:// URI, usually shown as a QR code) is added to an authenticator app; MFA is only
+// enabled once a code from the app is confirmed at /mfa/totp/confirm.
+func EnrollTOTPHandler(database *sql.DB, issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Second factors may only be changed from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "mfa can only be managed from a login session"})
+			return
+		}
+
+		secret := totp.GenerateSecret()
+		if err := queries.CreateTOTPEnrollment(database, claims.UserID, secret); err != nil {
+			if err == queries.ErrMFAAlreadyEnabled {
+				handlers.RespondJSON(w, http.StatusConflict, map[string]string{"error": "mfa is already enabled"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start mfa enrollment"})
+			return
+		}
+
+		response := models.TOTPEnrollmentResponse{
+			Secret:     secret,
+			OTPAuthURI: totp.KeyURI(issuer, claims.Email, secret),
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/secure"
+	"go-auth/utils/webauthn"
+	"net/http"
+	"strings"
+)
+
+// FinishWebAuthnRegistrationHandler verifies the new credential from navigator.credentials.create()
+// and stores it for the current user
+func FinishWebAuthnRegistrationHandler(database *sql.DB, rp *webauthn.RelyingParty, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Passkeys may only be added from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "passkeys can only be managed from a login session"})
+			return
+		}
+
+		var req models.FinishWebAuthnRegistrationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		credential := req.Credential
+		if credential.Type != "public-key" || len(credential.RawID) == 0 ||
+			len(credential.Response.ClientDataJSON) == 0 || len(credential.Response.AttestationObject) == 0 {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "credential is required"})
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if len(name) > 100 {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "name must be at most 100 characters"})
+			return
+		}
+
+		clientData, err := webauthn.ParseClientData(credential.Response.ClientDataJSON)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid credential"})
+			return
+		}
+
+		// The challenge must have been issued to this user for a registration
+		userID, err := queries.ConsumeWebAuthnChallenge(database, secure.HashToken(clientData.Challenge), models.WebAuthnCeremonyRegistration)
+		if err != nil {
+			if err == queries.ErrChallengeNotFound {
+				handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired challenge"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify passkey"})
+			return
+		}
+		if userID != claims.UserID {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired challenge"})
+			return
+		}
+
+		verified, err := rp.VerifyRegistration(clientData.Challenge, credential.Response.ClientDataJSON, credential.Response.AttestationObject)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "passkey verification failed"})
+			return
+		}
+		if string(verified.ID) != string(credential.RawID) {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "passkey verification failed"})
+			return
+		}
+
+		stored := &models.WebAuthnCredential{
+			ID:         base64.RawURLEncoding.EncodeToString(verified.ID),
+			UserID:     claims.UserID,
+			Name:       name,
+			PublicKey:  verified.PublicKey,
+			SignCount:  verified.SignCount,
+			Transports: credential.Response.Transports,
+		}
+		if stored.Transports == nil {
+			stored.Transports = []string{}
+		}
+
+		if err := queries.CreateWebAuthnCredential(database, stored); err != nil {
+			if err == queries.ErrCredentialExists {
+				handlers.RespondJSON(w, http.StatusConflict, map[string]string{"error": "passkey is already registered"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save passkey"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditWebAuthnCredentialAdded,
+			ActorID:   claims.UserID,
+			SubjectID: claims.UserID,
+			Details:   map[string]string{"credential_id": stored.ID},
+		})
+
+		handlers.RespondJSON(w, http.StatusCreated, stored)
+	}
+}
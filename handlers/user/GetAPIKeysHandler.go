@@ -0,0 +1,40 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"net/http"
+)
+
+// GetAPIKeysHandler returns the current user's API keys (never the keys themselves)
+func GetAPIKeysHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		keys, err := queries.GetAPIKeys(database, claims.UserID)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get api keys"})
+			return
+		}
+
+		response := models.GetAPIKeysResponse{
+			Total:   len(keys),
+			APIKeys: keys,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,48 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"net/http"
+)
+
+// GetMFAStatusHandler reports whether the current user has MFA enabled
+// and how many recovery codes they have left
+func GetMFAStatusHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		var response models.MFAStatusResponse
+
+		mfa, err := queries.GetUserMFA(database, claims.UserID)
+		if err != nil && err != queries.ErrMFANotFound {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get mfa status"})
+			return
+		}
+		if mfa != nil && mfa.Enabled() {
+			response.Enabled = true
+			response.EnabledAt = mfa.ConfirmedAt
+
+			response.RecoveryCodesRemaining, err = queries.CountRecoveryCodes(database, claims.UserID)
+			if err != nil {
+				handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get mfa status"})
+				return
+			}
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,40 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"net/http"
+)
+
+// GetWebAuthnCredentialsHandler returns the current user's passkeys
+func GetWebAuthnCredentialsHandler(database *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		credentials, err := queries.GetWebAuthnCredentials(database, claims.UserID)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get passkeys"})
+			return
+		}
+
+		response := models.GetWebAuthnCredentialsResponse{
+			Total:       len(credentials),
+			Credentials: credentials,
+		}
+
+		handlers.RespondJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"time"
+)
+
+// ValidateAPIKeyRequest checks a key request against its owner.
+// The returned error message is safe to show to the caller.
+func ValidateAPIKeyRequest(req *models.CreateAPIKeyRequest, owner *models.User) error {
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+
+	// A key can only carry its owner's own role, never more
+	if req.Role != "" && req.Role != owner.Role {
+		return errors.New("role must be the owner's role")
+	}
+
+	for _, scope := range req.Scopes {
+		if !handlers.IsValidScope(scope) {
+			return errors.New("invalid scope: " + scope)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+// IssueAPIKey generates a new API key for the owner and stores its hash.
+// The plaintext key is returned only here.
+func IssueAPIKey(database *sql.DB, owner *models.User, req *models.CreateAPIKeyRequest, createdBy string) (*models.APIKey, string, error) {
+	key := models.APIKeyPrefix + secure.RandomToken(32)
+
+	scopes := req.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	apiKey := &models.APIKey{
+		UserID:    owner.ID,
+		Name:      req.Name,
+		Prefix:    key[:len(models.APIKeyPrefix)+8],
+		Role:      req.Role,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: createdBy,
+	}
+
+	if err := queries.CreateAPIKey(database, apiKey, secure.HashToken(key)); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
@@ -0,0 +1,33 @@
+package user
+
+import (
+	"go-auth/models"
+	"testing"
+	"time"
+)
+
+func TestValidateAPIKeyRequest(t *testing.T) {
+	owner := &models.User{ID: "7d0c5c8e-0000-4000-8000-000000000001", Role: "User"}
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name string
+		req  models.CreateAPIKeyRequest
+		ok   bool
+	}{
+		{"owner's role", models.CreateAPIKeyRequest{Name: "ci", Role: "User"}, true},
+		{"scopes only", models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"read", "reports:write"}}, true},
+		{"future expiry", models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"read"}, ExpiresAt: &future}, true},
+		{"no name", models.CreateAPIKeyRequest{Role: "User"}, false},
+		{"other role", models.CreateAPIKeyRequest{Name: "ci", Role: "Super Admin"}, false},
+		{"scope with space", models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"read write"}}, false},
+		{"empty scope", models.CreateAPIKeyRequest{Name: "ci", Scopes: []string{""}}, false},
+		{"past expiry", models.CreateAPIKeyRequest{Name: "ci", Role: "User", ExpiresAt: &past}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateAPIKeyRequest(&tt.req, owner); (err == nil) != tt.ok {
+			t.Errorf("%s: got %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMFARequiresLoginSession(t *testing.T) {
+	// The guard runs before the database is used
+	handlers := map[string]http.HandlerFunc{
+		"enroll":                    EnrollTOTPHandler(nil, "go-auth"),
+		"confirm":                   ConfirmTOTPHandler(nil, nil),
+		"disable":                   DisableMFAHandler(nil, nil),
+		"regenerate recovery codes": RegenerateRecoveryCodesHandler(nil, nil),
+	}
+	for handlerName, handler := range handlers {
+		for name, claims := range notLoginSessions() {
+			rec := callWithClaims(handler, http.MethodPost, `{}`, claims)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("%s with %s: got status %d, want 403", handlerName, name, rec.Code)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"database/sql"
+	"encoding/json"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	authhandler "go-auth/handlers/auth"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+)
+
+// RegenerateRecoveryCodesHandler replaces the current user's recovery codes with a new set.
+// A current TOTP or recovery code is required; the old codes stop working immediately.
+func RegenerateRecoveryCodesHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Second factors may only be changed from a real login session
+		if !claims.IsLoginSession() {
+			handlers.RespondJSON(w, http.StatusForbidden, map[string]string{"error": "mfa can only be managed from a login session"})
+			return
+		}
+
+		var req models.MFACodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		// Validate input
+		if req.Code == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "code is required"})
+			return
+		}
+
+		mfa, err := queries.GetUserMFA(database, claims.UserID)
+		if err != nil && err != queries.ErrMFANotFound {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get mfa status"})
+			return
+		}
+		if mfa == nil || !mfa.Enabled() {
+			handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "mfa is not enabled"})
+			return
+		}
+
+		method, err := authhandler.VerifyMFACode(database, mfa, req.Code)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to verify mfa code"})
+			return
+		}
+		if method == "" {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid mfa code"})
+			return
+		}
+
+		codes, hashes := authhandler.GenerateRecoveryCodes()
+		if err := queries.ReplaceRecoveryCodes(database, claims.UserID, hashes); err != nil {
+			if err == queries.ErrMFANotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "mfa is not enabled"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to regenerate recovery codes"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditRecoveryCodesRegenerated,
+			ActorID:   claims.UserID,
+			SubjectID: claims.UserID,
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
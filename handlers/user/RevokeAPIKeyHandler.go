@@ -0,0 +1,56 @@
+package user
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/handlers"
+	"go-auth/middleware/auth"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"net/http"
+	"strings"
+)
+
+// RevokeAPIKeyHandler revokes one of the current user's API keys
+func RevokeAPIKeyHandler(database *sql.DB, auditLog *audit.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			handlers.RespondJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		// Get claims from context (set by middleware)
+		claims, err := auth.GetClaimsFromContext(r)
+		if err != nil {
+			handlers.RespondJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Extract key ID from URL path
+		// Expected format: /api-keys/revoke/{uuid}
+		keyID := strings.TrimPrefix(r.URL.Path, "/api-keys/revoke/")
+		if keyID == "" {
+			handlers.RespondJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid api key id"})
+			return
+		}
+
+		err = queries.RevokeAPIKey(database, keyID, claims.UserID)
+		if err != nil {
+			if err == queries.ErrAPIKeyNotFound {
+				handlers.RespondJSON(w, http.StatusNotFound, map[string]string{"error": "api key not found"})
+				return
+			}
+			handlers.RespondJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke api key"})
+			return
+		}
+
+		auditLog.Record(r, &models.AuditLogEntry{
+			Action:    models.AuditAPIKeyRevoked,
+			ActorID:   claims.UserID,
+			SubjectID: claims.UserID,
+			Details:   map[string]string{"api_key_id": keyID},
+		})
+
+		handlers.RespondJSON(w, http.StatusOK, map[string]string{"message": "api key revoked successfully"})
+	}
+}
@@ -0,0 +1,27 @@
+package user
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPasskeysRequireLoginSession(t *testing.T) {
+	// The guard runs before the relying party or database is used
+	handlers := []struct {
+		name    string
+		method  string
+		handler http.HandlerFunc
+	}{
+		{"begin registration", http.MethodPost, BeginWebAuthnRegistrationHandler(nil, nil)},
+		{"finish registration", http.MethodPost, FinishWebAuthnRegistrationHandler(nil, nil, nil)},
+		{"delete credential", http.MethodDelete, DeleteWebAuthnCredentialHandler(nil, nil)},
+	}
+	for _, h := range handlers {
+		for name, claims := range notLoginSessions() {
+			rec := callWithClaims(h.handler, h.method, `{}`, claims)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("%s with %s: got status %d, want 403", h.name, name, rec.Code)
+			}
+		}
+	}
+}
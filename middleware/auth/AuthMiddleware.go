@@ -5,12 +5,30 @@ import (
 	"go-auth/middleware/constants"
 	"go-auth/models"
 	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
 	"net/http"
 	"strings"
 )
 
-// AuthMiddleware verifies JWT token and extracts claims
-func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
+// AuthMiddleware verifies the access token (signature, expiry, revocation) or API key and extracts claims.
+// Only access tokens are accepted unless allowedTypes lists others, e.g. the restricted
+// password change token for /change-password. Tokens issued to OAuth clients are refused;
+// see ScopedAuthMiddleware.
+func AuthMiddleware(verifier *TokenVerifier, allowedTypes ...models.TokenType) func(http.Handler) http.Handler {
+	return authMiddleware(verifier, "", allowedTypes)
+}
+
+// ScopedAuthMiddleware is AuthMiddleware for resource endpoints that OAuth clients may call too,
+// as long as their token was granted scope.
+func ScopedAuthMiddleware(verifier *TokenVerifier, scope string) func(http.Handler) http.Handler {
+	return authMiddleware(verifier, scope, nil)
+}
+
+func authMiddleware(verifier *TokenVerifier, scope string, allowedTypes []models.TokenType) func(http.Handler) http.Handler {
+	if len(allowedTypes) == 0 {
+		allowedTypes = []models.TokenType{models.AccessToken}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract token from Authorization header
@@ -20,32 +38,61 @@ func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Bearer token format: "Bearer <token>"
+			// Bearer token format: "Bearer <token>", API key format: "ApiKey <key>"
 			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "ApiKey") {
 				constants.RespondError(w, http.StatusUnauthorized, "invalid authorization header format")
 				return
 			}
 
 			tokenString := parts[1]
 
-			// Verify token
-			claims, err := jwt.VerifyToken(tokenString, secretKey)
+			// Verify it's a valid, unrevoked access token or API key
+			var claims *models.Claims
+			var err error
+			if parts[0] == "ApiKey" {
+				claims, err = verifier.VerifyAPIKey(tokenString)
+			} else {
+				claims, err = verifier.Verify(tokenString, allowedTypes...)
+			}
 			if err != nil {
-				if err == jwt.ErrExpiredToken {
+				switch err {
+				case jwt.ErrExpiredToken:
 					constants.RespondError(w, http.StatusUnauthorized, "token expired")
-					return
+				case jwt.ErrInvalidTokenType:
+					constants.RespondError(w, http.StatusUnauthorized, "invalid token type")
+				case revocation.ErrTokenRevoked:
+					constants.RespondError(w, http.StatusUnauthorized, "token revoked")
+				case jwt.ErrInvalidToken:
+					constants.RespondError(w, http.StatusUnauthorized, "invalid token")
+				default:
+					constants.RespondError(w, http.StatusInternalServerError, "failed to verify token")
 				}
-				constants.RespondError(w, http.StatusUnauthorized, "invalid token")
 				return
 			}
 
-			// Verify it's an access token
-			if claims.TokenType != models.AccessToken {
-				constants.RespondError(w, http.StatusUnauthorized, "invalid token type")
+			// OAuth clients hold the user's role but were only granted their scopes, so keep
+			// them off every endpoint those scopes don't cover
+			if claims.IsOAuthClient() && (scope == "" || !claims.HasScope(scope)) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+				constants.RespondError(w, http.StatusForbidden, "insufficient scope")
 				return
 			}
 
+			// Keep a trail of everything an admin does while impersonating a user
+			if claims.IsImpersonated() && verifier.Audit != nil {
+				verifier.Audit.Record(r, &models.AuditLogEntry{
+					Action:    models.AuditImpersonatedRequest,
+					ActorID:   claims.Act.Subject,
+					SubjectID: claims.UserID,
+					Details: map[string]string{
+						"method": r.Method,
+						"path":   r.URL.Path,
+						"jti":    claims.ID,
+					},
+				})
+			}
+
 			// Store claims in context for handler access
 			ctx := context.WithValue(r.Context(), constants.UserContextKey, claims)
 			r = r.WithContext(ctx)
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"go-auth/middleware"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthMiddlewareTokenTypes(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	user := &models.User{ID: "7d0c5c8e-0000-4000-8000-000000000001", Username: "alice", Email: "alice@example.com", Role: "User"}
+
+	sign := func(claims *models.Claims) string {
+		t.Helper()
+		token, err := jwt.SignClaims(claims, keys)
+		if err != nil {
+			t.Fatalf("SignClaims: %v", err)
+		}
+		return token
+	}
+	access := sign(jwt.NewClaims(user, models.AccessToken))
+	passwordChange := sign(jwt.NewPasswordChangeClaims(user, nil, models.PasswordAMR))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := GetClaimsFromContext(r); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	protected := AuthMiddleware(verifier)(ok)
+	changePassword := AuthMiddleware(verifier, models.AccessToken, models.PasswordChangeToken)(ok)
+
+	tests := []struct {
+		name          string
+		handler       http.Handler
+		authorization string
+		want          int
+		wantError     string
+	}{
+		{"access token", protected, "Bearer " + access, http.StatusOK, ""},
+		{"restricted token", protected, "Bearer " + passwordChange, http.StatusUnauthorized, "invalid token type"},
+		{"access token on change password", changePassword, "Bearer " + access, http.StatusOK, ""},
+		{"restricted token on change password", changePassword, "Bearer " + passwordChange, http.StatusOK, ""},
+		{"missing header", protected, "", http.StatusUnauthorized, "missing authorization header"},
+		{"wrong scheme", protected, "Basic " + access, http.StatusUnauthorized, "invalid authorization header format"},
+		{"garbage token", protected, "Bearer not-a-token", http.StatusUnauthorized, "invalid token"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.wantError != "" && !strings.Contains(rec.Body.String(), `"`+tt.wantError+`"`) {
+			t.Errorf("%s: body = %s, want error %q", tt.name, rec.Body.String(), tt.wantError)
+		}
+	}
+}
+
+func TestAuthMiddlewareOAuthClientTokens(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	admin := &models.User{ID: "7d0c5c8e-0000-4000-8000-000000000002", Username: "root", Email: "root@example.com", Role: "Super Admin"}
+
+	sign := func(claims *models.Claims) string {
+		t.Helper()
+		token, err := jwt.SignClaims(claims, keys)
+		if err != nil {
+			t.Fatalf("SignClaims: %v", err)
+		}
+		return token
+	}
+	clientToken := func(scope string) string {
+		claims := jwt.NewClaims(admin, models.AccessToken)
+		claims.ClientID = "third-party-app"
+		claims.Scope = scope
+		return sign(claims)
+	}
+	openidOnly := clientToken("openid")
+	readOnly := clientToken("read")
+	session := sign(jwt.NewClaims(admin, models.AccessToken))
+	service := sign(jwt.NewServiceClaims(&models.ServiceAccount{ID: "svc-1", ClientID: "svc-client", Name: "deployer", Role: "Super Admin"}, ""))
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	adminRoute := AuthMiddleware(verifier)(middleware.RequireRole("Super Admin")(ok))
+	userInfo := ScopedAuthMiddleware(verifier, "openid")(ok)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		token   string
+		want    int
+	}{
+		{"openid client token on admin route", adminRoute, openidOnly, http.StatusForbidden},
+		{"read client token on admin route", adminRoute, readOnly, http.StatusForbidden},
+		{"login session on admin route", adminRoute, session, http.StatusOK},
+		{"service token on admin route", adminRoute, service, http.StatusOK},
+		{"openid client token on userinfo", userInfo, openidOnly, http.StatusOK},
+		{"read client token on userinfo", userInfo, readOnly, http.StatusForbidden},
+		{"login session on userinfo", userInfo, session, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		rec := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusForbidden && !strings.Contains(rec.Body.String(), `"insufficient scope"`) {
+			t.Errorf("%s: body = %s, want error %q", tt.name, rec.Body.String(), "insufficient scope")
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/audit"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"slices"
+)
+
+// TokenVerifier checks a token's signature, expiry, type and revocation status.
+// When Versions is set, it also rejects tokens issued before the user's
+// current token_version (role/password change, deletion, forced logout).
+// When Audit is set, requests made with impersonation tokens are recorded.
+// When Database is set, API keys are accepted as well.
+type TokenVerifier struct {
+	Keys     *jwt.KeySet
+	Denylist revocation.Denylist
+	Versions *revocation.VersionCache
+	Audit    *audit.Logger
+	Database *sql.DB
+}
+
+// NewTokenVerifier creates a verifier for the given key set and denylist
+func NewTokenVerifier(keys *jwt.KeySet, denylist revocation.Denylist) *TokenVerifier {
+	return &TokenVerifier{
+		Keys:     keys,
+		Denylist: denylist,
+	}
+}
+
+// Verify validates the token and returns its claims.
+// Returns jwt.ErrInvalidTokenType if the token is not of one of the expected types
+// and revocation.ErrTokenRevoked if its jti is on the denylist or it is outdated.
+func (v *TokenVerifier) Verify(tokenString string, tokenTypes ...models.TokenType) (*models.Claims, error) {
+	claims, err := jwt.VerifyToken(tokenString, v.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(tokenTypes, claims.TokenType) {
+		return nil, jwt.ErrInvalidTokenType
+	}
+
+	if err := v.CheckRevoked(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// CheckRevoked reports revocation.ErrTokenRevoked if already verified claims
+// are on the denylist or, with the online check enabled, outdated
+func (v *TokenVerifier) CheckRevoked(claims *models.Claims) error {
+	// Tokens issued before jti existed cannot be revoked individually
+	if claims.ID != "" {
+		revoked, err := v.Denylist.IsRevoked(claims.ID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return revocation.ErrTokenRevoked
+		}
+	}
+
+	// Optional online check against the principal's current token version
+	if v.Versions != nil {
+		var version int
+		var err error
+		if claims.IsService() {
+			version, err = v.Versions.GetService(claims.UserID)
+		} else {
+			version, err = v.Versions.Get(claims.UserID)
+		}
+		if err != nil {
+			if err == queries.ErrUserNotFound || err == queries.ErrServiceAccountNotFound {
+				return revocation.ErrTokenRevoked
+			}
+			return err
+		}
+		if claims.TokenVersion < version {
+			return revocation.ErrTokenRevoked
+		}
+	}
+
+	return nil
+}
\ No newline at end of file
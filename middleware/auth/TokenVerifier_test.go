@@ -0,0 +1,89 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"testing"
+	"time"
+)
+
+func TestTokenVerifierRejectsRevokedTokens(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	user := &models.User{ID: "7d0c5c8e-0000-4000-8000-000000000001", Username: "alice", Email: "alice@example.com", Role: "User"}
+
+	claims := jwt.NewClaims(user, models.AccessToken)
+	token, err := jwt.SignClaims(claims, keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+
+	if _, err := verifier.Verify(token, models.AccessToken); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if _, err := verifier.Verify(token, models.RefreshToken); err != jwt.ErrInvalidTokenType {
+		t.Fatalf("wrong type: got %v, want ErrInvalidTokenType", err)
+	}
+
+	if err := verifier.Denylist.Revoke(claims.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := verifier.Verify(token, models.AccessToken); err != revocation.ErrTokenRevoked {
+		t.Fatalf("revoked token: got %v, want ErrTokenRevoked", err)
+	}
+
+	// Other tokens of the same user stay valid
+	other, err := jwt.SignClaims(jwt.NewClaims(user, models.AccessToken), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	if _, err := verifier.Verify(other, models.AccessToken); err != nil {
+		t.Fatalf("other token: %v", err)
+	}
+}
+
+func TestTokenVerifierRejectsOutdatedTokens(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	verifier.Versions = revocation.NewVersionCache(db, 0)
+
+	sign := func() string {
+		t.Helper()
+		token, err := jwt.SignClaims(jwt.NewClaims(user, models.AccessToken), keys)
+		if err != nil {
+			t.Fatalf("SignClaims: %v", err)
+		}
+		return token
+	}
+
+	old := sign()
+	if _, err := verifier.Verify(old, models.AccessToken); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := queries.BumpTokenVersion(db, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+	if _, err := verifier.Verify(old, models.AccessToken); err != revocation.ErrTokenRevoked {
+		t.Fatalf("outdated token: got %v, want ErrTokenRevoked", err)
+	}
+
+	user.TokenVersion++
+	current := sign()
+	if _, err := verifier.Verify(current, models.AccessToken); err != nil {
+		t.Fatalf("current token: %v", err)
+	}
+
+	if err := queries.DeleteUser(db, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := verifier.Verify(current, models.AccessToken); err != revocation.ErrTokenRevoked {
+		t.Fatalf("deleted user: got %v, want ErrTokenRevoked", err)
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"go-auth/utils/secure"
+	"log"
+	"strings"
+)
+
+// VerifyAPIKey looks up an API key and returns claims equivalent to an access token
+// for its owner, restricted to the key's role and scopes.
+// Returns jwt.ErrInvalidToken for unknown keys and revocation.ErrTokenRevoked for
+// revoked or expired keys, deleted owners, or owners who no longer hold the key's role.
+func (v *TokenVerifier) VerifyAPIKey(key string) (*models.Claims, error) {
+	if v.Database == nil || !strings.HasPrefix(key, models.APIKeyPrefix) {
+		return nil, jwt.ErrInvalidToken
+	}
+
+	apiKey, err := queries.GetAPIKeyByHash(v.Database, secure.HashToken(key))
+	if err != nil {
+		if err == queries.ErrAPIKeyNotFound {
+			return nil, jwt.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !apiKey.IsActive() {
+		return nil, revocation.ErrTokenRevoked
+	}
+
+	owner, err := queries.GetUserByID(v.Database, apiKey.UserID)
+	if err != nil {
+		if err == queries.ErrUserNotFound {
+			return nil, revocation.ErrTokenRevoked
+		}
+		return nil, err
+	}
+
+	// A key never grants more than its owner currently holds
+	if apiKey.Role != "" && apiKey.Role != owner.Role {
+		return nil, revocation.ErrTokenRevoked
+	}
+
+	// Last-used tracking must not fail the request
+	if err := queries.TouchAPIKey(v.Database, apiKey.ID); err != nil {
+		log.Printf("Failed to record api key use: %v", err)
+	}
+
+	return jwt.NewAPIKeyClaims(owner, apiKey), nil
+}
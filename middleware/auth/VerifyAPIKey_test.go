@@ -0,0 +1,99 @@
+package auth
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"go-auth/models"
+	"go-auth/utils/jwt"
+	"go-auth/utils/revocation"
+	"go-auth/utils/secure"
+	"testing"
+	"time"
+)
+
+func TestVerifyAPIKeyWithoutDatabase(t *testing.T) {
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+
+	if _, err := verifier.VerifyAPIKey(models.APIKeyPrefix + secure.RandomToken(32)); err != jwt.ErrInvalidToken {
+		t.Fatalf("got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	db := dbtest.Open(t)
+	owner := dbtest.CreateUser(t, db)
+
+	keys := jwt.NewKeySet(jwt.NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	verifier := NewTokenVerifier(keys, revocation.NewMemoryDenylist())
+	verifier.Database = db
+
+	issue := func(role string, expiresAt *time.Time) (*models.APIKey, string) {
+		t.Helper()
+		key := models.APIKeyPrefix + secure.RandomToken(32)
+		apiKey := &models.APIKey{
+			UserID:    owner.ID,
+			Name:      "test",
+			Prefix:    key[:len(models.APIKeyPrefix)+8],
+			Role:      role,
+			Scopes:    []string{"read", "reports"},
+			ExpiresAt: expiresAt,
+			CreatedBy: owner.ID,
+		}
+		if err := queries.CreateAPIKey(db, apiKey, secure.HashToken(key)); err != nil {
+			t.Fatalf("CreateAPIKey: %v", err)
+		}
+		return apiKey, key
+	}
+
+	apiKey, key := issue(owner.Role, nil)
+	claims, err := verifier.VerifyAPIKey(key)
+	if err != nil {
+		t.Fatalf("VerifyAPIKey: %v", err)
+	}
+	if !claims.IsAPIKey() || claims.APIKeyID != apiKey.ID || claims.UserID != owner.ID || claims.Role != owner.Role || claims.Scope != "read reports" {
+		t.Errorf("got api key %q sub %q role %q scope %q", claims.APIKeyID, claims.UserID, claims.Role, claims.Scope)
+	}
+
+	// Scopes-only keys carry no role
+	_, scoped := issue("", nil)
+	if claims, err := verifier.VerifyAPIKey(scoped); err != nil || claims.Role != "" {
+		t.Errorf("scopes-only key: got role %q, %v", claims.Role, err)
+	}
+
+	if _, err := verifier.VerifyAPIKey(models.APIKeyPrefix + secure.RandomToken(32)); err != jwt.ErrInvalidToken {
+		t.Errorf("unknown key: got %v, want ErrInvalidToken", err)
+	}
+	if _, err := verifier.VerifyAPIKey(key[len(models.APIKeyPrefix):]); err != jwt.ErrInvalidToken {
+		t.Errorf("key without prefix: got %v, want ErrInvalidToken", err)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	_, expiredKey := issue(owner.Role, &expired)
+	if _, err := verifier.VerifyAPIKey(expiredKey); err != revocation.ErrTokenRevoked {
+		t.Errorf("expired key: got %v, want ErrTokenRevoked", err)
+	}
+
+	if err := queries.RevokeAPIKey(db, apiKey.ID, owner.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if _, err := verifier.VerifyAPIKey(key); err != revocation.ErrTokenRevoked {
+		t.Errorf("revoked key: got %v, want ErrTokenRevoked", err)
+	}
+
+	// A key stops working once its owner no longer holds its role
+	_, key = issue(owner.Role, nil)
+	if _, err := queries.UpdateUserRole(db, owner.ID, "Auditor"); err != nil {
+		t.Fatalf("UpdateUserRole: %v", err)
+	}
+	if _, err := verifier.VerifyAPIKey(key); err != revocation.ErrTokenRevoked {
+		t.Errorf("owner lost the role: got %v, want ErrTokenRevoked", err)
+	}
+
+	if err := queries.DeleteUser(db, owner.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := verifier.VerifyAPIKey(scoped); err != revocation.ErrTokenRevoked {
+		t.Errorf("deleted owner: got %v, want ErrTokenRevoked", err)
+	}
+}
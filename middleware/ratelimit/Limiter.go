@@ -0,0 +1,18 @@
+package ratelimit
+
+import "net"
+
+// Limiter holds what every rate limited route shares: the bucket store and the
+// proxies whose X-Forwarded-For header is trusted to carry the client IP
+type Limiter struct {
+	Store          Store
+	TrustedProxies []*net.IPNet
+}
+
+// NewLimiter creates a limiter backed by store
+func NewLimiter(store Store, trustedProxies []*net.IPNet) *Limiter {
+	return &Limiter{
+		Store:          store,
+		TrustedProxies: trustedProxies,
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"go-auth/models"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps token buckets in process memory.
+// Limits are per instance, so only suitable for single-instance deployments.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time // When the bucket will have refilled completely
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+// Take removes a token from the bucket for key
+func (s *MemoryStore) Take(key string, limit models.RateLimit) (bool, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(limit.Requests)
+	rate := limit.RefillRate()
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = min(capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		return false, bucket.tokens, nil
+	}
+	bucket.tokens--
+	bucket.fullAt = now.Add(time.Duration((capacity - bucket.tokens) / rate * float64(time.Second)))
+	return true, bucket.tokens, nil
+}
+
+// Purge drops buckets that have refilled completely, since a new bucket starts out full
+func (s *MemoryStore) Purge() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"testing"
+	"time"
+)
+
+// testStore checks the token bucket behaviour every Store must have
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	key := "test " + secure.RandomToken(8)
+	limit := models.RateLimit{Key: models.RateLimitByIP, Requests: 3, Per: 300 * time.Millisecond}
+
+	take := func() (bool, float64) {
+		t.Helper()
+		allowed, remaining, err := store.Take(key, limit)
+		if err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		return allowed, remaining
+	}
+
+	// A new bucket allows a burst of Requests
+	for i := 1; i <= 3; i++ {
+		if allowed, remaining := take(); !allowed || int(remaining+0.5) != 3-i {
+			t.Fatalf("request %d: got %v with %.2f left, want allowed with %d left", i, allowed, remaining, 3-i)
+		}
+	}
+	if allowed, remaining := take(); allowed || remaining >= 1 {
+		t.Fatalf("request 4: got %v with %.2f left, want refused", allowed, remaining)
+	}
+
+	// Other keys have their own bucket
+	if allowed, _, err := store.Take(key+" other", limit); err != nil || !allowed {
+		t.Fatalf("other key: got %v, %v", allowed, err)
+	}
+
+	// One request's worth refills after Per/Requests
+	time.Sleep(150 * time.Millisecond)
+	if allowed, _ := take(); !allowed {
+		t.Fatal("not refilled")
+	}
+
+	// Purge keeps buckets that are still refilling
+	if err := store.Purge(); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if allowed, _ := take(); allowed {
+		t.Fatal("purge dropped a bucket that was still refilling")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	testStore(t, store)
+
+	// Buckets that have refilled completely are dropped
+	time.Sleep(400 * time.Millisecond)
+	if err := store.Purge(); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(store.buckets) != 0 {
+		t.Errorf("%d buckets left after purging full ones", len(store.buckets))
+	}
+}
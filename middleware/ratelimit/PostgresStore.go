@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+)
+
+// PostgresStore keeps token buckets in the rate_limit_buckets table,
+// so every service instance shares the same limits
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store backed by the database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Take removes a token from the bucket for key
+func (s *PostgresStore) Take(key string, limit models.RateLimit) (bool, float64, error) {
+	return queries.TakeRateLimitToken(s.db, key, float64(limit.Requests), limit.RefillRate())
+}
+
+// Purge deletes buckets that have refilled completely
+func (s *PostgresStore) Purge() error {
+	return queries.DeleteFullRateLimitBuckets(s.db)
+}
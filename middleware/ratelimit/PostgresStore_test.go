@@ -0,0 +1,10 @@
+package ratelimit
+
+import (
+	"go-auth/db/dbtest"
+	"testing"
+)
+
+func TestPostgresStore(t *testing.T) {
+	testStore(t, NewPostgresStore(dbtest.Open(t)))
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"fmt"
+	"go-auth/middleware/constants"
+	"go-auth/models"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// RateLimitMiddleware applies the route's limits before the handler runs. Every limit has its
+// own token bucket per key; the request is refused with 429 as soon as one of them is empty.
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset describe the most restrictive limit,
+// and refused requests also get Retry-After. With no limits the handler is returned unchanged.
+// Store errors are logged and the request is let through, so an outage doesn't lock users out.
+func RateLimitMiddleware(limiter *Limiter, route string, limits ...models.RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(limits) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tightest *models.RateLimit
+			var tightestRemaining float64
+
+			for _, limit := range limits {
+				key, ok := limiter.requestKey(r, limit.Key)
+				if !ok {
+					continue
+				}
+
+				allowed, remaining, err := limiter.Store.Take(bucketKey(route, limit, key), limit)
+				if err != nil {
+					log.Printf("Failed to check %s rate limit for %s: %v", limit.Key, route, err)
+					continue
+				}
+
+				if !allowed {
+					setRateLimitHeaders(w, limit, remaining)
+					retryAfter := math.Ceil((1 - remaining) / limit.RefillRate())
+					w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+					constants.RespondError(w, http.StatusTooManyRequests, "rate limit exceeded")
+					return
+				}
+
+				if tightest == nil || remaining/float64(limit.Requests) < tightestRemaining/float64(tightest.Requests) {
+					tightest = &limit
+					tightestRemaining = remaining
+				}
+			}
+
+			if tightest != nil {
+				setRateLimitHeaders(w, *tightest, tightestRemaining)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bucketKey names the bucket of one limit for one key. The limit's size and period are part of
+// it, so two limits of the same kind on a route (e.g. per minute and per day) never share a
+// bucket, and changing a limit starts over with full buckets.
+func bucketKey(route string, limit models.RateLimit, key string) string {
+	return fmt.Sprintf("%s %s:%d/%s:%s", route, limit.Key, limit.Requests, limit.Per, key)
+}
+
+// setRateLimitHeaders describes the limit: the burst size, the requests left and the
+// seconds until the bucket is full again
+func setRateLimitHeaders(w http.ResponseWriter, limit models.RateLimit, remaining float64) {
+	reset := math.Ceil((float64(limit.Requests) - remaining) / limit.RefillRate())
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(int(math.Floor(remaining))))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(int(reset)))
+}
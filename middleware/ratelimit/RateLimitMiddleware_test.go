@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"errors"
+	"go-auth/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddlewareKeepsLimitsOfTheSameKindApart(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), nil)
+	handler := RateLimitMiddleware(limiter, "/login",
+		models.RateLimit{Key: models.RateLimitByIP, Requests: 3, Per: time.Minute},
+		models.RateLimit{Key: models.RateLimitByIP, Requests: 100, Per: 24 * time.Hour},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The per-minute limit allows a burst of 3; a shared bucket would refuse the second request
+	for i := range 3 {
+		rec := request()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: got status %d, want 200", i+1, rec.Code)
+		}
+		if got, want := rec.Header().Get("RateLimit-Limit"), "3"; got != want {
+			t.Fatalf("request %d: got RateLimit-Limit %s, want %s", i+1, got, want)
+		}
+	}
+
+	rec := request()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request 4: got status %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("request 4: missing Retry-After")
+	}
+}
+
+func TestBucketKeyDependsOnTheLimit(t *testing.T) {
+	perMinute := models.RateLimit{Key: models.RateLimitByIP, Requests: 5, Per: time.Minute}
+	perDay := models.RateLimit{Key: models.RateLimitByIP, Requests: 100, Per: 24 * time.Hour}
+	larger := models.RateLimit{Key: models.RateLimitByIP, Requests: 10, Per: time.Minute}
+
+	keys := map[string]bool{}
+	for _, limit := range []models.RateLimit{perMinute, perDay, larger} {
+		keys[bucketKey("/login", limit, "192.0.2.1")] = true
+	}
+	if len(keys) != 3 {
+		t.Fatalf("got %d distinct bucket keys, want 3: %v", len(keys), keys)
+	}
+	if bucketKey("/login", perMinute, "192.0.2.1") == bucketKey("/register", perMinute, "192.0.2.1") {
+		t.Fatal("routes share a bucket")
+	}
+}
+
+// failingStore stands in for a store whose backend is down
+type failingStore struct{}
+
+func (failingStore) Take(string, models.RateLimit) (bool, float64, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func (failingStore) Purge() error { return nil }
+
+func TestRateLimitMiddlewareResponses(t *testing.T) {
+	accountLimit := models.RateLimit{Key: models.RateLimitByAccount, Requests: 1, Per: time.Minute}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	request := func(handler http.Handler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	handler := RateLimitMiddleware(NewLimiter(NewMemoryStore(), nil), "/login", accountLimit)(ok)
+	rec := request(handler, `{"email":"alice@example.com"}`)
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" || rec.Header().Get("RateLimit-Reset") != "60" {
+		t.Fatalf("first request: got %d with headers %v", rec.Code, rec.Header())
+	}
+	rec = request(handler, `{"email":"alice@example.com"}`)
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "rate limit exceeded") {
+		t.Fatalf("second request: got %d %s, want 429", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %s, want 60", got)
+	}
+
+	// Requests without an account identifier aren't counted by account
+	for range 3 {
+		if rec := request(handler, `{}`); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("no account: got %d with headers %v", rec.Code, rec.Header())
+		}
+	}
+
+	// A store outage lets requests through
+	handler = RateLimitMiddleware(NewLimiter(failingStore{}, nil), "/login", accountLimit)(ok)
+	if rec := request(handler, `{"email":"alice@example.com"}`); rec.Code != http.StatusOK {
+		t.Errorf("store outage: got %d, want 200", rec.Code)
+	}
+}
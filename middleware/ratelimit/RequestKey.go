@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxBodyPeek bounds how much of the request body is read to find the account identifier
+const maxBodyPeek = 64 << 10
+
+// requestKey returns what the request is counted by for the given kind of limit, or false
+// when the request doesn't carry it (e.g. no API key), in which case the limit doesn't apply.
+// Account identifiers and API keys are hashed so they are never stored in the clear.
+func (l *Limiter) requestKey(r *http.Request, kind string) (string, bool) {
+	switch kind {
+	case models.RateLimitByIP:
+		return ClientIP(r, l.TrustedProxies), true
+	case models.RateLimitByAccount:
+		account := accountIdentifier(r)
+		if account == "" {
+			return "", false
+		}
+		return secure.HashToken(account), true
+	case models.RateLimitByAPIKey:
+		scheme, apiKey, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || scheme != "ApiKey" || apiKey == "" {
+			return "", false
+		}
+		return secure.HashToken(apiKey), true
+	}
+	return "", false
+}
+
+// accountIdentifier reads the "email" field of a JSON or form encoded body. The body is
+// put back so the handler can still read all of it.
+func accountIdentifier(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(r.Body, maxBodyPeek))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var email string
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		form, _ := url.ParseQuery(string(peeked))
+		email = form.Get("email")
+	} else {
+		// A body that isn't JSON simply has no account identifier
+		var body struct {
+			Email string `json:"email"`
+		}
+		_ = json.Unmarshal(peeked, &body)
+		email = body.Email
+	}
+
+	return strings.ToLower(strings.TrimSpace(email))
+}
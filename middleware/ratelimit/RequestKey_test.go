@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestKey(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), nil)
+	account := secure.HashToken("alice@example.com")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		auth        string
+		kind        string
+		want        string // "" when the limit doesn't apply
+	}{
+		{"ip", "", "", "", models.RateLimitByIP, "192.0.2.1"},
+		{"json account", "application/json", `{"email":" Alice@Example.com ","password":"x"}`, "", models.RateLimitByAccount, account},
+		{"form account", "application/x-www-form-urlencoded", "email=alice%40example.com&password=x", "", models.RateLimitByAccount, account},
+		{"no account", "application/json", `{"refresh_token":"x"}`, "", models.RateLimitByAccount, ""},
+		{"body isn't JSON", "text/plain", "alice@example.com", "", models.RateLimitByAccount, ""},
+		{"api key", "", "", "ApiKey gak_secret", models.RateLimitByAPIKey, secure.HashToken("gak_secret")},
+		{"bearer token isn't an api key", "", "", "Bearer token", models.RateLimitByAPIKey, ""},
+		{"unknown kind", "", "", "", "user_agent", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(tt.body))
+		req.RemoteAddr = "192.0.2.1:1234"
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+
+		key, ok := limiter.requestKey(req, tt.kind)
+		if ok != (tt.want != "") || key != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, key, ok, tt.want)
+		}
+
+		// The handler still reads the whole body
+		if body, _ := io.ReadAll(req.Body); string(body) != tt.body {
+			t.Errorf("%s: body after peeking = %q, want %q", tt.name, body, tt.body)
+		}
+	}
+}
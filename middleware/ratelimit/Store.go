@@ -0,0 +1,11 @@
+package ratelimit
+
+import "go-auth/models"
+
+// Store keeps one token bucket per key. Take refills the bucket for the time elapsed since
+// the last request, then removes a token if one is left. remaining is the (fractional)
+// number of tokens left afterwards.
+type Store interface {
+	Take(key string, limit models.RateLimit) (allowed bool, remaining float64, err error)
+	Purge() error
+}
@@ -5,6 +5,12 @@ type UpdateRoleRequest struct {
 	Role string `json:"role"`
 }
 
+// ImpersonateUserRequest is the optional payload for impersonating a user
+type ImpersonateUserRequest struct {
+	Audience string `json:"audience"` // Must be one of the configured audiences
+	Reason   string `json:"reason"`   // Recorded in the audit log
+}
+
 // GetAllUsersResponse is the response for getting all users
 type GetAllUsersResponse struct {
 	Total int     `json:"total"`
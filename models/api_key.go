@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognise and scan for
+const APIKeyPrefix = "gak_"
+
+// APIKey is a long-lived, hashed credential that acts as its owner with a restricted role and scopes
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // First characters of the key, for identifying it in listings
+	Role       string     `json:"role,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(time.Now()))
+}
+
+// CreateAPIKeyRequest is the payload for creating an API key.
+// Role may only be the owner's own role; leave it empty for a scopes-only key.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse is returned once after creating an API key.
+// The key itself is never shown again.
+type CreateAPIKeyResponse struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
+
+// GetAPIKeysResponse is the response for listing API keys
+type GetAPIKeysResponse struct {
+	Total   int       `json:"total"`
+	APIKeys []*APIKey `json:"api_keys"`
+}
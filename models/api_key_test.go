@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPIKeyIsActive(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := map[string]struct {
+		key  APIKey
+		want bool
+	}{
+		"no expiry":     {APIKey{}, true},
+		"not expired":   {APIKey{ExpiresAt: &future}, true},
+		"expired":       {APIKey{ExpiresAt: &past}, false},
+		"revoked":       {APIKey{RevokedAt: &past}, false},
+		"revoked early": {APIKey{ExpiresAt: &future, RevokedAt: &past}, false},
+	}
+	for name, tt := range tests {
+		if got := tt.key.IsActive(); got != tt.want {
+			t.Errorf("%s: IsActive() = %v, want %v", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Audit log actions
+const (
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonatedRequest  = "impersonation.request"
+
+	AuditServiceAccountCreated  = "service_account.created"
+	AuditServiceAccountRotated  = "service_account.rotated"
+	AuditServiceAccountDisabled = "service_account.disabled"
+
+	AuditAPIKeyCreated = "api_key.created"
+	AuditAPIKeyRevoked = "api_key.revoked"
+
+	AuditMFAEnabled               = "mfa.enabled"
+	AuditMFADisabled              = "mfa.disabled"
+	AuditMFAReset                 = "mfa.reset" // An admin removed a user's MFA
+	AuditRecoveryCodesRegenerated = "mfa.recovery_codes_regenerated"
+
+	AuditWebAuthnCredentialAdded   = "webauthn.credential_added"
+	AuditWebAuthnCredentialRemoved = "webauthn.credential_removed"
+)
+
+// AuditLogEntry is a recorded security-relevant event
+type AuditLogEntry struct {
+	ID        string            `json:"id"`
+	Action    string            `json:"action"`
+	ActorID   string            `json:"actor_id,omitempty"`   // Who performed the action
+	SubjectID string            `json:"subject_id,omitempty"` // Who the action was performed on or as
+	Details   map[string]string `json:"details,omitempty"`
+	IPAddress string            `json:"ip_address,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// AuditLogFilter narrows an audit log query; empty fields match everything
+type AuditLogFilter struct {
+	ActorID   string
+	SubjectID string
+	Action    string
+	Limit     int
+}
+
+// GetAuditLogResponse is the response for reading the audit log
+type GetAuditLogResponse struct {
+	Total   int              `json:"total"`
+	Entries []*AuditLogEntry `json:"entries"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LockoutPolicy controls per-account login throttling. After DelayAfter consecutive failed
+// logins each further attempt has to wait DelayBase, doubling per failure up to DelayMax;
+// after Threshold failures the account is locked for Duration.
+type LockoutPolicy struct {
+	Threshold  int           // Failed attempts before the account is locked; 0 disables lockout
+	Duration   time.Duration // How long a lockout lasts
+	DelayAfter int           // Failed attempts before delays start; 0 disables delays
+	DelayBase  time.Duration
+	DelayMax   time.Duration
+}
+
+// Login throttling error codes, returned as "code" next to "error"
+const (
+	LoginErrorAccountLocked = "account_locked"  // 423, the account is locked until Retry-After
+	LoginErrorThrottled     = "login_throttled" // 429, wait Retry-After before the next attempt
+)
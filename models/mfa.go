@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Authentication methods (RFC 8176 amr values) and the resulting assurance level (acr)
+const (
+	AMRPassword    = "pwd"
+	AMROTP         = "otp"
+	AMRHardwareKey = "hwk"
+	AMRMFA         = "mfa"
+	// AMRRecoveryCode isn't registered in RFC 8176; it marks a login finished with a one-time recovery code
+	AMRRecoveryCode = "rc"
+
+	ACRSingleFactor = "aal1"
+	ACRMultiFactor  = "aal2"
+)
+
+// PasswordAMR is the amr claim of a password login without a second factor.
+// WebAuthnAMR is a passkey login; the key is possessed and user verification is required.
+var (
+	PasswordAMR = []string{AMRPassword}
+	WebAuthnAMR = []string{AMRHardwareKey, AMRMFA}
+)
+
+// SecondFactorAMR returns the amr claim of a password login completed with the given second factor
+func SecondFactorAMR(method string) []string {
+	return []string{AMRPassword, method, AMRMFA}
+}
+
+// ACRForAMR returns the assurance level reached by the authentication methods
+func ACRForAMR(amr []string) string {
+	for _, method := range amr {
+		if method == AMRMFA {
+			return ACRMultiFactor
+		}
+	}
+	return ACRSingleFactor
+}
+
+// RecoveryCodeCount is how many one-time recovery codes are issued when MFA is enabled
+const RecoveryCodeCount = 10
+
+// UserMFA is a user's TOTP enrollment. It only protects logins once confirmed.
+type UserMFA struct {
+	UserID       string
+	TOTPSecret   string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 // Time step of the last accepted code, so codes can't be replayed
+	CreatedAt    time.Time
+}
+
+// Enabled reports whether the enrollment has been confirmed
+func (m *UserMFA) Enabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+// MFAStatusResponse describes the current user's MFA setup
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollmentResponse carries a new TOTP secret, for manual entry or as an otpauth:// URI
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest carries a code from the authenticator app (or a recovery code where accepted)
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// DisableMFARequest is the payload for turning MFA off; both factors are required
+type DisableMFARequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RecoveryCodesResponse returns freshly issued recovery codes. They are never shown again.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is returned by /login instead of tokens when the user has MFA enabled
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// LoginMFARequest completes a login with the challenge token and a TOTP or recovery code
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
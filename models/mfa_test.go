@@ -0,0 +1,21 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSecondFactorAMR(t *testing.T) {
+	for _, method := range []string{AMROTP, AMRRecoveryCode} {
+		amr := SecondFactorAMR(method)
+		if !slices.Equal(amr, []string{AMRPassword, method, AMRMFA}) {
+			t.Fatalf("%s: got amr %v", method, amr)
+		}
+		if acr := ACRForAMR(amr); acr != ACRMultiFactor {
+			t.Fatalf("%s: got acr %q, want %q", method, acr, ACRMultiFactor)
+		}
+	}
+	if acr := ACRForAMR(PasswordAMR); acr != ACRSingleFactor {
+		t.Fatalf("password only: got acr %q, want %q", acr, ACRSingleFactor)
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import "time"
+
+// OAuthClient is an application registered to use the OAuth 2.0 flows
+type OAuthClient struct {
+	ID            string    `json:"id"`
+	ClientID      string    `json:"client_id"`
+	SecretHash    string    `json:"-"` // Empty for public clients (SPAs, native apps)
+	Name          string    `json:"name"`
+	RedirectURIs  []string  `json:"redirect_uris"`
+	AllowedScopes []string  `json:"allowed_scopes"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IsPublic reports whether the client cannot keep a secret
+func (c *OAuthClient) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+// AuthorizationCode is a stored (hashed) OAuth authorization code bound to a PKCE challenge
+type AuthorizationCode struct {
+	ClientID            string
+	UserID              string
+	RedirectURI         string
+	RedirectURISupplied bool // The authorization request named redirect_uri, so the token request must repeat it
+	Scope               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string    // OpenID Connect nonce, echoed in the ID token
+	AuthTime            time.Time // When the user authenticated at the authorization endpoint
+	AMR                 []string  // How the user authenticated at the authorization endpoint
+	ExpiresAt           time.Time
+}
+
+// Device authorization (RFC 8628) states
+const (
+	DeviceCodePending  = "pending"
+	DeviceCodeApproved = "approved"
+	DeviceCodeDenied   = "denied"
+	DeviceCodeConsumed = "consumed"
+)
+
+// DeviceCode is a stored (hashed) device code and the user code shown to the user
+type DeviceCode struct {
+	ClientID     string
+	UserCode     string
+	UserID       string    // Set once a user approves or denies the request
+	AMR          []string  // How the approving user authenticated
+	AuthTime     time.Time // When the approving user authenticated
+	Scope        string
+	Status       string
+	Interval     int // Minimum seconds between polls
+	ExpiresAt    time.Time
+	LastPolledAt *time.Time
+}
+
+// CreateOAuthClientRequest is the payload for registering an OAuth client
+type CreateOAuthClientRequest struct {
+	Name          string   `json:"name"`
+	RedirectURIs  []string `json:"redirect_uris"`
+	AllowedScopes []string `json:"allowed_scopes"`
+	Public        bool     `json:"public"`
+}
+
+// CreateOAuthClientResponse is returned once after registering a client.
+// The client secret is never shown again.
+type CreateOAuthClientResponse struct {
+	Client       *OAuthClient `json:"client"`
+	ClientSecret string       `json:"client_secret,omitempty"`
+}
+
+// GetAllOAuthClientsResponse is the response for listing OAuth clients
+type GetAllOAuthClientsResponse struct {
+	Total   int            `json:"total"`
+	Clients []*OAuthClient `json:"clients"`
+}
+
+// OAuthTokenResponse is the RFC 6749 token endpoint response
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// DeviceAuthorizationResponse is the RFC 8628 device authorization response
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// OAuthErrorResponse is the RFC 6749 error response
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OpenIDConfiguration is the OpenID Connect discovery document
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	ACRValuesSupported                []string `json:"acr_values_supported"`
+}
+
+// UserInfoResponse is the OpenID Connect userinfo response; claims depend on the granted scopes
+type UserInfoResponse struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// PasswordPolicy is the set of rules new passwords must satisfy. It is also
+// served by GET /password-policy so frontends can show the rules.
+type PasswordPolicy struct {
+	MinLength            int  `json:"min_length"` // In characters
+	MaxLength            int  `json:"max_length"` // In bytes; bcrypt ignores everything after 72
+	RequireUppercase     bool `json:"require_uppercase"`
+	RequireLowercase     bool `json:"require_lowercase"`
+	RequireDigit         bool `json:"require_digit"`
+	RequireSymbol        bool `json:"require_symbol"`
+	DisallowPersonalInfo bool `json:"disallow_personal_info"` // Reject passwords containing the username or email
+	RejectBreached       bool `json:"reject_breached"`        // Reject passwords found in the breach corpus
+	HistoryDepth         int  `json:"history_depth"`          // Last N passwords (including the current one) that can't be reused
+}
+
+// FieldError describes why one request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is returned when one or more fields fail validation
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
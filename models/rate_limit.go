@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Rate limit keys: what a limit counts requests by
+const (
+	RateLimitByIP      = "ip"      // Client IP, honouring X-Forwarded-For from trusted proxies
+	RateLimitByAccount = "account" // The "email" field of the request body
+	RateLimitByAPIKey  = "api_key" // The key in an "Authorization: ApiKey <key>" header
+)
+
+// RateLimit allows Requests per Per for every distinct key. It is a token bucket: a burst of
+// up to Requests is allowed, refilled continuously at Requests/Per.
+type RateLimit struct {
+	Key      string
+	Requests int
+	Per      time.Duration
+}
+
+// RefillRate returns how many requests the bucket regains per second
+func (l RateLimit) RefillRate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ServiceAccount is a machine principal that authenticates with the client_credentials grant
+type ServiceAccount struct {
+	ID           string     `json:"id"`
+	ClientID     string     `json:"client_id"`
+	SecretHash   string     `json:"-"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role,omitempty"`
+	Scopes       []string   `json:"scopes"`
+	TokenVersion int        `json:"-"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsDisabled reports whether the service account can no longer get tokens
+func (a *ServiceAccount) IsDisabled() bool {
+	return a.DisabledAt != nil
+}
+
+// CreateServiceAccountRequest is the payload for creating a service account
+type CreateServiceAccountRequest struct {
+	Name   string   `json:"name"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+}
+
+// ServiceAccountCredentialsResponse is returned once when a service account is created
+// or its secret is rotated. The client secret is never shown again.
+type ServiceAccountCredentialsResponse struct {
+	ServiceAccount *ServiceAccount `json:"service_account"`
+	ClientSecret   string          `json:"client_secret"`
+}
+
+// GetAllServiceAccountsResponse is the response for listing service accounts
+type GetAllServiceAccountsResponse struct {
+	Total           int               `json:"total"`
+	ServiceAccounts []*ServiceAccount `json:"service_accounts"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,21 +13,148 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+
+	// PasswordChangeToken is a restricted token only accepted by /change-password
+	PasswordChangeToken TokenType = "password_change"
+
+	// MFAChallengeToken proves the password was checked; it is only accepted by /login/mfa
+	MFAChallengeToken TokenType = "mfa_challenge"
+
+	// EmailVerificationToken is sent by email; it is only accepted by /email/verify
+	EmailVerificationToken TokenType = "email_verification"
+)
+
+// IsRestricted reports whether tokens of this type are single-purpose tokens only the auth
+// service accepts. They carry no role or audience and introspection never reports them active.
+func (t TokenType) IsRestricted() bool {
+	return t != AccessToken && t != RefreshToken
+}
+
+// PrincipalType distinguishes human users from machine principals
+type PrincipalType string
+
+const (
+	PrincipalUser    PrincipalType = "user"
+	PrincipalService PrincipalType = "service"
 )
 
 // TokenDuration constants
 const (
 	AccessTokenDuration  = 15 * time.Minute
 	RefreshTokenDuration = 7 * 24 * time.Hour // 7 days
+
+	AuthorizationCodeDuration = 5 * time.Minute
+	DeviceCodeDuration        = 10 * time.Minute
+	DeviceCodePollInterval    = 5 * time.Second
+	IDTokenDuration           = 15 * time.Minute
+
+	ImpersonationTokenDuration  = 10 * time.Minute // Never paired with a refresh token
+	PasswordChangeTokenDuration = 10 * time.Minute
+	MFAChallengeTokenDuration   = 5 * time.Minute
+	PasswordResetTokenDuration  = 30 * time.Minute
+
+	EmailVerificationTokenDuration = 24 * time.Hour
 )
 
 // Claims represents the JWT claims
 type Claims struct {
-	UserID    string    `json:"user_id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	TokenType TokenType `json:"token_type"`
+	UserID        string           `json:"user_id"`
+	Username      string           `json:"username"`
+	Email         string           `json:"email"`
+	Role          string           `json:"role,omitempty"`
+	TokenType     TokenType        `json:"token_type"`
+	Principal     PrincipalType    `json:"principal_type,omitempty"` // "service" for service accounts
+	TokenVersion  int              `json:"ver"`                      // User's token_version when issued
+	Scope         string           `json:"scope,omitempty"`
+	ClientID      string           `json:"client_id,omitempty"`      // OAuth client the token was issued to
+	AuthTime      *jwt.NumericDate `json:"auth_time,omitempty"`      // When the user last authenticated
+	Act           *Actor           `json:"act,omitempty"`            // Set when an admin is impersonating the user
+	APIKeyID      string           `json:"api_key_id,omitempty"`     // Set when the request was authenticated with an API key
+	AMR           []string         `json:"amr,omitempty"`            // How the user authenticated, e.g. ["pwd", "otp", "mfa"]
+	ACR           string           `json:"acr,omitempty"`            // Assurance level reached: aal1 or aal2
+	EmailVerified *bool            `json:"email_verified,omitempty"` // False until the user verifies their email
+	LoginAudience []string         `json:"login_aud,omitempty"`      // Audience asked for at login, carried by restricted tokens instead of aud
+	jwt.RegisteredClaims
+}
+
+// SessionOptions returns the per-session options of the token, so tokens issued later in the
+// same session keep its audience and how the user authenticated. Restricted tokens carry the
+// audience asked for at login instead of their own.
+func (c *Claims) SessionOptions() TokenOptions {
+	opts := TokenOptions{
+		Audience: c.Audience,
+		Scope:    c.Scope,
+		ClientID: c.ClientID,
+		AMR:      c.AMR,
+	}
+	if c.TokenType.IsRestricted() {
+		opts.Audience = c.LoginAudience
+	}
+	if c.AuthTime != nil {
+		opts.AuthTime = c.AuthTime.Time
+	}
+	return opts
+}
+
+// IsService reports whether the token was issued to a service account rather than a user
+func (c *Claims) IsService() bool {
+	return c.Principal == PrincipalService
+}
+
+// IsAPIKey reports whether the claims come from an API key rather than a signed token
+func (c *Claims) IsAPIKey() bool {
+	return c.APIKeyID != ""
+}
+
+// IsImpersonated reports whether the token was issued to an admin acting as the user
+func (c *Claims) IsImpersonated() bool {
+	return c.Act != nil
+}
+
+// IsOAuthClient reports whether the token was issued to an OAuth client on the user's behalf.
+// Such tokens are only good for the resource endpoints their scopes cover.
+func (c *Claims) IsOAuthClient() bool {
+	return c.ClientID != "" && !c.IsService()
+}
+
+// IsLoginSession reports whether the token belongs to one of the user's own login sessions,
+// rather than an API key, impersonation, service account or OAuth client acting for them
+func (c *Claims) IsLoginSession() bool {
+	return !c.IsAPIKey() && !c.IsImpersonated() && !c.IsService() && c.ClientID == ""
+}
+
+// HasScope reports whether the token was granted scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor identifies the party acting on behalf of the token's subject (RFC 8693 act claim)
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+// ImpersonationResponse carries the access token an admin uses to act as a user
+type ImpersonationResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// IDTokenClaims represents the claims of an OpenID Connect ID token
+type IDTokenClaims struct {
+	Nonce             string           `json:"nonce,omitempty"`
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+	Email             string           `json:"email,omitempty"`
+	EmailVerified     *bool            `json:"email_verified,omitempty"`
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	AMR               []string         `json:"amr,omitempty"`
+	ACR               string           `json:"acr,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -35,4 +163,54 @@ type Token struct {
 	Value     string    `json:"value"`
 	Type      TokenType `json:"type"`
 	ExpiresAt time.Time `json:"expires_at"`
-}
\ No newline at end of file
+}
+
+// TokenOptions carries the per-session claims shared by an access/refresh token pair.
+// They are copied from the refresh token whenever it is rotated.
+type TokenOptions struct {
+	Audience []string // Empty uses the configured default audiences
+	Scope    string
+	ClientID string
+	AuthTime time.Time // Zero omits auth_time
+	AMR      []string  // Authentication methods; acr is derived from them. Empty omits both
+}
+
+// TokenPair is a freshly issued access token and refresh token
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenRecord is a stored (hashed) refresh token.
+// Every token descending from the same login shares a FamilyID.
+type RefreshTokenRecord struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IntrospectionResponse is the RFC 7662 token introspection response.
+// Inactive tokens only carry "active": false.
+type IntrospectionResponse struct {
+	Active    bool          `json:"active"`
+	Issuer    string        `json:"iss,omitempty"`
+	Subject   string        `json:"sub,omitempty"`
+	Audience  []string      `json:"aud,omitempty"`
+	Username  string        `json:"username,omitempty"`
+	Email     string        `json:"email,omitempty"`
+	Role      string        `json:"role,omitempty"`
+	TokenType string        `json:"token_type,omitempty"`
+	TokenUse  TokenType     `json:"token_use,omitempty"`
+	ExpiresAt int64         `json:"exp,omitempty"`
+	IssuedAt  int64         `json:"iat,omitempty"`
+	NotBefore int64         `json:"nbf,omitempty"`
+	JTI       string        `json:"jti,omitempty"`
+	Scope     string        `json:"scope,omitempty"`
+	ClientID  string        `json:"client_id,omitempty"`
+	Principal PrincipalType `json:"principal_type,omitempty"`
+	Act       *Actor        `json:"act,omitempty"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSessionOptions(t *testing.T) {
+	authTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	access := &Claims{
+		TokenType: AccessToken,
+		Scope:     "openid",
+		ClientID:  "client",
+		AuthTime:  jwt.NewNumericDate(authTime),
+		AMR:       SecondFactorAMR(AMROTP),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience: jwt.ClaimStrings{"api"},
+		},
+	}
+	opts := access.SessionOptions()
+	if !slices.Equal(opts.Audience, []string{"api"}) || opts.Scope != "openid" || opts.ClientID != "client" ||
+		!opts.AuthTime.Equal(authTime) || !slices.Equal(opts.AMR, access.AMR) {
+		t.Fatalf("access token: got %+v", opts)
+	}
+
+	// Restricted tokens have no aud of their own
+	passwordChange := &Claims{
+		TokenType:     PasswordChangeToken,
+		LoginAudience: []string{"api"},
+		AMR:           PasswordAMR,
+	}
+	opts = passwordChange.SessionOptions()
+	if !slices.Equal(opts.Audience, []string{"api"}) || !opts.AuthTime.IsZero() || !slices.Equal(opts.AMR, PasswordAMR) {
+		t.Fatalf("password change token: got %+v", opts)
+	}
+}
+
+func TestClientTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      *Claims
+		wantClient  bool
+		wantSession bool
+	}{
+		{"login session", &Claims{}, false, true},
+		{"oauth client", &Claims{ClientID: "client", Scope: "openid"}, true, false},
+		{"service account", &Claims{ClientID: "client", Principal: PrincipalService}, false, false},
+		{"api key", &Claims{APIKeyID: "key"}, false, false},
+		{"impersonation", &Claims{Act: &Actor{Subject: "admin"}}, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.claims.IsOAuthClient(); got != tt.wantClient {
+			t.Errorf("%s: IsOAuthClient() = %v, want %v", tt.name, got, tt.wantClient)
+		}
+		if got := tt.claims.IsLoginSession(); got != tt.wantSession {
+			t.Errorf("%s: IsLoginSession() = %v, want %v", tt.name, got, tt.wantSession)
+		}
+	}
+
+	claims := &Claims{Scope: "openid profile"}
+	for scope, want := range map[string]bool{"openid": true, "profile": true, "email": false, "open": false, "": false} {
+		if got := claims.HasScope(scope); got != want {
+			t.Errorf("HasScope(%q) = %v, want %v", scope, got, want)
+		}
+	}
+}
@@ -4,16 +4,37 @@ import "time"
 
 // User represents a user in the system
 type User struct {
-	ID        string     `json:"id"`
-	Username  string     `json:"username"`
-	Email     string     `json:"email"`
-	Password  string     `json:"-"` // Never expose password in JSON
-	Role      string     `json:"role"`
-	DeletedAt *time.Time `json:"deleted_at"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID                  string     `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	Password            string     `json:"-"` // Never expose password in JSON
+	Role                string     `json:"role"`
+	TokenVersion        int        `json:"-"` // Bumped to invalidate every token issued so far
+	MustChangePassword  bool       `json:"must_change_password"`
+	PasswordChangedAt   time.Time  `json:"-"` // Used for the per-role maximum password age
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LastFailedLoginAt   *time.Time `json:"last_failed_login_at"`
+	LockedUntil         *time.Time `json:"locked_until"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	DeletedAt           *time.Time `json:"deleted_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
+// EmailVerified reports whether the user has confirmed they own their email address
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Email verification modes (EMAIL_VERIFICATION_MODE)
+const (
+	EmailVerificationOptional = "optional" // Unverified users sign in; their tokens carry email_verified=false
+	EmailVerificationRequired = "required" // Unverified users can't sign in
+)
+
+// LoginErrorEmailNotVerified is returned as "code" (403) when unverified users can't sign in
+const LoginErrorEmailNotVerified = "email_not_verified"
+
 // Role represents a role in the system
 type Role struct {
 	ID   string `json:"id"`
@@ -27,10 +48,12 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 }
 
-// LoginRequest is the payload for user login
+// LoginRequest is the payload for user login.
+// Audience optionally narrows the tokens to one of the configured JWT_AUDIENCE values.
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Audience string `json:"audience"`
 }
 
 // ChangePasswordRequest is the payload for changing password
@@ -39,6 +62,50 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password"`
 }
 
+// Reasons a user has to change their password before getting a full token pair
+const (
+	PasswordChangeReasonRequired = "must_change_password"
+	PasswordChangeReasonExpired  = "password_expired"
+)
+
+// PasswordChangeRequiredResponse is returned by login instead of an AuthResponse when the
+// user must change their password first. The token is only accepted by /change-password.
+type PasswordChangeRequiredResponse struct {
+	PasswordChangeRequired bool   `json:"password_change_required"`
+	Reason                 string `json:"reason"`
+	PasswordChangeToken    string `json:"password_change_token"`
+	ExpiresIn              int    `json:"expires_in"`
+	User                   User   `json:"user"`
+}
+
+// ForgotPasswordRequest is the payload for requesting a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest is the payload for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// VerifyEmailRequest is the payload for confirming an email address with the emailed token
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// ResendVerificationRequest is the payload for requesting another verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// EmailVerificationRequiredResponse is returned by register instead of an AuthResponse when
+// users must verify their email before signing in
+type EmailVerificationRequiredResponse struct {
+	EmailVerificationRequired bool `json:"email_verification_required"`
+	User                      User `json:"user"`
+}
+
 // AuthResponse is returned after successful login/register
 type AuthResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -55,4 +122,10 @@ type RefreshTokenRequest struct {
 type UpdateUserRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
-}
\ No newline at end of file
+}
+
+// LogoutRequest is the optional payload for logging out.
+// When a refresh token is given, its whole token family is revoked as well.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
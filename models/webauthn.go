@@ -0,0 +1,163 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// WebAuthnChallengeDuration is how long a registration or login ceremony may take
+const WebAuthnChallengeDuration = 5 * time.Minute
+
+// WebAuthn ceremonies a challenge can be used for
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+)
+
+// Base64URLBytes is binary data encoded as unpadded base64url in JSON, the encoding
+// browsers use for WebAuthn options and credentials
+type Base64URLBytes []byte
+
+// MarshalJSON encodes the bytes as an unpadded base64url string
+func (b Base64URLBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes a base64url string, with or without padding
+func (b *Base64URLBytes) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// WebAuthnCredential is a passkey or security key registered by a user
+type WebAuthnCredential struct {
+	ID         string     `json:"id"` // Credential ID, base64url encoded
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	PublicKey  []byte     `json:"-"` // COSE_Key from the authenticator
+	SignCount  uint32     `json:"sign_count"`
+	Transports []string   `json:"transports"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// WebAuthnRelyingParty identifies this service to the authenticator
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// WebAuthnUser identifies the account a credential is created for
+type WebAuthnUser struct {
+	ID          Base64URLBytes `json:"id"` // User handle, returned by the authenticator on login
+	Name        string         `json:"name"`
+	DisplayName string         `json:"displayName"`
+}
+
+// WebAuthnCredentialParameter is a credential type and COSE algorithm the service accepts
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// WebAuthnCredentialDescriptor refers to an existing credential
+type WebAuthnCredentialDescriptor struct {
+	Type       string         `json:"type"`
+	ID         Base64URLBytes `json:"id"`
+	Transports []string       `json:"transports,omitempty"`
+}
+
+// WebAuthnAuthenticatorSelection states which authenticators may be used
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// WebAuthnCreationOptions are the PublicKeyCredentialCreationOptions for navigator.credentials.create()
+type WebAuthnCreationOptions struct {
+	Challenge              Base64URLBytes                 `json:"challenge"`
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUser                   `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                            `json:"timeout"` // Milliseconds
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions are the PublicKeyCredentialRequestOptions for navigator.credentials.get().
+// No credentials are listed, so the authenticator offers its discoverable credentials.
+type WebAuthnRequestOptions struct {
+	Challenge        Base64URLBytes `json:"challenge"`
+	Timeout          int            `json:"timeout"` // Milliseconds
+	RPID             string         `json:"rpId"`
+	UserVerification string         `json:"userVerification"`
+}
+
+// WebAuthnCreationOptionsResponse starts a registration ceremony
+type WebAuthnCreationOptionsResponse struct {
+	PublicKey WebAuthnCreationOptions `json:"publicKey"`
+}
+
+// WebAuthnRequestOptionsResponse starts a login ceremony
+type WebAuthnRequestOptionsResponse struct {
+	PublicKey WebAuthnRequestOptions `json:"publicKey"`
+}
+
+// WebAuthnAttestationResponse is the authenticator's response to navigator.credentials.create()
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    Base64URLBytes `json:"clientDataJSON"`
+	AttestationObject Base64URLBytes `json:"attestationObject"`
+	Transports        []string       `json:"transports"`
+}
+
+// WebAuthnAssertionResponse is the authenticator's response to navigator.credentials.get()
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    Base64URLBytes `json:"clientDataJSON"`
+	AuthenticatorData Base64URLBytes `json:"authenticatorData"`
+	Signature         Base64URLBytes `json:"signature"`
+	UserHandle        Base64URLBytes `json:"userHandle"`
+}
+
+// WebAuthnRegistrationCredential is a new PublicKeyCredential, as serialized by its toJSON()
+type WebAuthnRegistrationCredential struct {
+	RawID    Base64URLBytes              `json:"rawId"`
+	Type     string                      `json:"type"`
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+// WebAuthnLoginCredential is an asserted PublicKeyCredential, as serialized by its toJSON()
+type WebAuthnLoginCredential struct {
+	RawID    Base64URLBytes            `json:"rawId"`
+	Type     string                    `json:"type"`
+	Response WebAuthnAssertionResponse `json:"response"`
+}
+
+// FinishWebAuthnRegistrationRequest completes a registration ceremony
+type FinishWebAuthnRegistrationRequest struct {
+	Name       string                         `json:"name"`
+	Credential WebAuthnRegistrationCredential `json:"credential"`
+}
+
+// FinishWebAuthnLoginRequest completes a login ceremony
+type FinishWebAuthnLoginRequest struct {
+	Credential WebAuthnLoginCredential `json:"credential"`
+	Audience   string                  `json:"audience,omitempty"` // Optional, see LoginRequest
+}
+
+// GetWebAuthnCredentialsResponse is the response for listing a user's credentials
+type GetWebAuthnCredentialsResponse struct {
+	Total       int                   `json:"total"`
+	Credentials []*WebAuthnCredential `json:"credentials"`
+}
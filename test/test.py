@@ -3,9 +3,16 @@
 import requests
 import json
 import base64
+import hashlib
+import hmac
 import os
+import re
+import secrets
+import struct
+import time
 from pathlib import Path
 from datetime import datetime
+from urllib.parse import urlparse, parse_qs
 from dotenv import load_dotenv
 
 # Load .env from root directory
@@ -16,6 +23,10 @@ load_dotenv(env_path)
 AUTH_SERVICE_URL = os.getenv("AUTH_SERVICE_URL", "http://localhost:8080")
 SUPER_ADMIN_EMAIL = os.getenv("SUPER_ADMIN_EMAIL", "superadmin@web.com")
 SUPER_ADMIN_PASSWORD = os.getenv("SUPER_ADMIN_PASSWORD", "superadminpass123")
+DEFAULT_ROLE = os.getenv("DEFAULT_REGISTRATION_ROLE", "User")
+
+# Suffix for users created by this run, so the suite can be run repeatedly
+RUN_ID = datetime.now().strftime("%Y%m%d%H%M%S")
 
 # Parse ROLES from .env
 try:
@@ -24,6 +35,23 @@ try:
 except json.JSONDecodeError:
     ROLES = ["Super Admin", "User"]
 
+# Issuer and audiences stamped on tokens (empty when not configured)
+JWT_ISSUER = os.getenv("JWT_ISSUER", "")
+try:
+    JWT_AUDIENCE = json.loads(os.getenv("JWT_AUDIENCE", "[]"))
+except json.JSONDecodeError:
+    JWT_AUDIENCE = []
+
+# Introspection clients from .env (client_id -> secret); the introspection tests need one
+try:
+    INTROSPECTION_CLIENTS = json.loads(os.getenv("INTROSPECTION_CLIENTS", "{}"))
+except json.JSONDecodeError:
+    INTROSPECTION_CLIENTS = {}
+
+# Emails are only readable by the suite with MAILER=file; MAILER_FILE_DIR is relative to the repository root
+MAILER = os.getenv("MAILER", "log").lower()
+MAILER_FILE_DIR = Path(__file__).parent.parent / os.getenv("MAILER_FILE_DIR", "mail")
+
 # Color codes for output
 GREEN = '\033[92m'
 RED = '\033[91m'
@@ -66,6 +94,131 @@ def decode_jwt(token):
         print_error(f"Failed to decode token: {e}")
         return None
 
+def decode_jwt_header(token):
+    """Decode the JOSE header of a JWT"""
+    try:
+        header = token.split('.')[0]
+        header += '=' * (-len(header) % 4)
+        return json.loads(base64.urlsafe_b64decode(header))
+    except Exception as e:
+        print_error(f"Failed to decode token header: {e}")
+        return None
+
+def create_test_user(super_admin_token, prefix):
+    """Create a user unique to this run that can sign in right away"""
+    username = f"{prefix}_{RUN_ID}"
+    # Random, so it passes breach screening and the password policy
+    password = f"Tp-{secrets.token_hex(8)}-9"
+    payload = {
+        "username": username,
+        "email": f"{username}@example.com",
+        "password": password,
+        "role": DEFAULT_ROLE,
+        "must_change_password": False
+    }
+    headers = {
+        "Authorization": f"Bearer {super_admin_token}"
+    }
+    response = requests.post(f"{AUTH_SERVICE_URL}/admin/users/create", json=payload, headers=headers)
+    if response.status_code != 201:
+        print_error(f"Failed to create test user {username}: {response.text}")
+        return None
+    data = response.json()
+    return {
+        "id": data["id"],
+        "username": username,
+        "email": data["email"],
+        "password": password
+    }
+
+def login_user(email, password, audience=None):
+    """Log in and return the response body, or None when no token pair was issued"""
+    payload = {"email": email, "password": password}
+    if audience:
+        payload["audience"] = audience
+    response = requests.post(f"{AUTH_SERVICE_URL}/login", json=payload)
+    if response.status_code != 200 or "access_token" not in response.json():
+        print_error(f"Login failed ({response.status_code}): {response.text}")
+        return None
+    return response.json()
+
+def wait_for_email(to, timeout=10):
+    """Return the body of the newest email to this address written by MAILER=file, or None"""
+    deadline = time.time() + timeout
+    while time.time() < deadline:
+        for path in sorted(MAILER_FILE_DIR.glob("*.eml"), reverse=True):
+            header, _, body = path.read_text().partition("\r\n\r\n")
+            if f"\r\nTo: {to}\r\n" in header:
+                path.unlink()
+                return body.replace("\r\n", "\n")
+        time.sleep(0.2)
+    print_error(f"No email to {to} arrived in {MAILER_FILE_DIR}")
+    return None
+
+def token_from_email(body):
+    """Extract the token from an email, whether it links to the frontend or contains the raw token"""
+    link = re.search(r"https?://\S+", body)
+    if link:
+        return parse_qs(urlparse(link.group(0)).query).get("token", [None])[0]
+    token = re.search(r"Use this token to .*:\n\n(\S+)", body)
+    return token.group(1) if token else None
+
+def totp_code(secret, step):
+    """RFC 6238 code (SHA-1, 6 digits) for a base32 secret and time step"""
+    key = base64.b32decode(secret.upper() + "=" * (-len(secret) % 8))
+    digest = hmac.new(key, struct.pack(">Q", step), hashlib.sha1).digest()
+    offset = digest[-1] & 0x0F
+    value = struct.unpack(">I", digest[offset:offset + 4])[0] & 0x7FFFFFFF
+    return f"{value % 1000000:06d}"
+
+def create_oauth_client(super_admin_token, prefix, redirect_uri, scopes):
+    """Register a public OAuth client unique to this run; returns its client_id"""
+    payload = {
+        "name": f"{prefix}_{RUN_ID}",
+        "redirect_uris": [redirect_uri],
+        "allowed_scopes": scopes,
+        "public": True
+    }
+    headers = {
+        "Authorization": f"Bearer {super_admin_token}"
+    }
+    response = requests.post(f"{AUTH_SERVICE_URL}/admin/oauth/clients/create", json=payload, headers=headers)
+    if response.status_code != 201:
+        print_error(f"Failed to create OAuth client: {response.text}")
+        return None
+    return response.json()["client"]["client_id"]
+
+def delete_oauth_client(super_admin_token, client_id):
+    headers = {
+        "Authorization": f"Bearer {super_admin_token}"
+    }
+    requests.delete(f"{AUTH_SERVICE_URL}/admin/oauth/clients/delete/{client_id}", headers=headers)
+
+def pkce_pair():
+    """Return a PKCE code verifier and its S256 challenge"""
+    verifier = secrets.token_urlsafe(48)
+    digest = hashlib.sha256(verifier.encode()).digest()
+    return verifier, base64.urlsafe_b64encode(digest).rstrip(b"=").decode()
+
+def authorize(client_id, redirect_uri, user, code_challenge, scope, decision="allow", nonce=""):
+    """Sign in on the authorization endpoint; returns the response and the redirect's query parameters"""
+    response = requests.post(f"{AUTH_SERVICE_URL}/oauth/authorize", data={
+        "client_id": client_id,
+        "redirect_uri": redirect_uri,
+        "response_type": "code",
+        "scope": scope,
+        "state": f"state-{RUN_ID}",
+        "code_challenge": code_challenge,
+        "code_challenge_method": "S256",
+        "email": user["email"],
+        "password": user["password"],
+        "decision": decision,
+        "nonce": nonce
+    }, allow_redirects=False)
+    location = response.headers.get("Location", "")
+    params = {name: values[0] for name, values in parse_qs(urlparse(location).query).items()}
+    return response, params
+
 def test_health_check():
     print_header("Testing Health Check")
     try:
@@ -273,15 +426,17 @@ def test_change_password(access_token, old_password, new_password, role_name):
         )
         
         if response.status_code == 200:
+            data = response.json()
             print_success("Password changed successfully")
-            print(f"Response: {response.json()}")
-            return True
+            print(f"Response: {data.get('message')}")
+            # Old tokens are invalidated, the response carries a fresh pair
+            return data
         else:
             print_error(f"Change password failed: {response.text}")
-            return False
+            return None
     except Exception as e:
         print_error(f"Change password error: {e}")
-        return False
+        return None
 
 def test_refresh_token(refresh_token, role_name):
     print_header(f"Testing Token Refresh - {role_name}")
@@ -340,7 +495,7 @@ def test_get_all_users(super_admin_token):
         print_error(f"Get all users error: {e}")
         return False
 
-def test_create_user_admin(super_admin_token, username, email, password, role):
+def test_create_user_admin(super_admin_token, username, email, password, role, must_change_password=None):
     print_header(f"Testing Create User - {username} ({role})")
     try:
         headers = {
@@ -352,6 +507,8 @@ def test_create_user_admin(super_admin_token, username, email, password, role):
             "password": password,
             "role": role
         }
+        if must_change_password is not None:
+            payload["must_change_password"] = must_change_password
         response = requests.post(
             f"{AUTH_SERVICE_URL}/admin/users/create",
             json=payload,
@@ -365,11 +522,13 @@ def test_create_user_admin(super_admin_token, username, email, password, role):
             print(f"Username: {data['username']}")
             print(f"Email: {data['email']}")
             print(f"Role: {data['role']}")
+            print(f"Must Change Password: {data['must_change_password']}")
             return {
                 "id": data['id'],
                 "username": data['username'],
                 "email": data['email'],
-                "role": data['role']
+                "role": data['role'],
+                "must_change_password": data['must_change_password']
             }
         else:
             print_error(f"Create user failed: {response.text}")
@@ -378,6 +537,80 @@ def test_create_user_admin(super_admin_token, username, email, password, role):
         print_error(f"Create user error: {e}")
         return None
 
+def test_login_requires_password_change(email, password, expected_reason):
+    print_header(f"Testing Login Requires Password Change - {email}")
+    try:
+        payload = {
+            "email": email,
+            "password": password
+        }
+        response = requests.post(f"{AUTH_SERVICE_URL}/login", json=payload)
+
+        if response.status_code != 200:
+            print_error(f"Login failed: {response.text}")
+            return None
+
+        data = response.json()
+        if not data.get("password_change_required") or "access_token" in data:
+            print_error(f"Expected only a password change token, got: {data}")
+            return None
+        if data.get("reason") != expected_reason:
+            print_error(f"Expected reason {expected_reason}, got {data.get('reason')}")
+            return None
+
+        token = data["password_change_token"]
+        claims = decode_jwt(token)
+        if not claims or claims.get("token_type") != "password_change" or "role" in claims or "aud" in claims:
+            print_error(f"Unexpected password change token claims: {claims}")
+            return None
+
+        print_success(f"Login returned a password change token (reason: {data['reason']})")
+        return token
+    except Exception as e:
+        print_error(f"Login requires password change error: {e}")
+        return None
+
+def test_password_change_token_rejected(password_change_token):
+    print_header("Testing Password Change Token Is Rejected Elsewhere")
+    try:
+        headers = {
+            "Authorization": f"Bearer {password_change_token}"
+        }
+        response = requests.get(f"{AUTH_SERVICE_URL}/profile", headers=headers)
+
+        if response.status_code == 401:
+            print_success("Password change token correctly rejected by /profile (401)")
+            return True
+        else:
+            print_error(f"Expected 401, got {response.status_code}: {response.text}")
+            return False
+    except Exception as e:
+        print_error(f"Password change token test error: {e}")
+        return False
+
+def test_forced_change_rejects_same_password(password_change_token, current_password):
+    print_header("Testing Forced Password Change Rejects The Current Password")
+    try:
+        headers = {
+            "Authorization": f"Bearer {password_change_token}"
+        }
+        payload = {
+            "old_password": current_password,
+            "new_password": current_password
+        }
+        response = requests.post(f"{AUTH_SERVICE_URL}/change-password", json=payload, headers=headers)
+
+        if response.status_code == 400:
+            fields = response.json().get("fields", [])
+            if any(f.get("field") == "new_password" for f in fields):
+                print_success("Reusing the current password correctly rejected (400)")
+                return True
+        print_error(f"Expected 400 with a new_password error, got {response.status_code}: {response.text}")
+        return False
+    except Exception as e:
+        print_error(f"Forced password change test error: {e}")
+        return False
+
 def test_get_user_admin(super_admin_token, user_id, expected_username):
     print_header(f"Testing Get User - {expected_username}")
     try:
@@ -508,6 +741,1157 @@ def test_unauthorized_role_update(user_token, target_user_id, new_role, username
         print_error(f"Unauthorized role update test error: {e}")
         return False
 
+def test_jwks(access_token):
+    print_header("Testing JWKS Endpoint")
+    try:
+        response = requests.get(f"{AUTH_SERVICE_URL}/.well-known/jwks.json")
+        if response.status_code != 200:
+            print_error(f"JWKS request failed: {response.text}")
+            return False
+
+        keys = response.json().get("keys")
+        if keys is None:
+            print_error(f"JWKS has no keys member: {response.text}")
+            return False
+
+        # Only public members may be published, and never an HMAC secret
+        for key in keys:
+            if key.get("kty") == "oct" or any(member in key for member in ("d", "p", "q", "k")):
+                print_error(f"JWKS exposes private key material: {key}")
+                return False
+
+        header = decode_jwt_header(access_token)
+        print(f"Token alg: {header.get('alg')}, kid: {header.get('kid')}")
+        print(f"Published keys: {[key.get('kid') for key in keys]}")
+        if header.get("alg") == "HS256":
+            if keys:
+                print_error("HS256 deployments should not publish any keys")
+                return False
+            print_success("HS256 secret is not published")
+            return True
+
+        if not any(key.get("kid") == header.get("kid") and key.get("alg") == header.get("alg") for key in keys):
+            print_error("The access token's signing key is not in the JWKS")
+            return False
+        print_success("Access token's signing key is published")
+        return True
+    except Exception as e:
+        print_error(f"JWKS error: {e}")
+        return False
+
+def test_refresh_token_reuse(user):
+    print_header("Testing Refresh Token Rotation And Reuse Detection")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+        first = tokens["refresh_token"]
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": first})
+        if response.status_code != 200:
+            print_error(f"Refresh failed: {response.text}")
+            return False
+        second = response.json()["refresh_token"]
+        if second == first:
+            print_error("Refresh returned the same refresh token")
+            return False
+        print_success("Refresh token rotated")
+
+        # Replaying the used token revokes the whole family
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": first})
+        if response.status_code != 401:
+            print_error(f"Expected 401 for a replayed refresh token, got {response.status_code}: {response.text}")
+            return False
+        print_success(f"Replayed refresh token rejected: {response.json().get('error')}")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": second})
+        if response.status_code != 401:
+            print_error(f"Expected 401 for the rest of the revoked family, got {response.status_code}")
+            return False
+        print_success("The replacement token was revoked with its family")
+        return True
+    except Exception as e:
+        print_error(f"Refresh token reuse error: {e}")
+        return False
+
+def run_refresh_token_tests(super_admin_data):
+    user = create_test_user(super_admin_data["access_token"], "refresh")
+    test_results["Refresh Tokens"] = {
+        "reuse_detection": user is not None and test_refresh_token_reuse(user)
+    }
+
+def test_logout(user):
+    print_header("Testing Logout")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+        headers = {"Authorization": f"Bearer {tokens['access_token']}"}
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/logout", headers=headers, json={"refresh_token": tokens["refresh_token"]})
+        if response.status_code != 200:
+            print_error(f"Logout failed: {response.text}")
+            return False
+        print_success("Logged out")
+
+        response = requests.get(f"{AUTH_SERVICE_URL}/profile", headers=headers)
+        if response.status_code != 401:
+            print_error(f"Expected 401 for a revoked access token, got {response.status_code}")
+            return False
+        print_success("The access token is revoked")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": tokens["refresh_token"]})
+        if response.status_code != 401:
+            print_error(f"Expected 401 for a revoked refresh token, got {response.status_code}")
+            return False
+        print_success("The refresh token is revoked")
+        return True
+    except Exception as e:
+        print_error(f"Logout error: {e}")
+        return False
+
+def test_logout_all(user):
+    print_header("Testing Logout From All Sessions")
+    try:
+        first = login_user(user["email"], user["password"])
+        second = login_user(user["email"], user["password"])
+        if not first or not second:
+            return False
+
+        headers = {"Authorization": f"Bearer {first['access_token']}"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/logout/all", headers=headers)
+        if response.status_code != 200:
+            print_error(f"Logout from all sessions failed: {response.text}")
+            return False
+        print_success("Logged out of all sessions")
+
+        # The other session can no longer be refreshed
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": second["refresh_token"]})
+        if response.status_code != 401:
+            print_error(f"Expected 401 for the other session's refresh token, got {response.status_code}")
+            return False
+        print_success("The other session's refresh token is revoked")
+        return True
+    except Exception as e:
+        print_error(f"Logout all error: {e}")
+        return False
+
+def run_logout_tests(super_admin_data):
+    user = create_test_user(super_admin_data["access_token"], "logout")
+    test_results["Logout"] = {
+        "logout": user is not None and test_logout(user),
+        "logout_all": user is not None and test_logout_all(user)
+    }
+
+def test_role_change_revokes_tokens(super_admin_token, user):
+    print_header("Testing Token Invalidation On Role Change")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+
+        if not test_update_user_role(super_admin_token, user["id"], DEFAULT_ROLE, user["username"]):
+            return False
+
+        # The role change bumps the user's token version
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": tokens["refresh_token"]})
+        if response.status_code != 401:
+            print_error(f"Expected 401 for a refresh token issued before the role change, got {response.status_code}")
+            return False
+        print_success("Refresh token issued before the role change rejected")
+
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+        print_success("Logged in again after the role change")
+        return True
+    except Exception as e:
+        print_error(f"Role change invalidation error: {e}")
+        return False
+
+def test_deletion_revokes_tokens(super_admin_token, user):
+    print_header("Testing Token Invalidation On Deletion")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+
+        if not test_delete_user_admin(super_admin_token, user["id"], user["username"]):
+            return False
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/refresh", json={"refresh_token": tokens["refresh_token"]})
+        if response.status_code != 401:
+            print_error(f"Expected 401 for a deleted user's refresh token, got {response.status_code}")
+            return False
+        print_success("Deleted user's refresh token rejected")
+        return True
+    except Exception as e:
+        print_error(f"Deletion invalidation error: {e}")
+        return False
+
+def run_token_version_tests(super_admin_data):
+    super_admin_token = super_admin_data["access_token"]
+    user = create_test_user(super_admin_token, "tokenversion")
+    test_results["Token Version"] = {
+        "role_change": user is not None and test_role_change_revokes_tokens(super_admin_token, user),
+        "deletion": user is not None and test_deletion_revokes_tokens(super_admin_token, user)
+    }
+
+def introspect(token):
+    client_id, secret = next(iter(INTROSPECTION_CLIENTS.items()))
+    return requests.post(f"{AUTH_SERVICE_URL}/introspect", data={"token": token}, auth=(client_id, secret))
+
+def test_introspection(user):
+    print_header("Testing Token Introspection")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/introspect", data={"token": tokens["access_token"]})
+        if response.status_code != 401:
+            print_error(f"Expected 401 without client credentials, got {response.status_code}")
+            return False
+        print_success("Unauthenticated callers rejected")
+
+        response = introspect(tokens["access_token"])
+        data = response.json()
+        if response.status_code != 200 or not data.get("active") or data.get("sub") != user["id"]:
+            print_error(f"Expected the access token to be active: {response.text}")
+            return False
+        print_success(f"Access token active (sub {data['sub']}, token_use {data.get('token_use')})")
+
+        response = introspect(tokens["refresh_token"])
+        if response.status_code != 200 or not response.json().get("active"):
+            print_error(f"Expected the refresh token to be active: {response.text}")
+            return False
+        print_success("Refresh token active")
+
+        headers = {"Authorization": f"Bearer {tokens['access_token']}"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/logout", headers=headers, json={"refresh_token": tokens["refresh_token"]})
+        if response.status_code != 200:
+            print_error(f"Logout failed: {response.text}")
+            return False
+
+        for name in ("access_token", "refresh_token"):
+            response = introspect(tokens[name])
+            if response.status_code != 200 or response.json() != {"active": False}:
+                print_error(f"Expected the revoked {name} to be inactive: {response.text}")
+                return False
+        print_success("Revoked tokens reported inactive")
+        return True
+    except Exception as e:
+        print_error(f"Introspection error: {e}")
+        return False
+
+def run_introspection_tests(super_admin_data):
+    if not INTROSPECTION_CLIENTS:
+        print_info("Skipping introspection tests: INTROSPECTION_CLIENTS is not set")
+        return
+    user = create_test_user(super_admin_data["access_token"], "introspect")
+    test_results["Introspection"] = {
+        "introspect": user is not None and test_introspection(user)
+    }
+
+def test_registered_claims(user):
+    print_header("Testing Registered Claims")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+        claims = decode_jwt(tokens["access_token"])
+
+        if claims.get("sub") != user["id"] or not claims.get("jti"):
+            print_error(f"Expected sub {user['id']} and a jti, got {claims}")
+            return False
+        if not claims.get("nbf") or claims["nbf"] > claims["iat"] or claims["exp"] <= claims["iat"]:
+            print_error(f"Unexpected nbf/iat/exp: {claims}")
+            return False
+        print_success(f"sub, jti, nbf, iat and exp set (jti {claims['jti']})")
+
+        if JWT_ISSUER and claims.get("iss") != JWT_ISSUER:
+            print_error(f"Expected iss {JWT_ISSUER}, got {claims.get('iss')}")
+            return False
+        if JWT_AUDIENCE and sorted(claims.get("aud", [])) != sorted(JWT_AUDIENCE):
+            print_error(f"Expected aud {JWT_AUDIENCE}, got {claims.get('aud')}")
+            return False
+        print_success(f"iss {claims.get('iss')!r} and aud {claims.get('aud')!r} match the configuration")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/login", json={
+            "email": user["email"],
+            "password": user["password"],
+            "audience": f"unknown-audience-{RUN_ID}"
+        })
+        if response.status_code != 400:
+            print_error(f"Expected 400 for an unknown audience, got {response.status_code}: {response.text}")
+            return False
+        print_success("Login for an unknown audience rejected")
+
+        if JWT_AUDIENCE:
+            tokens = login_user(user["email"], user["password"], JWT_AUDIENCE[0])
+            if not tokens:
+                return False
+            aud = decode_jwt(tokens["access_token"]).get("aud")
+            if aud != [JWT_AUDIENCE[0]]:
+                print_error(f"Expected aud [{JWT_AUDIENCE[0]}], got {aud}")
+                return False
+            print_success(f"Login for {JWT_AUDIENCE[0]} narrows aud to it")
+        return True
+    except Exception as e:
+        print_error(f"Registered claims error: {e}")
+        return False
+
+def run_registered_claims_tests(super_admin_data):
+    user = create_test_user(super_admin_data["access_token"], "claims")
+    test_results["Registered Claims"] = {
+        "claims": user is not None and test_registered_claims(user)
+    }
+
+def test_authorization_code_flow(super_admin_token, user):
+    print_header("Testing OAuth Authorization Code + PKCE")
+    redirect_uri = "http://localhost:3000/callback"
+    client_id = create_oauth_client(super_admin_token, "authcode", redirect_uri, ["read"])
+    if not client_id:
+        return False
+    try:
+        response = requests.get(f"{AUTH_SERVICE_URL}/oauth/authorize", params={
+            "client_id": client_id,
+            "redirect_uri": "http://localhost:3000/elsewhere",
+            "response_type": "code"
+        }, allow_redirects=False)
+        if response.status_code != 400:
+            print_error(f"Expected 400 for an unregistered redirect_uri, got {response.status_code}")
+            return False
+        print_success("Unregistered redirect_uri rejected without redirecting")
+
+        verifier, challenge = pkce_pair()
+        response, params = authorize(client_id, redirect_uri, user, challenge, "read")
+        if response.status_code != 302 or "code" not in params or params.get("state") != f"state-{RUN_ID}":
+            print_error(f"Authorization failed ({response.status_code}): {response.headers.get('Location')} {response.text}")
+            return False
+        print_success("Authorization code issued")
+
+        exchange = {
+            "grant_type": "authorization_code",
+            "client_id": client_id,
+            "code": params["code"],
+            "redirect_uri": redirect_uri,
+            "code_verifier": verifier
+        }
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=exchange)
+        if response.status_code != 200:
+            print_error(f"Code exchange failed: {response.text}")
+            return False
+        tokens = response.json()
+        claims = decode_jwt(tokens["access_token"])
+        if claims.get("client_id") != client_id or claims.get("scope") != "read" or claims.get("sub") != user["id"]:
+            print_error(f"Unexpected access token claims: {claims}")
+            return False
+        print_success(f"Code exchanged (scope {claims['scope']})")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=exchange)
+        if response.status_code != 400 or response.json().get("error") != "invalid_grant":
+            print_error(f"Expected invalid_grant for a replayed code, got {response.status_code}: {response.text}")
+            return False
+        print_success("Replayed code rejected")
+
+        _, params = authorize(client_id, redirect_uri, user, challenge, "read")
+        exchange["code"] = params.get("code", "")
+        exchange["code_verifier"] = pkce_pair()[0]
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=exchange)
+        if response.status_code != 400 or response.json().get("error") != "invalid_grant":
+            print_error(f"Expected invalid_grant for a wrong code_verifier, got {response.status_code}: {response.text}")
+            return False
+        print_success("Wrong code_verifier rejected")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data={
+            "grant_type": "refresh_token",
+            "client_id": client_id,
+            "refresh_token": tokens["refresh_token"]
+        })
+        if response.status_code != 200 or decode_jwt(response.json()["access_token"]).get("scope") != "read":
+            print_error(f"Refresh grant failed: {response.text}")
+            return False
+        print_success("Refresh grant keeps the granted scope")
+
+        response, params = authorize(client_id, redirect_uri, user, challenge, "read", decision="deny")
+        if response.status_code != 302 or params.get("error") != "access_denied":
+            print_error(f"Expected access_denied, got {response.status_code}: {response.headers.get('Location')}")
+            return False
+        print_success("Denied request redirects with access_denied")
+        return True
+    except Exception as e:
+        print_error(f"Authorization code flow error: {e}")
+        return False
+    finally:
+        delete_oauth_client(super_admin_token, client_id)
+
+def test_openid_connect(super_admin_token, user):
+    print_header("Testing OpenID Connect")
+    response = requests.get(f"{AUTH_SERVICE_URL}/.well-known/openid-configuration")
+    if response.status_code == 404:
+        print_info("Skipping: OIDC_ENABLED is off")
+        return None
+    discovery = response.json()
+    if response.status_code != 200 or "authorization_code" not in discovery.get("grant_types_supported", []):
+        print_error(f"Unexpected discovery document: {response.text}")
+        return False
+    print_success(f"Discovery document published (issuer {discovery.get('issuer')!r})")
+
+    redirect_uri = "http://localhost:3000/callback"
+    client_id = create_oauth_client(super_admin_token, "oidc", redirect_uri, ["openid", "email"])
+    if not client_id:
+        return False
+    try:
+        verifier, challenge = pkce_pair()
+        nonce = secrets.token_hex(8)
+        _, params = authorize(client_id, redirect_uri, user, challenge, "openid email", nonce=nonce)
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data={
+            "grant_type": "authorization_code",
+            "client_id": client_id,
+            "code": params.get("code", ""),
+            "redirect_uri": redirect_uri,
+            "code_verifier": verifier
+        })
+        if response.status_code != 200 or "id_token" not in response.json():
+            print_error(f"Expected an ID token: {response.text}")
+            return False
+        tokens = response.json()
+
+        id_token = decode_jwt(tokens["id_token"])
+        aud = id_token.get("aud")
+        if isinstance(aud, str):
+            aud = [aud]
+        if id_token.get("sub") != user["id"] or aud != [client_id] or id_token.get("nonce") != nonce:
+            print_error(f"Unexpected ID token claims: {id_token}")
+            return False
+        if id_token.get("email") != user["email"] or not id_token.get("auth_time"):
+            print_error(f"Expected email and auth_time in the ID token: {id_token}")
+            return False
+        print_success("ID token carries sub, aud, nonce, auth_time and email")
+
+        headers = {"Authorization": f"Bearer {tokens['access_token']}"}
+        response = requests.get(f"{AUTH_SERVICE_URL}/userinfo", headers=headers)
+        if response.status_code != 200 or response.json().get("email") != user["email"]:
+            print_error(f"Userinfo failed: {response.text}")
+            return False
+        if "preferred_username" in response.json():
+            print_error("Userinfo returned profile claims without the profile scope")
+            return False
+        print_success("Userinfo returns the claims of the granted scopes")
+
+        for path in ("/profile", "/admin/users", "/api-keys"):
+            response = requests.get(f"{AUTH_SERVICE_URL}{path}", headers=headers)
+            if response.status_code != 403:
+                print_error(f"Expected 403 for a client token on {path}, got {response.status_code}")
+                return False
+        print_success("Client tokens are refused outside /userinfo")
+
+        login = login_user(user["email"], user["password"])
+        headers = {"Authorization": f"Bearer {login['access_token']}"}
+        response = requests.get(f"{AUTH_SERVICE_URL}/userinfo", headers=headers)
+        if response.status_code != 403:
+            print_error(f"Expected 403 for a token without the openid scope, got {response.status_code}")
+            return False
+        print_success("Userinfo requires the openid scope")
+        return True
+    except Exception as e:
+        print_error(f"OpenID Connect error: {e}")
+        return False
+    finally:
+        delete_oauth_client(super_admin_token, client_id)
+
+def test_device_flow(super_admin_token, user):
+    print_header("Testing OAuth Device Authorization Grant")
+    client_id = create_oauth_client(super_admin_token, "device", "http://localhost:3000/callback", ["read"])
+    if not client_id:
+        return False
+    try:
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/device_authorization", data={"client_id": client_id, "scope": "read"})
+        if response.status_code == 404:
+            print_info("Skipping: JWT_ISSUER is not the service's base URL")
+            return None
+        if response.status_code != 200:
+            print_error(f"Device authorization failed: {response.text}")
+            return False
+        data = response.json()
+        if JWT_ISSUER and data["verification_uri"] != JWT_ISSUER.rstrip("/") + "/oauth/device":
+            print_error(f"Expected the verification URI under {JWT_ISSUER}, got {data['verification_uri']}")
+            return False
+        print_success(f"User code {data['user_code']}, verify at {data['verification_uri']}")
+
+        poll = {
+            "grant_type": "urn:ietf:params:oauth:grant-type:device_code",
+            "client_id": client_id,
+            "device_code": data["device_code"]
+        }
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=poll)
+        if response.status_code != 400 or response.json().get("error") != "authorization_pending":
+            print_error(f"Expected authorization_pending, got {response.status_code}: {response.text}")
+            return False
+        print_success("Polling before approval returns authorization_pending")
+
+        # User codes are accepted however the user types them
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/device", data={
+            "user_code": data["user_code"].lower().replace("-", " "),
+            "email": user["email"],
+            "password": user["password"],
+            "decision": "allow"
+        })
+        if response.status_code != 200:
+            print_error(f"Approval failed ({response.status_code}): {response.text}")
+            return False
+        print_success("Device approved")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=poll)
+        if response.status_code != 200:
+            print_error(f"Polling after approval failed: {response.text}")
+            return False
+        claims = decode_jwt(response.json()["access_token"])
+        if claims.get("sub") != user["id"] or claims.get("scope") != "read" or not claims.get("auth_time"):
+            print_error(f"Unexpected device token claims: {claims}")
+            return False
+        print_success("Tokens issued to the device")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=poll)
+        if response.status_code != 400 or response.json().get("error") != "invalid_grant":
+            print_error(f"Expected invalid_grant for a used device code, got {response.status_code}: {response.text}")
+            return False
+        print_success("Used device code rejected")
+        return True
+    except Exception as e:
+        print_error(f"Device flow error: {e}")
+        return False
+    finally:
+        delete_oauth_client(super_admin_token, client_id)
+
+def run_oauth_tests(super_admin_data):
+    super_admin_token = super_admin_data["access_token"]
+    user = create_test_user(super_admin_token, "oauth")
+    test_results["OAuth"] = {
+        "authorization_code": user is not None and test_authorization_code_flow(super_admin_token, user)
+    }
+    device = user is not None and test_device_flow(super_admin_token, user)
+    if device is not None:
+        test_results["OAuth"]["device_code"] = device
+    oidc = user is not None and test_openid_connect(super_admin_token, user)
+    if oidc is not None:
+        test_results["OAuth"]["openid_connect"] = oidc
+
+def test_impersonation(super_admin_data, user):
+    print_header("Testing Admin Impersonation")
+    try:
+        admin_headers = {"Authorization": f"Bearer {super_admin_data['access_token']}"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/admin/users/impersonate/{user['id']}", headers=admin_headers, json={
+            "reason": f"test run {RUN_ID}"
+        })
+        if response.status_code != 200:
+            print_error(f"Impersonation failed: {response.text}")
+            return False
+        data = response.json()
+        if "refresh_token" in data:
+            print_error("Impersonation must not come with a refresh token")
+            return False
+
+        claims = decode_jwt(data["access_token"])
+        if claims.get("sub") != user["id"] or claims.get("act", {}).get("sub") != super_admin_data["id"]:
+            print_error(f"Expected sub {user['id']} acted on by the admin, got {claims}")
+            return False
+        print_success(f"Impersonation token issued (expires in {data.get('expires_in')}s)")
+
+        headers = {"Authorization": f"Bearer {data['access_token']}"}
+        response = requests.get(f"{AUTH_SERVICE_URL}/profile", headers=headers)
+        if response.status_code != 200 or response.json().get("id") != user["id"]:
+            print_error(f"Profile as the user failed: {response.text}")
+            return False
+        print_success("Requests run as the impersonated user")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/api-keys/create", headers=headers, json={"name": "impersonated"})
+        if response.status_code != 403:
+            print_error(f"Expected 403 creating an API key while impersonating, got {response.status_code}")
+            return False
+        print_success("Credentials can't be created while impersonating")
+
+        response = requests.get(f"{AUTH_SERVICE_URL}/admin/audit", headers=admin_headers, params={"subject_id": user["id"]})
+        actions = [entry["action"] for entry in response.json().get("entries", [])]
+        if "impersonation.started" not in actions or "impersonation.request" not in actions:
+            print_error(f"Expected the impersonation in the audit log, got {actions}")
+            return False
+        print_success("Impersonation and its requests are in the audit log")
+        return True
+    except Exception as e:
+        print_error(f"Impersonation error: {e}")
+        return False
+
+def run_impersonation_tests(super_admin_data):
+    user = create_test_user(super_admin_data["access_token"], "impersonate")
+    test_results["Impersonation"] = {
+        "impersonate": user is not None and test_impersonation(super_admin_data, user)
+    }
+
+def client_credentials_token(client_id, client_secret, scope=None):
+    data = {"grant_type": "client_credentials"}
+    if scope:
+        data["scope"] = scope
+    return requests.post(f"{AUTH_SERVICE_URL}/oauth/token", data=data, auth=(client_id, client_secret))
+
+def test_service_account(super_admin_token):
+    print_header("Testing Service Accounts (Client Credentials)")
+    try:
+        headers = {"Authorization": f"Bearer {super_admin_token}"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/admin/service-accounts/create", headers=headers, json={
+            "name": f"svc_{RUN_ID}",
+            "scopes": ["read", "write"]
+        })
+        if response.status_code != 201:
+            print_error(f"Failed to create service account: {response.text}")
+            return False
+        data = response.json()
+        account = data["service_account"]
+        client_id, secret = account["client_id"], data["client_secret"]
+        print_success(f"Service account created: {client_id}")
+
+        response = client_credentials_token(client_id, secret, "read")
+        if response.status_code != 200 or "refresh_token" in response.json():
+            print_error(f"Client credentials grant failed: {response.text}")
+            return False
+        claims = decode_jwt(response.json()["access_token"])
+        if claims.get("sub") != account["id"] or claims.get("scope") != "read":
+            print_error(f"Unexpected service token claims: {claims}")
+            return False
+        print_success("Access token issued for the requested scope, without a refresh token")
+
+        response = client_credentials_token(client_id, secret, "admin")
+        if response.status_code != 400 or response.json().get("error") != "invalid_scope":
+            print_error(f"Expected invalid_scope, got {response.status_code}: {response.text}")
+            return False
+        print_success("Scopes the account doesn't hold rejected")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/admin/service-accounts/rotate/{account['id']}", headers=headers)
+        if response.status_code != 200:
+            print_error(f"Secret rotation failed: {response.text}")
+            return False
+        new_secret = response.json()["client_secret"]
+        if client_credentials_token(client_id, secret).status_code != 401:
+            print_error("The old secret still works after rotation")
+            return False
+        if client_credentials_token(client_id, new_secret).status_code != 200:
+            print_error("The new secret doesn't work after rotation")
+            return False
+        print_success("Rotation replaces the secret")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/admin/service-accounts/disable/{account['id']}", headers=headers)
+        if response.status_code != 200:
+            print_error(f"Disabling failed: {response.text}")
+            return False
+        if client_credentials_token(client_id, new_secret).status_code != 401:
+            print_error("A disabled account can still get tokens")
+            return False
+        print_success("Disabled accounts can't get tokens")
+        return True
+    except Exception as e:
+        print_error(f"Service account error: {e}")
+        return False
+
+def run_service_account_tests(super_admin_data):
+    test_results["Service Accounts"] = {
+        "client_credentials": test_service_account(super_admin_data["access_token"])
+    }
+
+def test_api_keys(user):
+    print_header("Testing API Keys")
+    try:
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+        headers = {"Authorization": f"Bearer {tokens['access_token']}"}
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/api-keys/create", headers=headers, json={
+            "name": f"ci_{RUN_ID}",
+            "scopes": ["read"]
+        })
+        if response.status_code != 201:
+            print_error(f"API key creation failed: {response.text}")
+            return False
+        data = response.json()
+        key, key_id = data["key"], data["api_key"]["id"]
+        print_success(f"API key created ({data['api_key']['prefix']}...)")
+
+        key_headers = {"Authorization": f"ApiKey {key}"}
+        response = requests.get(f"{AUTH_SERVICE_URL}/profile", headers=key_headers)
+        if response.status_code != 200 or response.json().get("id") != user["id"]:
+            print_error(f"Request with the API key failed: {response.text}")
+            return False
+        print_success("API key authenticates as its owner")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/api-keys/create", headers=key_headers, json={"name": "nested"})
+        if response.status_code != 403:
+            print_error(f"Expected 403 creating a key with a key, got {response.status_code}")
+            return False
+        print_success("API keys can't create more keys")
+
+        response = requests.get(f"{AUTH_SERVICE_URL}/api-keys", headers=headers)
+        if response.status_code != 200 or key in response.text:
+            print_error(f"Listing keys failed or exposed the key: {response.text}")
+            return False
+        print_success("Listing shows the key's prefix only")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/api-keys/revoke/{key_id}", headers=headers)
+        if response.status_code != 200:
+            print_error(f"Revoking the key failed: {response.text}")
+            return False
+        response = requests.get(f"{AUTH_SERVICE_URL}/profile", headers=key_headers)
+        if response.status_code != 401:
+            print_error(f"Expected 401 for a revoked key, got {response.status_code}")
+            return False
+        print_success("Revoked key rejected")
+        return True
+    except Exception as e:
+        print_error(f"API key error: {e}")
+        return False
+
+def run_api_key_tests(super_admin_data):
+    user = create_test_user(super_admin_data["access_token"], "apikey")
+    test_results["API Keys"] = {
+        "api_keys": user is not None and test_api_keys(user)
+    }
+
+def test_password_policy(super_admin_token):
+    print_header("Testing Password Policy")
+    try:
+        response = requests.get(f"{AUTH_SERVICE_URL}/password-policy")
+        if response.status_code != 200 or "min_length" not in response.json():
+            print_error(f"Failed to get the password policy: {response.text}")
+            return False
+        policy = response.json()
+        print_success(f"Password policy: {policy}")
+
+        username = f"policy_{RUN_ID}"
+        headers = {"Authorization": f"Bearer {super_admin_token}"}
+        def create(password):
+            return requests.post(f"{AUTH_SERVICE_URL}/admin/users/create", headers=headers, json={
+                "username": username,
+                "email": f"{username}@example.com",
+                "password": password,
+                "role": DEFAULT_ROLE
+            })
+
+        rejected = {"too_short": "Ab1!"[:policy["min_length"] - 1]}
+        if policy.get("disallow_personal_info"):
+            rejected["contains_username"] = f"Xx-{username}-9"
+        for code, weak in rejected.items():
+            response = create(weak)
+            data = response.json()
+            if response.status_code != 400 or code not in [field["code"] for field in data.get("fields", [])]:
+                print_error(f"Expected a {code} error, got {response.status_code}: {response.text}")
+                return False
+            print_success(f"Rejected with {code}: {data['fields']}")
+        return True
+    except Exception as e:
+        print_error(f"Password policy error: {e}")
+        return False
+
+def test_password_history(super_admin_token):
+    print_header("Testing Password History")
+    try:
+        depth = requests.get(f"{AUTH_SERVICE_URL}/password-policy").json().get("history_depth", 0)
+        if depth < 2:
+            print_info(f"PASSWORD_HISTORY_DEPTH is {depth}, skipping")
+            return True
+
+        user = create_test_user(super_admin_token, "history")
+        if not user:
+            return False
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+
+        def change(access_token, old_password, new_password):
+            return requests.post(f"{AUTH_SERVICE_URL}/change-password",
+                                 headers={"Authorization": f"Bearer {access_token}"},
+                                 json={"old_password": old_password, "new_password": new_password})
+
+        new_password = f"Tp-{secrets.token_hex(8)}-9"
+        response = change(tokens["access_token"], user["password"], new_password)
+        if response.status_code != 200:
+            print_error(f"Failed to change the password: {response.text}")
+            return False
+        access_token = response.json()["access_token"]
+        print_success("Changed the password")
+
+        # Both the current password and the one before it are remembered
+        for label, reused in [("current", new_password), ("previous", user["password"])]:
+            response = change(access_token, new_password, reused)
+            codes = [field["code"] for field in response.json().get("fields", [])]
+            if response.status_code != 400 or "reused" not in codes:
+                print_error(f"Expected the {label} password to be rejected, got {response.status_code}: {response.text}")
+                return False
+            print_success(f"Rejected the {label} password as reused")
+        return True
+    except Exception as e:
+        print_error(f"Password history error: {e}")
+        return False
+
+def test_password_reset(super_admin_token):
+    print_header("Testing Password Reset")
+    if MAILER != "file":
+        print_info("MAILER is not file, skipping (the suite reads the reset email from MAILER_FILE_DIR)")
+        return True
+    try:
+        user = create_test_user(super_admin_token, "reset")
+        if not user:
+            return False
+
+        # Unknown emails get the same answer as accounts
+        answers = []
+        for email in [f"nobody_{RUN_ID}@example.com", user["email"]]:
+            response = requests.post(f"{AUTH_SERVICE_URL}/password/forgot", json={"email": email})
+            if response.status_code != 202:
+                print_error(f"Forgot password failed for {email}: {response.text}")
+                return False
+            answers.append(response.json())
+        if answers[0] != answers[1]:
+            print_error(f"Unknown email answered differently: {answers}")
+            return False
+        print_success("Forgot password gives the same answer for unknown emails")
+
+        body = wait_for_email(user["email"])
+        token = token_from_email(body) if body else None
+        if not token:
+            print_error(f"No reset token in the email: {body}")
+            return False
+        print_success("Received the reset email")
+
+        new_password = f"Tp-{secrets.token_hex(8)}-9"
+        response = requests.post(f"{AUTH_SERVICE_URL}/password/reset", json={"token": token, "new_password": new_password})
+        if response.status_code != 200:
+            print_error(f"Reset failed: {response.text}")
+            return False
+        print_success("Password reset")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/login", json={"email": user["email"], "password": user["password"]})
+        if response.status_code != 401:
+            print_error(f"Old password still works: {response.status_code}")
+            return False
+        if not login_user(user["email"], new_password):
+            return False
+        print_success("Only the new password works")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/password/reset", json={"token": token, "new_password": f"Tp-{secrets.token_hex(8)}-9"})
+        if response.status_code != 400:
+            print_error(f"Reset token was accepted twice: {response.status_code}")
+            return False
+        print_success("Reset token is single-use")
+        return True
+    except Exception as e:
+        print_error(f"Password reset error: {e}")
+        return False
+
+def run_password_policy_tests(super_admin_data):
+    test_results["Password Policy"] = {
+        "policy": test_password_policy(super_admin_data["access_token"]),
+        "history": test_password_history(super_admin_data["access_token"]),
+        "reset": test_password_reset(super_admin_data["access_token"])
+    }
+
+def test_lockout(super_admin_token):
+    print_header("Testing Account Lockout")
+    try:
+        user = create_test_user(super_admin_token, "lockout")
+        if not user:
+            return False
+
+        # Wrong passwords are refused before the password is checked once a delay or lockout applies
+        refused = None
+        for attempt in range(1, 16):
+            response = requests.post(f"{AUTH_SERVICE_URL}/login", json={"email": user["email"], "password": "wrong-password"})
+            if response.status_code in (423, 429):
+                refused = response
+                break
+            if response.status_code != 401:
+                print_error(f"Unexpected answer to a wrong password: {response.status_code} {response.text}")
+                return False
+        if refused is None:
+            print_info("No attempt was refused, LOCKOUT_THRESHOLD and LOGIN_DELAY_AFTER seem to be disabled")
+            return True
+
+        code = refused.json().get("code")
+        expected = {423: "account_locked", 429: "login_throttled"}[refused.status_code]
+        if code != expected or not refused.headers.get("Retry-After"):
+            print_error(f"Expected {expected} with Retry-After, got {refused.status_code} {refused.text}")
+            return False
+        print_success(f"Attempt {attempt} refused with {code}, retry after {refused.headers['Retry-After']}s")
+
+        # Locked and throttled accounts refuse the right password too, before it is checked
+        response = requests.post(f"{AUTH_SERVICE_URL}/login", json={"email": user["email"], "password": user["password"]})
+        if response.status_code != refused.status_code or response.json().get("code") != expected:
+            print_error(f"Expected the correct password to be refused with {refused.status_code} {expected}, "
+                        f"got {response.status_code} {response.text}")
+            return False
+        print_success(f"Correct password refused with {expected} too")
+
+        headers = {"Authorization": f"Bearer {super_admin_token}"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/admin/users/unlock/{user['id']}", headers=headers)
+        if response.status_code != 200:
+            print_error(f"Unlock failed: {response.text}")
+            return False
+        if not login_user(user["email"], user["password"]):
+            return False
+        print_success("Unlocked account can sign in again")
+        return True
+    except Exception as e:
+        print_error(f"Lockout error: {e}")
+        return False
+
+def run_lockout_tests(super_admin_data):
+    test_results["Lockout"] = {
+        "lockout": test_lockout(super_admin_data["access_token"])
+    }
+
+def test_rate_limit():
+    print_header("Testing Rate Limiting")
+    try:
+        # An unknown account, so only the rate limits can refuse it
+        payload = {"email": f"ratelimit_{RUN_ID}@example.com", "password": "wrong-password"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/login", json=payload)
+        limit = response.headers.get("RateLimit-Limit")
+        if not limit:
+            print_info("/login has no rate limits, skipping")
+            return True
+        print_success(f"RateLimit-Limit {limit}, RateLimit-Remaining {response.headers.get('RateLimit-Remaining')}")
+
+        for attempt in range(2, int(limit) + 2):
+            response = requests.post(f"{AUTH_SERVICE_URL}/login", json=payload)
+            if response.status_code == 429:
+                break
+        if response.status_code != 429 or response.json().get("error") != "rate limit exceeded":
+            print_error(f"Expected 429 after {limit} requests, got {response.status_code}: {response.text}")
+            return False
+        if not response.headers.get("Retry-After"):
+            print_error("429 without Retry-After")
+            return False
+        print_success(f"Request {attempt} refused, retry after {response.headers['Retry-After']}s")
+        return True
+    except Exception as e:
+        print_error(f"Rate limit error: {e}")
+        return False
+
+def test_mfa(super_admin_token):
+    print_header("Testing Multi-Factor Authentication")
+    try:
+        user = create_test_user(super_admin_token, "mfa")
+        if not user:
+            return False
+        tokens = login_user(user["email"], user["password"])
+        if not tokens:
+            return False
+        headers = {"Authorization": f"Bearer {tokens['access_token']}"}
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/mfa/totp/enroll", headers=headers)
+        if response.status_code != 200 or not response.json().get("otpauth_uri", "").startswith("otpauth://totp/"):
+            print_error(f"Enrollment failed: {response.text}")
+            return False
+        secret = response.json()["secret"]
+
+        step = int(time.time()) // 30
+        response = requests.post(f"{AUTH_SERVICE_URL}/mfa/totp/confirm", headers=headers, json={"code": totp_code(secret, step)})
+        recovery_codes = response.json().get("recovery_codes", []) if response.status_code == 200 else []
+        if len(recovery_codes) != 10:
+            print_error(f"Confirmation failed: {response.status_code} {response.text}")
+            return False
+        print_success(f"MFA enabled with {len(recovery_codes)} recovery codes")
+
+        def challenge():
+            response = requests.post(f"{AUTH_SERVICE_URL}/login", json={"email": user["email"], "password": user["password"]})
+            data = response.json()
+            if response.status_code != 200 or not data.get("mfa_required") or "access_token" in data:
+                print_error(f"Expected an MFA challenge: {response.text}")
+                return None
+            return data["mfa_token"]
+
+        def complete(mfa_token, code):
+            return requests.post(f"{AUTH_SERVICE_URL}/login/mfa", json={"mfa_token": mfa_token, "code": code})
+
+        mfa_token = challenge()
+        if not mfa_token:
+            return False
+        print_success("Password login returns an MFA challenge")
+
+        # The confirmation used this step's code, so it can't be replayed; the next step's code is accepted for clock drift
+        if complete(mfa_token, totp_code(secret, step)).status_code != 401:
+            print_error("Replayed TOTP code was accepted")
+            return False
+        response = complete(mfa_token, totp_code(secret, step + 1))
+        if response.status_code != 200:
+            print_error(f"TOTP login failed: {response.text}")
+            return False
+        claims = decode_jwt(response.json()["access_token"])
+        if not {"pwd", "otp", "mfa"} <= set(claims.get("amr", [])) or claims.get("acr") != "aal2":
+            print_error(f"Expected amr pwd, otp, mfa and acr aal2, got {claims.get('amr')} {claims.get('acr')}")
+            return False
+        print_success(f"TOTP login: amr {claims['amr']}, acr {claims['acr']}")
+
+        mfa_token = challenge()
+        response = complete(mfa_token, recovery_codes[0].lower())
+        if response.status_code != 200 or "rc" not in decode_jwt(response.json()["access_token"]).get("amr", []):
+            print_error(f"Recovery code login failed: {response.text}")
+            return False
+        if complete(challenge(), recovery_codes[0]).status_code != 401:
+            print_error("Recovery code was accepted twice")
+            return False
+        print_success("Recovery codes work once")
+
+        headers = {"Authorization": f"Bearer {response.json()['access_token']}"}
+        response = requests.post(f"{AUTH_SERVICE_URL}/mfa/disable", headers=headers,
+                                 json={"password": user["password"], "code": recovery_codes[1]})
+        if response.status_code != 200:
+            print_error(f"Disabling MFA failed: {response.text}")
+            return False
+        if not login_user(user["email"], user["password"]):
+            return False
+        print_success("Disabled MFA; password login issues tokens again")
+        return True
+    except Exception as e:
+        print_error(f"MFA error: {e}")
+        return False
+
+def run_mfa_tests(super_admin_data):
+    test_results["MFA"] = {
+        "totp": test_mfa(super_admin_data["access_token"])
+    }
+
+def test_webauthn_options(user_token):
+    print_header("Testing Passkey Ceremony Options")
+    try:
+        response = requests.post(f"{AUTH_SERVICE_URL}/login/webauthn/begin")
+        if response.status_code == 404:
+            print_info("WEBAUTHN_RP_ID is not set, skipping")
+            return True
+        options = response.json().get("publicKey", {})
+        if response.status_code != 200 or not options.get("challenge") or not options.get("rpId"):
+            print_error(f"Login options are missing the challenge or rpId: {response.text}")
+            return False
+        print_success(f"Login options for {options['rpId']}")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/webauthn/register/begin",
+                                 headers={"Authorization": f"Bearer {user_token}"})
+        options = response.json().get("publicKey", {})
+        algorithms = [param["alg"] for param in options.get("pubKeyCredParams", [])]
+        if response.status_code != 200 or not options.get("user", {}).get("id") or -7 not in algorithms:
+            print_error(f"Registration options are missing the user or ES256: {response.text}")
+            return False
+        print_success(f"Registration options offer algorithms {algorithms}")
+
+        # A made-up assertion for a fresh challenge never signs anyone in
+        challenge = requests.post(f"{AUTH_SERVICE_URL}/login/webauthn/begin").json()["publicKey"]["challenge"]
+        b64 = lambda data: base64.urlsafe_b64encode(data).rstrip(b"=").decode()
+        client_data = json.dumps({"type": "webauthn.get", "challenge": challenge, "origin": AUTH_SERVICE_URL}).encode()
+        credential = {
+            "id": b64(b"made-up"),
+            "rawId": b64(b"made-up"),
+            "type": "public-key",
+            "response": {
+                "clientDataJSON": b64(client_data),
+                "authenticatorData": b64(bytes(37)),
+                "signature": b64(bytes(64)),
+            },
+        }
+        response = requests.post(f"{AUTH_SERVICE_URL}/login/webauthn/finish", json={"credential": credential})
+        if response.status_code not in (400, 401) or "access_token" in response.json():
+            print_error(f"Made-up assertion was not rejected: {response.status_code} {response.text}")
+            return False
+        print_success("Made-up assertion rejected")
+        return True
+    except Exception as e:
+        print_error(f"Passkey options error: {e}")
+        return False
+
+def run_webauthn_tests(super_admin_data):
+    user = create_test_user(super_admin_data["access_token"], "passkey")
+    tokens = login_user(user["email"], user["password"]) if user else None
+    test_results["Passkeys"] = {
+        "options": test_webauthn_options(tokens["access_token"]) if tokens else False
+    }
+
+def test_email_verification(super_admin_token):
+    print_header("Testing Email Verification")
+    if MAILER != "file":
+        print_info("MAILER is not file, skipping (the suite reads the verification email from MAILER_FILE_DIR)")
+        return True
+    try:
+        # Admin-created users are verified; changing the email makes the user verify the new one
+        user = create_test_user(super_admin_token, "verify")
+        if not user:
+            return False
+        headers = {"Authorization": f"Bearer {super_admin_token}"}
+        def change_email(email):
+            response = requests.patch(f"{AUTH_SERVICE_URL}/admin/users/update/{user['id']}", headers=headers,
+                                      json={"username": user["username"], "email": email})
+            return response.status_code == 200
+
+        first_email = f"verify_first_{RUN_ID}@example.com"
+        if not change_email(first_email):
+            print_error("Failed to change the email")
+            return False
+        stale_token = token_from_email(wait_for_email(first_email) or "")
+
+        user["email"] = f"verify_{RUN_ID}@example.com"
+        if not change_email(user["email"]):
+            print_error("Failed to change the email")
+            return False
+        wait_for_email(user["email"])
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/login", json={"email": user["email"], "password": user["password"]})
+        if response.status_code == 403 and response.json().get("code") == "email_not_verified":
+            print_success("Unverified user can't sign in (EMAIL_VERIFICATION_MODE=required)")
+        elif response.status_code == 200 and decode_jwt(response.json()["access_token"]).get("email_verified") is False:
+            print_success("Unverified user signs in with email_verified false")
+        else:
+            print_error(f"Unexpected login before verification: {response.status_code} {response.text}")
+            return False
+
+        # A link sent to the previous address doesn't verify the new one
+        response = requests.post(f"{AUTH_SERVICE_URL}/email/verify", json={"token": stale_token})
+        if response.status_code != 400:
+            print_error(f"Token for the previous email was accepted: {response.status_code}")
+            return False
+        print_success("Token for the previous email rejected")
+
+        response = requests.post(f"{AUTH_SERVICE_URL}/email/verify/resend", json={"email": user["email"]})
+        if response.status_code != 202:
+            print_error(f"Resend failed: {response.text}")
+            return False
+        token = token_from_email(wait_for_email(user["email"]) or "")
+        response = requests.post(f"{AUTH_SERVICE_URL}/email/verify", json={"token": token})
+        if response.status_code != 200:
+            print_error(f"Verification failed: {response.text}")
+            return False
+
+        tokens = login_user(user["email"], user["password"])
+        if not tokens or decode_jwt(tokens["access_token"]).get("email_verified") is not True:
+            print_error("Verified user doesn't get email_verified true")
+            return False
+        print_success("Verified; tokens carry email_verified true")
+        return True
+    except Exception as e:
+        print_error(f"Email verification error: {e}")
+        return False
+
+def run_email_verification_tests(super_admin_data):
+    test_results["Email Verification"] = {
+        "verify": test_email_verification(super_admin_data["access_token"])
+    }
+
+def run_rate_limit_tests(super_admin_data):
+    test_results["Rate Limits"] = {
+        "login": test_rate_limit()
+    }
+
+def run_signing_key_tests(super_admin_data):
+    test_results["Signing Keys"] = {
+        "jwks": test_jwks(super_admin_data["access_token"])
+    }
+
 def print_test_results_summary():
     print_header("Test Results Summary")
     
@@ -515,20 +1899,20 @@ def print_test_results_summary():
         print_info("No tests were run")
         return
     
-    print(f"{'Role':<15} {'Tests':<10} {'Passed':<10} {'Failed':<10}")
+    print(f"{'Group':<15} {'Tests':<10} {'Passed':<10} {'Failed':<10}")
     print("-" * 50)
     
     total_passed = 0
     total_failed = 0
     
-    for role, results in test_results.items():
+    for group, results in test_results.items():
         passed = sum(1 for r in results.values() if r)
         failed = len(results) - passed
         total_passed += passed
         total_failed += failed
         
         status = GREEN if failed == 0 else RED
-        print(f"{role:<15} {len(results):<10} {status}{passed:<10}{RESET} {failed:<10}")
+        print(f"{group:<15} {len(results):<10} {status}{passed:<10}{RESET} {failed:<10}")
     
     print("-" * 50)
     print(f"{'TOTAL':<15} {total_passed + total_failed:<10} {GREEN}{total_passed:<10}{RESET} {RED}{total_failed:<10}{RESET}")
@@ -577,7 +1961,10 @@ def main():
             "newadminpass123",
             "Super Admin"
         )
-        test_results["Super Admin"]["change_password"] = result
+        test_results["Super Admin"]["change_password"] = result is not None
+        if result:
+            super_admin_data["access_token"] = result["access_token"]
+            super_admin_data["refresh_token"] = result["refresh_token"]
 
     # Test 5: Super Admin - Refresh Token
     if super_admin_data:
@@ -635,10 +2022,11 @@ def main():
                     new_password,
                     role
                 )
-                test_results[role]["change_password"] = result
+                test_results[role]["change_password"] = result is not None
                 
-                # Test token refresh
-                result = test_refresh_token(user_data["refresh_token"], role)
+                # Test token refresh (tokens issued before the password change are revoked)
+                if result:
+                    result = test_refresh_token(result["refresh_token"], role)
                 test_results[role]["token_refresh"] = result is not None
 
     # Test Get All Users endpoint
@@ -681,6 +2069,61 @@ def main():
                 admin_created_user["username"]
             )
 
+    # Test forced password change for admin-created users
+    print_header("Testing Forced Password Change")
+
+    if super_admin_data:
+        test_results["Password Change"] = {
+            "created_flagged": False,
+            "login_restricted": False,
+            "token_rejected": False,
+            "same_password_rejected": False,
+            "forced_change": False,
+            "login_after_change": False,
+            "created_unflagged": False,
+            "login_unflagged": False
+        }
+        results = test_results["Password Change"]
+
+        # Admin-created users must pick their own password on first login by default
+        email = f"forcedchange_{RUN_ID}@example.com"
+        password = "adminpass123"
+        flagged_user = test_create_user_admin(
+            super_admin_data["access_token"],
+            f"forcedchange_{RUN_ID}",
+            email,
+            password,
+            "User"
+        )
+        results["created_flagged"] = flagged_user is not None and flagged_user["must_change_password"]
+
+        if flagged_user:
+            token = test_login_requires_password_change(email, password, "must_change_password")
+            results["login_restricted"] = token is not None
+
+            if token:
+                results["token_rejected"] = test_password_change_token_rejected(token)
+                results["same_password_rejected"] = test_forced_change_rejects_same_password(token, password)
+
+                result = test_change_password(token, password, "chosenpass123", "Forced Change")
+                results["forced_change"] = result is not None and "access_token" in result
+
+                results["login_after_change"] = test_user_login(email, "chosenpass123", "User") is not None
+
+        # The admin can let the user keep the initial password
+        email = f"keepspassword_{RUN_ID}@example.com"
+        unflagged_user = test_create_user_admin(
+            super_admin_data["access_token"],
+            f"keepspassword_{RUN_ID}",
+            email,
+            password,
+            "User",
+            must_change_password=False
+        )
+        results["created_unflagged"] = unflagged_user is not None and not unflagged_user["must_change_password"]
+        if unflagged_user:
+            results["login_unflagged"] = test_user_login(email, password, "User") is not None
+
     # Test unauthorized access to admin endpoints
     print_header("Testing Authorization & Access Control")
     
@@ -727,6 +2170,26 @@ def main():
                 test_username
             )
 
+    # Test the features added on top of the basic flows
+    if super_admin_data:
+        run_signing_key_tests(super_admin_data)
+        run_refresh_token_tests(super_admin_data)
+        run_logout_tests(super_admin_data)
+        run_token_version_tests(super_admin_data)
+        run_introspection_tests(super_admin_data)
+        run_registered_claims_tests(super_admin_data)
+        run_oauth_tests(super_admin_data)
+        run_impersonation_tests(super_admin_data)
+        run_service_account_tests(super_admin_data)
+        run_api_key_tests(super_admin_data)
+        run_password_policy_tests(super_admin_data)
+        run_lockout_tests(super_admin_data)
+        run_mfa_tests(super_admin_data)
+        run_webauthn_tests(super_admin_data)
+        run_email_verification_tests(super_admin_data)
+        # Last, since it uses up this client's /login allowance
+        run_rate_limit_tests(super_admin_data)
+
     # Print summary
     print_test_results_summary()
 
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"go-auth/models"
+	"log"
+	"net"
+	"net/http"
+)
+
+// Logger records security-relevant events in the audit log.
+// Recording is best effort: failures are logged and never fail the request.
+type Logger struct {
+	db *sql.DB
+}
+
+// NewLogger creates an audit logger backed by the audit_log table
+func NewLogger(db *sql.DB) *Logger {
+	return &Logger{db: db}
+}
+
+// Record stores the entry, stamping it with the request's client IP when r is not nil
+func (l *Logger) Record(r *http.Request, entry *models.AuditLogEntry) {
+	if r != nil && entry.IPAddress == "" {
+		entry.IPAddress = clientIP(r)
+	}
+
+	if err := queries.CreateAuditLog(l.db, entry); err != nil {
+		log.Printf("Failed to record audit event %s: %v", entry.Action, err)
+	}
+}
+
+// clientIP returns the IP address of the connection's remote end
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,33 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCorpus is returned when a corpus line is not in "HASH:COUNT" form
+var ErrInvalidCorpus = errors.New("invalid breach corpus format")
+
+// Checker reports whether a password appears in a breach corpus
+type Checker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// hashPassword returns the uppercase hex SHA-1 of password, as used by the HIBP corpus
+func hashPassword(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// parseLine splits a corpus line into its hash (or hash suffix) and whether it was seen at all.
+// The HIBP range files pad responses with zero-count entries, which don't count as breached.
+func parseLine(line string) (string, bool, error) {
+	line = strings.TrimRight(line, "\r")
+	hash, count, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", false, ErrInvalidCorpus
+	}
+	return strings.ToUpper(hash), strings.TrimLeft(count, "0") != "", nil
+}
@@ -0,0 +1,30 @@
+package breach
+
+import (
+	"fmt"
+	"os"
+)
+
+// Open loads the breach corpus at path, which is either:
+//   - a directory of HIBP range files named by 5-character SHA-1 prefix (00000.txt … FFFFF.txt),
+//     each holding "SUFFIX:COUNT" lines, or
+//   - a single file of "HASH:COUNT" lines sorted by hash, as written by the HIBP downloader.
+//
+// Neither form is loaded into memory: range files are selected by name and the single file
+// is binary searched on disk.
+func Open(path string) (Checker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach corpus: %w", err)
+	}
+
+	if info.IsDir() {
+		return &rangeDirectory{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach corpus: %w", err)
+	}
+	return &sortedFile{file: file, size: info.Size()}, nil
+}
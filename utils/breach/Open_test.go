@@ -0,0 +1,127 @@
+package breach
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+// corpusLines returns sorted "HASH:COUNT" lines for n breached passwords named
+// breached-0 … breached-(n-1), plus a zero-count padding line for "padding"
+func corpusLines(n int) []string {
+	lines := []string{hashPassword("padding") + ":0"}
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("%s:%d", hashPassword(fmt.Sprintf("breached-%d", i)), i+1))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func TestSortedFile(t *testing.T) {
+	const n = 500
+	lines := corpusLines(n)
+
+	variants := map[string]string{
+		"trailing newline":    strings.Join(lines, "\n") + "\n",
+		"no trailing newline": strings.Join(lines, "\n"),
+		"CRLF":                strings.Join(lines, "\r\n") + "\r\n",
+	}
+	for name, content := range variants {
+		checker, err := Open(writeFile(t, t.TempDir(), "pwned.txt", content))
+		if err != nil {
+			t.Fatalf("%s: Open: %v", name, err)
+		}
+
+		for i := 0; i < n; i++ {
+			if breached, err := checker.IsBreached(fmt.Sprintf("breached-%d", i)); !breached || err != nil {
+				t.Fatalf("%s: breached-%d: got %v, %v", name, i, breached, err)
+			}
+		}
+		for _, password := range []string{"padding", "not-breached", ""} {
+			if breached, err := checker.IsBreached(password); breached || err != nil {
+				t.Errorf("%s: %q: got %v, %v", name, password, breached, err)
+			}
+		}
+	}
+}
+
+func TestSortedFileSingleLine(t *testing.T) {
+	checker, err := Open(writeFile(t, t.TempDir(), "pwned.txt", hashPassword("password")+":3861493\n"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if breached, err := checker.IsBreached("password"); !breached || err != nil {
+		t.Errorf("got %v, %v", breached, err)
+	}
+	if breached, err := checker.IsBreached("Password"); breached || err != nil {
+		t.Errorf("other password: got %v, %v", breached, err)
+	}
+}
+
+func TestRangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	// Group the lines into range files by prefix, keeping only the suffix on each line
+	files := make(map[string][]string)
+	for _, line := range corpusLines(50) {
+		files[line[:5]] = append(files[line[:5]], line[5:])
+	}
+	for prefix, lines := range files {
+		writeFile(t, dir, prefix+".txt", strings.Join(lines, "\r\n"))
+	}
+
+	checker, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		if breached, err := checker.IsBreached(fmt.Sprintf("breached-%d", i)); !breached || err != nil {
+			t.Fatalf("breached-%d: got %v, %v", i, breached, err)
+		}
+	}
+	// A zero-count entry, and a prefix without a range file
+	for _, password := range []string{"padding", "not-breached"} {
+		if breached, err := checker.IsBreached(password); breached || err != nil {
+			t.Errorf("%q: got %v, %v", password, breached, err)
+		}
+	}
+}
+
+func TestInvalidCorpus(t *testing.T) {
+	dir := t.TempDir()
+	hash := hashPassword("password")
+	writeFile(t, dir, hash[:5]+".txt", hash[5:]+"\n")
+
+	checker, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := checker.IsBreached("password"); err != ErrInvalidCorpus {
+		t.Errorf("range file: got %v, want ErrInvalidCorpus", err)
+	}
+
+	checker, err = Open(writeFile(t, dir, "pwned.txt", hash+"\n"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := checker.IsBreached("password"); err != ErrInvalidCorpus {
+		t.Errorf("sorted file: got %v, want ErrInvalidCorpus", err)
+	}
+
+	if _, err := Open(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("Open of a missing corpus succeeded")
+	}
+}
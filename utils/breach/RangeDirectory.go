@@ -0,0 +1,44 @@
+package breach
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// rangeDirectory looks passwords up in per-prefix HIBP range files
+type rangeDirectory struct {
+	dir string
+}
+
+// IsBreached scans the range file for the password hash's 5-character prefix
+func (d *rangeDirectory) IsBreached(password string) (bool, error) {
+	hash := hashPassword(password)
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open breach range file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, seen, err := parseLine(scanner.Text())
+		if err != nil {
+			return false, err
+		}
+		if lineSuffix == suffix {
+			return seen, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breach range file: %w", err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,93 @@
+package breach
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxLineLength bounds a "HASH:COUNT" line (40 hex characters, a colon and the count)
+const maxLineLength = 64
+
+// sortedFile binary searches a single file of "HASH:COUNT" lines sorted by hash.
+// Lookups only use ReadAt, so one sortedFile is safe for concurrent use.
+type sortedFile struct {
+	file *os.File
+	size int64
+}
+
+// IsBreached binary searches the file by byte offset for the password hash
+func (f *sortedFile) IsBreached(password string) (bool, error) {
+	hash := hashPassword(password)
+
+	// Invariant: the line holding hash, if any, starts in [lo, hi)
+	lo, hi := int64(0), f.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		line, start, err := f.lineAt(mid)
+		if err != nil {
+			return false, err
+		}
+		if line == nil {
+			// No line starts at or after mid
+			hi = mid
+			continue
+		}
+
+		lineHash, seen, err := parseLine(string(line))
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case lineHash == hash:
+			return seen, nil
+		case lineHash < hash:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return false, nil
+}
+
+// lineAt returns the first line starting at or after offset, and where it starts
+func (f *sortedFile) lineAt(offset int64) ([]byte, int64, error) {
+	// Read from one byte earlier so a line starting exactly at offset is recognised
+	readFrom := offset
+	if offset > 0 {
+		readFrom = offset - 1
+	}
+
+	buf := make([]byte, 2*maxLineLength)
+	n, err := f.file.ReadAt(buf, readFrom)
+	if err != nil && err != io.EOF {
+		return nil, 0, fmt.Errorf("failed to read breach corpus: %w", err)
+	}
+	buf = buf[:n]
+
+	start := readFrom
+	if offset > 0 {
+		newline := bytes.IndexByte(buf, '\n')
+		if newline < 0 {
+			return nil, 0, nil
+		}
+		buf = buf[newline+1:]
+		start = readFrom + int64(newline) + 1
+	}
+	if len(buf) == 0 {
+		return nil, 0, nil
+	}
+
+	end := bytes.IndexByte(buf, '\n')
+	if end < 0 {
+		if start+int64(len(buf)) < f.size {
+			return nil, 0, ErrInvalidCorpus
+		}
+		end = len(buf) // Last line without a trailing newline
+	}
+
+	return buf[:end], start, nil
+}
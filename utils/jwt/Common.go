@@ -3,7 +3,10 @@ package jwt
 import "errors"
 
 var (
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrExpiredToken    = errors.New("token expired")
-	ErrInvalidClaims   = errors.New("invalid claims")
-)
\ No newline at end of file
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrExpiredToken     = errors.New("token expired")
+	ErrInvalidClaims    = errors.New("invalid claims")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+	ErrInvalidTokenType = errors.New("invalid token type")
+)
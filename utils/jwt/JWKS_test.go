@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestThumbprintRFC7638(t *testing.T) {
+	// The example key of RFC 7638 section 3.1
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+
+	thumbprint, err := Thumbprint(key)
+	if err != nil {
+		t.Fatalf("Thumbprint: %v", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; thumbprint != want {
+		t.Fatalf("got %s, want %s", thumbprint, want)
+	}
+}
+
+func TestPublicJWKSOmitsSecrets(t *testing.T) {
+	signers := testSigners(t)
+	hmacKey := NewHMACKey("hmac", "test-secret-with-enough-entropy", time.Time{})
+	keys := NewKeySet(hmacKey)
+	for algorithm, signer := range signers {
+		privatePath, _ := writePEM(t, signer)
+		key, err := LoadSigningKey(algorithm, "", "", privatePath)
+		if err != nil {
+			t.Fatalf("%s: LoadSigningKey: %v", algorithm, err)
+		}
+		keys.Keys = append(keys.Keys, key)
+	}
+
+	set := keys.PublicJWKS()
+	if len(set.Keys) != len(signers) {
+		t.Fatalf("got %d keys, want only the %d asymmetric ones", len(set.Keys), len(signers))
+	}
+	want := map[string]string{"RS256": "RSA", "ES256": "EC", "EdDSA": "OKP"}
+	for _, jwk := range set.Keys {
+		if jwk.KeyID == "" || jwk.Use != "sig" || want[jwk.Algorithm] != jwk.KeyType {
+			t.Errorf("unexpected key %+v", jwk)
+		}
+	}
+}
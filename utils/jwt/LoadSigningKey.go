@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// LoadSigningKey builds the active signing key for the configured algorithm.
+// HS256 uses the shared secret; RS256, ES256 and EdDSA read a PEM private key.
+// When keyID is empty, asymmetric keys get their RFC 7638 thumbprint as kid.
+func LoadSigningKey(algorithm, keyID, secret, privateKeyPath string) (*SigningKey, error) {
+	if strings.EqualFold(algorithm, "HS256") {
+		if secret == "" {
+			return nil, fmt.Errorf("HS256 requires a secret")
+		}
+		return NewHMACKey(keyID, secret, time.Time{}), nil
+	}
+
+	data, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	privateKey, err := parsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	method, err := methodForPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(method.Alg(), algorithm) {
+		return nil, fmt.Errorf("private key is a %s key but JWT_ALGORITHM is %s", method.Alg(), algorithm)
+	}
+
+	key := &SigningKey{
+		ID:         keyID,
+		Method:     method,
+		PrivateKey: privateKey,
+		PublicKey:  signer.Public(),
+	}
+	if key.ID == "" {
+		if key.ID, err = Thumbprint(key.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// LoadVerificationKeys reads PEM public keys that are accepted but never used for signing.
+// This keeps tokens signed by a previous key pair valid while it is being rotated out.
+func LoadVerificationKeys(paths []string) ([]*SigningKey, error) {
+	var keys []*SigningKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key %s: %w", path, err)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("verification key %s is not PEM encoded", path)
+		}
+
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s: %w", path, err)
+		}
+
+		method, err := methodForPublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		kid, err := Thumbprint(publicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &SigningKey{
+			ID:        kid,
+			Method:    method,
+			PublicKey: publicKey,
+		})
+	}
+	return keys, nil
+}
+
+// parsePrivateKeyPEM decodes PKCS#8, PKCS#1 (RSA) and SEC 1 (EC) private keys
+func parsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("failed to parse private key: unsupported format %q", block.Type)
+}
+
+// methodForPublicKey maps a public key to the JWS algorithm used with it
+func methodForPublicKey(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: only P-256 ECDSA keys are supported", ErrUnsupportedKey)
+		}
+		return jwt.SigningMethodES256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
@@ -0,0 +1,135 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"go-auth/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePEM writes a PKCS#8 private key and its PKIX public key to a temporary directory
+func writePEM(t *testing.T, signer crypto.Signer) (privatePath, publicPath string) {
+	t.Helper()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath = filepath.Join(dir, "private.pem")
+	publicPath = filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func testSigners(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey}
+}
+
+func TestLoadSigningKeySignsAndVerifies(t *testing.T) {
+	for algorithm, signer := range testSigners(t) {
+		privatePath, _ := writePEM(t, signer)
+
+		key, err := LoadSigningKey(algorithm, "", "", privatePath)
+		if err != nil {
+			t.Fatalf("%s: LoadSigningKey: %v", algorithm, err)
+		}
+		thumbprint, err := Thumbprint(signer.Public())
+		if err != nil {
+			t.Fatalf("%s: Thumbprint: %v", algorithm, err)
+		}
+		if key.ID != thumbprint {
+			t.Errorf("%s: got kid %q, want the thumbprint %q", algorithm, key.ID, thumbprint)
+		}
+
+		keys := NewKeySet(key)
+		token, err := SignClaims(NewClaims(testUser(), models.AccessToken), keys)
+		if err != nil {
+			t.Fatalf("%s: SignClaims: %v", algorithm, err)
+		}
+		if _, err := VerifyToken(token, keys); err != nil {
+			t.Errorf("%s: VerifyToken: %v", algorithm, err)
+		}
+	}
+}
+
+func TestLoadSigningKeyRejectsAlgorithmMismatch(t *testing.T) {
+	signers := testSigners(t)
+	privatePath, _ := writePEM(t, signers["ES256"])
+
+	if _, err := LoadSigningKey("RS256", "", "", privatePath); err == nil {
+		t.Fatal("loaded an ES256 key as RS256")
+	}
+	if _, err := LoadSigningKey("HS256", "", "", ""); err == nil {
+		t.Fatal("loaded HS256 without a secret")
+	}
+}
+
+func TestVerificationKeysAcceptTokensOfThePreviousKey(t *testing.T) {
+	signers := testSigners(t)
+	oldPrivate, oldPublic := writePEM(t, signers["ES256"])
+	newPrivate, _ := writePEM(t, signers["EdDSA"])
+
+	oldKey, err := LoadSigningKey("ES256", "", "", oldPrivate)
+	if err != nil {
+		t.Fatalf("LoadSigningKey: %v", err)
+	}
+	token, err := SignClaims(NewClaims(testUser(), models.AccessToken), NewKeySet(oldKey))
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+
+	newKey, err := LoadSigningKey("EdDSA", "", "", newPrivate)
+	if err != nil {
+		t.Fatalf("LoadSigningKey: %v", err)
+	}
+	if _, err := VerifyToken(token, NewKeySet(newKey)); err == nil {
+		t.Fatal("token of the old key accepted without its verification key")
+	}
+
+	verificationKeys, err := LoadVerificationKeys([]string{oldPublic})
+	if err != nil {
+		t.Fatalf("LoadVerificationKeys: %v", err)
+	}
+	keys := NewKeySet(newKey, verificationKeys...)
+	if _, err := VerifyToken(token, keys); err != nil {
+		t.Fatalf("VerifyToken with the old public key: %v", err)
+	}
+
+	// Verification keys are published but never sign
+	if len(keys.PublicJWKS().Keys) != 2 {
+		t.Fatalf("got %d published keys, want 2", len(keys.PublicJWKS().Keys))
+	}
+}
@@ -0,0 +1,31 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewAPIKeyClaims builds the request claims for an API key, equivalent to an access token
+// for its owner but restricted to the key's role and scopes. They are never signed.
+func NewAPIKeyClaims(owner *models.User, apiKey *models.APIKey) *models.Claims {
+	claims := &models.Claims{
+		UserID:       owner.ID,
+		Username:     owner.Username,
+		Email:        owner.Email,
+		Role:         apiKey.Role,
+		TokenType:    models.AccessToken,
+		Principal:    models.PrincipalUser,
+		TokenVersion: owner.TokenVersion,
+		Scope:        strings.Join(apiKey.Scopes, " "),
+		APIKeyID:     apiKey.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: owner.ID,
+		},
+	}
+	if apiKey.ExpiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*apiKey.ExpiresAt)
+	}
+	return claims
+}
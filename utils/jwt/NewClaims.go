@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewClaims builds the standard claims for a user token.
+// Callers can adjust the result before passing it to SignClaims.
+func NewClaims(user *models.User, tokenType models.TokenType) *models.Claims {
+	now := time.Now()
+	var expirationTime time.Time
+
+	// Set expiration based on token type
+	if tokenType == models.AccessToken {
+		expirationTime = now.Add(models.AccessTokenDuration)
+	} else {
+		expirationTime = now.Add(models.RefreshTokenDuration)
+	}
+
+	emailVerified := user.EmailVerified()
+
+	return &models.Claims{
+		UserID:        user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          user.Role,
+		TokenType:     tokenType,
+		Principal:     models.PrincipalUser,
+		TokenVersion:  user.TokenVersion,
+		EmailVerified: &emailVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        secure.NewID(), // jti, used for revocation
+			Subject:   user.ID,
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+// NewSessionClaims builds the user's claims with the per-session options applied
+func NewSessionClaims(user *models.User, tokenType models.TokenType, opts models.TokenOptions) *models.Claims {
+	claims := NewClaims(user, tokenType)
+	claims.Audience = opts.Audience
+	claims.Scope = opts.Scope
+	claims.ClientID = opts.ClientID
+	if !opts.AuthTime.IsZero() {
+		claims.AuthTime = jwt.NewNumericDate(opts.AuthTime)
+	}
+	if len(opts.AMR) > 0 {
+		claims.AMR = opts.AMR
+		claims.ACR = models.ACRForAMR(opts.AMR)
+	}
+	return claims
+}
+
+// newRestrictedClaims builds the claims of a single-purpose token that expires after duration.
+// They carry no role or audience, so services that check either never accept them.
+func newRestrictedClaims(user *models.User, tokenType models.TokenType, duration time.Duration) *models.Claims {
+	claims := NewClaims(user, tokenType)
+	claims.Role = ""
+	claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(duration))
+	return claims
+}
@@ -0,0 +1,9 @@
+package jwt
+
+import "go-auth/models"
+
+// NewEmailVerificationClaims builds the signed token sent in a verification email. It is
+// only accepted by /email/verify, and only while the user's email is still the one it was sent to.
+func NewEmailVerificationClaims(user *models.User) *models.Claims {
+	return newRestrictedClaims(user, models.EmailVerificationToken, models.EmailVerificationTokenDuration)
+}
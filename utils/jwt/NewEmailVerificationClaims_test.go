@@ -0,0 +1,34 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"testing"
+)
+
+func TestNewEmailVerificationClaims(t *testing.T) {
+	keys := testKeySet()
+	user := testUser()
+
+	token, err := SignClaims(NewEmailVerificationClaims(user), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+
+	if claims.TokenType != models.EmailVerificationToken || !claims.TokenType.IsRestricted() {
+		t.Errorf("token type = %q, want a restricted %q", claims.TokenType, models.EmailVerificationToken)
+	}
+	// The address it was sent to is checked when the token is used
+	if claims.Email != user.Email || claims.UserID != user.ID {
+		t.Errorf("got user %q email %q, want %q %q", claims.UserID, claims.Email, user.ID, user.Email)
+	}
+	if claims.Role != "" || len(claims.Audience) != 0 {
+		t.Errorf("got role %q audience %v, want neither", claims.Role, claims.Audience)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != models.EmailVerificationTokenDuration {
+		t.Errorf("lifetime = %v, want %v", lifetime, models.EmailVerificationTokenDuration)
+	}
+}
@@ -0,0 +1,39 @@
+package jwt
+
+import (
+	"fmt"
+	"time"
+)
+
+// HMACSecret is one shared secret in a rotating keyring
+type HMACSecret struct {
+	KeyID    string
+	Secret   string
+	RetireAt time.Time
+}
+
+// NewHMACKeyring builds a key set from several HMAC secrets.
+// The secret named by activeKID signs new tokens; the others are only
+// accepted for verification until their retirement time.
+func NewHMACKeyring(activeKID string, secrets []HMACSecret) (*KeySet, error) {
+	var active *SigningKey
+	var others []*SigningKey
+
+	for _, secret := range secrets {
+		key := NewHMACKey(secret.KeyID, secret.Secret, secret.RetireAt)
+		if secret.KeyID == activeKID {
+			active = key
+			continue
+		}
+		others = append(others, key)
+	}
+
+	if active == nil {
+		return nil, fmt.Errorf("%w: active kid %q not in keyring", ErrUnknownKey, activeKID)
+	}
+	if active.IsRetired() {
+		return nil, fmt.Errorf("active kid %q is retired", activeKID)
+	}
+
+	return NewKeySet(active, others...), nil
+}
@@ -0,0 +1,77 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"testing"
+	"time"
+)
+
+func TestHMACKeyringRotation(t *testing.T) {
+	retired := time.Now().Add(-time.Minute)
+	secrets := []HMACSecret{
+		{KeyID: "2026-09", Secret: "retired-secret-with-enough-entropy", RetireAt: retired},
+		{KeyID: "2026-10", Secret: "previous-secret-with-enough-entropy", RetireAt: time.Now().Add(time.Hour)},
+		{KeyID: "2026-11", Secret: "current-secret-with-enough-entropy"},
+	}
+	keys, err := NewHMACKeyring("2026-11", secrets)
+	if err != nil {
+		t.Fatalf("NewHMACKeyring: %v", err)
+	}
+
+	signWith := func(secret HMACSecret) string {
+		t.Helper()
+		token, err := SignClaims(NewClaims(testUser(), models.AccessToken), NewKeySet(NewHMACKey(secret.KeyID, secret.Secret, time.Time{})))
+		if err != nil {
+			t.Fatalf("SignClaims: %v", err)
+		}
+		return token
+	}
+
+	token, err := SignClaims(NewClaims(testUser(), models.AccessToken), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	if _, err := VerifyToken(token, keys); err != nil {
+		t.Errorf("token of the active secret: %v", err)
+	}
+	if _, err := VerifyToken(signWith(secrets[1]), keys); err != nil {
+		t.Errorf("token of a secret that is being rotated out: %v", err)
+	}
+	if _, err := VerifyToken(signWith(secrets[0]), keys); err == nil {
+		t.Error("token of a retired secret accepted")
+	}
+	if _, err := VerifyToken(signWith(HMACSecret{KeyID: "unknown", Secret: secrets[2].Secret}), keys); err == nil {
+		t.Error("token with an unknown kid accepted")
+	}
+
+	// A secret can't sign for another kid
+	forged := HMACSecret{KeyID: "2026-11", Secret: "guessed-secret-with-enough-entropy"}
+	if _, err := VerifyToken(signWith(forged), keys); err == nil {
+		t.Error("token signed with the wrong secret accepted")
+	}
+}
+
+func TestHMACKeyringActiveKey(t *testing.T) {
+	secrets := []HMACSecret{
+		{KeyID: "old", Secret: "old-secret-with-enough-entropy", RetireAt: time.Now().Add(-time.Minute)},
+		{KeyID: "new", Secret: "new-secret-with-enough-entropy"},
+	}
+	if _, err := NewHMACKeyring("missing", secrets); err == nil {
+		t.Error("keyring built without its active secret")
+	}
+	if _, err := NewHMACKeyring("old", secrets); err == nil {
+		t.Error("keyring built with a retired active secret")
+	}
+
+	keys, err := NewHMACKeyring("new", secrets)
+	if err != nil {
+		t.Fatalf("NewHMACKeyring: %v", err)
+	}
+	if keys.Active.ID != "new" || len(keys.Keys) != 2 {
+		t.Fatalf("got active %q and %d keys", keys.Active.ID, len(keys.Keys))
+	}
+	// Tokens from before kid headers were added are checked against the active secret
+	if keys.Lookup("") != keys.Active {
+		t.Error("a token without kid is not checked against the active secret")
+	}
+}
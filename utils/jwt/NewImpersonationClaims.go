@@ -0,0 +1,19 @@
+package jwt
+
+import (
+	"go-auth/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewImpersonationClaims builds a short-lived access token for the target user
+// with an act claim identifying the admin acting as them
+func NewImpersonationClaims(target *models.User, actor *models.Claims) *models.Claims {
+	claims := NewClaims(target, models.AccessToken)
+	claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(models.ImpersonationTokenDuration))
+	claims.Act = &models.Actor{
+		Subject: actor.UserID,
+		Email:   actor.Email,
+	}
+	return claims
+}
@@ -0,0 +1,31 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"testing"
+)
+
+func TestNewImpersonationClaims(t *testing.T) {
+	keys := testKeySet()
+	admin := NewClaims(&models.User{ID: "7d0c5c8e-0000-4000-8000-000000000009", Email: "admin@example.com", Role: "Super Admin"}, models.AccessToken)
+	target := testUser()
+
+	token, err := SignClaims(NewImpersonationClaims(target, admin), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+
+	if claims.UserID != target.ID || claims.Role != target.Role || claims.TokenType != models.AccessToken {
+		t.Errorf("got sub %q role %q type %q, want the target's access token", claims.UserID, claims.Role, claims.TokenType)
+	}
+	if !claims.IsImpersonated() || claims.Act.Subject != admin.UserID || claims.Act.Email != admin.Email {
+		t.Errorf("got act %+v, want the admin", claims.Act)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != models.ImpersonationTokenDuration {
+		t.Errorf("lifetime = %s, want %s", lifetime, models.ImpersonationTokenDuration)
+	}
+}
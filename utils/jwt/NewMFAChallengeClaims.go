@@ -0,0 +1,12 @@
+package jwt
+
+import "go-auth/models"
+
+// NewMFAChallengeClaims builds a short-lived token proving the user's password was checked.
+// It is only accepted by /login/mfa and carries the audience requested at login.
+func NewMFAChallengeClaims(user *models.User, audience []string) *models.Claims {
+	claims := newRestrictedClaims(user, models.MFAChallengeToken, models.MFAChallengeTokenDuration)
+	claims.LoginAudience = audience
+	claims.AMR = models.PasswordAMR
+	return claims
+}
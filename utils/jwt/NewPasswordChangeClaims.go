@@ -0,0 +1,20 @@
+package jwt
+
+import (
+	"go-auth/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewPasswordChangeClaims builds a short-lived restricted token that is only accepted
+// by /change-password, for users who must change their password before signing in.
+// It carries the audience requested at login and how the user authenticated, which the
+// token pair issued after the change inherits.
+func NewPasswordChangeClaims(user *models.User, audience, amr []string) *models.Claims {
+	claims := newRestrictedClaims(user, models.PasswordChangeToken, models.PasswordChangeTokenDuration)
+	claims.LoginAudience = audience
+	claims.AuthTime = jwt.NewNumericDate(claims.IssuedAt.Time)
+	claims.AMR = amr
+	claims.ACR = models.ACRForAMR(amr)
+	return claims
+}
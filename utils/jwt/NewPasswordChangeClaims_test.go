@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"reflect"
+	"testing"
+)
+
+func TestNewPasswordChangeClaims(t *testing.T) {
+	keys := testKeySet()
+	audience := []string{"billing-app"}
+
+	token, err := SignClaims(NewPasswordChangeClaims(testUser(), audience, models.PasswordAMR), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+
+	if claims.TokenType != models.PasswordChangeToken || !claims.TokenType.IsRestricted() {
+		t.Errorf("token type = %q, want a restricted %q", claims.TokenType, models.PasswordChangeToken)
+	}
+	// The restricted token grants no role, so no other endpoint can use it
+	if claims.Role != "" {
+		t.Errorf("role = %q, want none", claims.Role)
+	}
+	if lifetime := claims.ExpiresAt.Sub(claims.IssuedAt.Time); lifetime != models.PasswordChangeTokenDuration {
+		t.Errorf("lifetime = %v, want %v", lifetime, models.PasswordChangeTokenDuration)
+	}
+
+	// The token pair issued after the change inherits the login's audience and methods
+	opts := claims.SessionOptions()
+	if !reflect.DeepEqual(opts.Audience, audience) {
+		t.Errorf("session audience = %v, want %v", opts.Audience, audience)
+	}
+	if !reflect.DeepEqual(opts.AMR, models.PasswordAMR) || claims.ACR != models.ACRSingleFactor {
+		t.Errorf("got amr %v acr %q, want %v %q", opts.AMR, claims.ACR, models.PasswordAMR, models.ACRSingleFactor)
+	}
+	if claims.AuthTime == nil || opts.AuthTime.IsZero() {
+		t.Error("auth_time is not set")
+	}
+}
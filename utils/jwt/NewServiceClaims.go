@@ -0,0 +1,33 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewServiceClaims builds the claims for a service account access token.
+// The account ID takes the place of the user ID and the token is marked as a service principal.
+func NewServiceClaims(account *models.ServiceAccount, scope string) *models.Claims {
+	now := time.Now()
+
+	return &models.Claims{
+		UserID:       account.ID,
+		Username:     account.Name,
+		Role:         account.Role,
+		TokenType:    models.AccessToken,
+		Principal:    models.PrincipalService,
+		TokenVersion: account.TokenVersion,
+		Scope:        scope,
+		ClientID:     account.ClientID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        secure.NewID(),
+			Subject:   account.ID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(models.AccessTokenDuration)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
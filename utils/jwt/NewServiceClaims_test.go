@@ -0,0 +1,30 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"testing"
+)
+
+func TestNewServiceClaims(t *testing.T) {
+	keys := testKeySet()
+	account := &models.ServiceAccount{ID: "7d0c5c8e-0000-4000-8000-000000000005", ClientID: "svc-reports", Name: "reports", Role: "User", TokenVersion: 3}
+
+	token, err := SignClaims(NewServiceClaims(account, "read"), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+
+	if !claims.IsService() || claims.UserID != account.ID || claims.Subject != account.ID {
+		t.Errorf("got principal %q sub %q, want the service account", claims.Principal, claims.Subject)
+	}
+	if claims.ClientID != account.ClientID || claims.Scope != "read" || claims.Role != "User" || claims.TokenVersion != 3 {
+		t.Errorf("got client_id %q scope %q role %q version %d", claims.ClientID, claims.Scope, claims.Role, claims.TokenVersion)
+	}
+	if claims.Email != "" || claims.EmailVerified != nil || claims.TokenType != models.AccessToken {
+		t.Errorf("got email %q email_verified %v type %q", claims.Email, claims.EmailVerified, claims.TokenType)
+	}
+}
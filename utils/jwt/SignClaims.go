@@ -0,0 +1,37 @@
+package jwt
+
+import (
+	"go-auth/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignClaims signs the claims with the active key and stamps its kid header.
+// The issuer and, except for restricted tokens, the default audiences are filled in
+// from the key set when unset.
+func SignClaims(claims *models.Claims, keys *KeySet) (string, error) {
+	if claims.Issuer == "" {
+		claims.Issuer = keys.Issuer
+	}
+	if len(claims.Audience) == 0 && len(keys.Audience) > 0 && !claims.TokenType.IsRestricted() {
+		claims.Audience = jwt.ClaimStrings(keys.Audience)
+	}
+
+	return signToken(claims, keys)
+}
+
+// signToken signs any claims with the active key and stamps its kid header
+func signToken(claims jwt.Claims, keys *KeySet) (string, error) {
+	signingKey := keys.Active
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	if signingKey.ID != "" {
+		token.Header["kid"] = signingKey.ID
+	}
+
+	tokenString, err := token.SignedString(signingKey.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
@@ -0,0 +1,49 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"go-auth/utils/secure"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SignIDToken builds and signs an OpenID Connect ID token for the user, addressed to the client.
+// The email and profile scopes control which user claims are included; amr and acr describe
+// how the user authenticated when known.
+func SignIDToken(user *models.User, clientID, scope, nonce string, authTime time.Time, amr []string, keys *KeySet) (string, error) {
+	now := time.Now()
+
+	claims := &models.IDTokenClaims{
+		Nonce: nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        secure.NewID(),
+			Issuer:    keys.Issuer,
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(models.IDTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	if !authTime.IsZero() {
+		claims.AuthTime = jwt.NewNumericDate(authTime)
+	}
+	if len(amr) > 0 {
+		claims.AMR = amr
+		claims.ACR = models.ACRForAMR(amr)
+	}
+
+	for _, s := range strings.Fields(scope) {
+		switch s {
+		case "email":
+			emailVerified := user.EmailVerified()
+			claims.Email = user.Email
+			claims.EmailVerified = &emailVerified
+		case "profile":
+			claims.PreferredUsername = user.Username
+		}
+	}
+
+	return signToken(claims, keys)
+}
@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSignIDToken(t *testing.T) {
+	keys := testKeySet()
+	user := testUser()
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		scope        string
+		wantEmail    bool
+		wantUsername bool
+	}{
+		{"openid", false, false},
+		{"openid email", true, false},
+		{"openid profile", false, true},
+		{"openid email profile", true, true},
+	}
+	for _, tt := range tests {
+		token, err := SignIDToken(user, "billing-app", tt.scope, "n-0S6_WzA2Mj", authTime, models.SecondFactorAMR(models.AMROTP), keys)
+		if err != nil {
+			t.Fatalf("%s: SignIDToken: %v", tt.scope, err)
+		}
+
+		claims := &models.IDTokenClaims{}
+		_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+			return keys.Active.PublicKey, nil
+		}, jwt.WithIssuer(keys.Issuer), jwt.WithAudience("billing-app"), jwt.WithExpirationRequired())
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tt.scope, err)
+		}
+
+		if claims.Subject != user.ID || claims.Nonce != "n-0S6_WzA2Mj" || len(claims.Audience) != 1 {
+			t.Errorf("%s: got sub %q nonce %q aud %v", tt.scope, claims.Subject, claims.Nonce, claims.Audience)
+		}
+		if claims.AuthTime == nil || !claims.AuthTime.Equal(authTime) {
+			t.Errorf("%s: got auth_time %v, want %v", tt.scope, claims.AuthTime, authTime)
+		}
+		if claims.ACR != models.ACRMultiFactor || len(claims.AMR) != 3 {
+			t.Errorf("%s: got amr %v acr %q", tt.scope, claims.AMR, claims.ACR)
+		}
+		if gotEmail := claims.Email == user.Email && claims.EmailVerified != nil; gotEmail != tt.wantEmail {
+			t.Errorf("%s: got email %q verified %v", tt.scope, claims.Email, claims.EmailVerified)
+		}
+		if gotUsername := claims.PreferredUsername == user.Username; gotUsername != tt.wantUsername {
+			t.Errorf("%s: got preferred_username %q", tt.scope, claims.PreferredUsername)
+		}
+	}
+}
+
+func TestSignIDTokenOmitsUnknownLoginDetails(t *testing.T) {
+	keys := testKeySet()
+
+	token, err := SignIDToken(testUser(), "billing-app", "openid", "", time.Time{}, nil, keys)
+	if err != nil {
+		t.Fatalf("SignIDToken: %v", err)
+	}
+	claims := &models.IDTokenClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return keys.Active.PublicKey, nil
+	}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if claims.AuthTime != nil || claims.Nonce != "" || claims.AMR != nil || claims.ACR != "" {
+		t.Errorf("got auth_time %v nonce %q amr %v acr %q, want none", claims.AuthTime, claims.Nonce, claims.AMR, claims.ACR)
+	}
+}
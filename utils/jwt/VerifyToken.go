@@ -1,26 +1,47 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
 	"go-auth/models"
-	"time"
+	"slices"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// VerifyToken validates a JWT token and returns the claims
-func VerifyToken(tokenString, secretKey string) (*models.Claims, error) {
+// VerifyToken validates a JWT token against the key set and returns the claims.
+// The issuer and audience must match the key set's policy when configured (restricted
+// tokens carry no audience), and exp/nbf are checked with the configured leeway for clock skew.
+func VerifyToken(tokenString string, keys *KeySet) (*models.Claims, error) {
 	claims := &models.Claims{}
 
+	options := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(keys.Leeway),
+	}
+	if keys.Issuer != "" {
+		options = append(options, jwt.WithIssuer(keys.Issuer))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method is what we expect
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Find the key named by the kid header
+		kid, _ := token.Header["kid"].(string)
+		key := keys.Lookup(kid)
+		if key == nil {
+			return nil, ErrUnknownKey
+		}
+
+		// Verify the signing method matches the key, never trust the alg header alone
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secretKey), nil
-	})
+		return key.PublicKey, nil
+	}, options...)
 
 	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
 		return nil, ErrInvalidToken
 	}
 
@@ -29,9 +50,9 @@ func VerifyToken(tokenString, secretKey string) (*models.Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	// Check if token has expired
-	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
-		return nil, ErrExpiredToken
+	// Access and refresh tokens must be meant for one of the configured audiences
+	if len(keys.Audience) > 0 && !claims.TokenType.IsRestricted() && !slices.ContainsFunc(claims.Audience, keys.HasAudience) {
+		return nil, ErrInvalidToken
 	}
 
 	return claims, nil
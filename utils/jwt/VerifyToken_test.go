@@ -0,0 +1,175 @@
+package jwt
+
+import (
+	"go-auth/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testKeySet() *KeySet {
+	keys := NewKeySet(NewHMACKey("k1", "test-secret-with-enough-entropy", time.Time{}))
+	keys.Issuer = "https://auth.example.com"
+	keys.Audience = []string{"billing-app", "crm-app"}
+	return keys
+}
+
+func testUser() *models.User {
+	return &models.User{ID: "7d0c5c8e-0000-4000-8000-000000000001", Username: "alice", Email: "alice@example.com", Role: "Admin"}
+}
+
+func TestSignClaimsStampsDefaultAudienceOnAccessTokens(t *testing.T) {
+	keys := testKeySet()
+
+	token, err := SignClaims(NewClaims(testUser(), models.AccessToken), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if len(claims.Audience) != 2 || claims.Role != "Admin" {
+		t.Fatalf("got aud %v role %q, want the default audiences and the user's role", claims.Audience, claims.Role)
+	}
+}
+
+func TestRestrictedTokensCarryNoRoleOrAudience(t *testing.T) {
+	keys := testKeySet()
+	user := testUser()
+
+	restricted := map[models.TokenType]*models.Claims{
+		models.MFAChallengeToken:      NewMFAChallengeClaims(user, []string{"billing-app"}),
+		models.PasswordChangeToken:    NewPasswordChangeClaims(user, nil, models.PasswordAMR),
+		models.EmailVerificationToken: NewEmailVerificationClaims(user),
+	}
+	for tokenType, claims := range restricted {
+		token, err := SignClaims(claims, keys)
+		if err != nil {
+			t.Fatalf("%s: SignClaims: %v", tokenType, err)
+		}
+
+		verified, err := VerifyToken(token, keys)
+		if err != nil {
+			t.Fatalf("%s: VerifyToken: %v", tokenType, err)
+		}
+		if verified.TokenType != tokenType || !verified.TokenType.IsRestricted() {
+			t.Errorf("%s: got token_type %q", tokenType, verified.TokenType)
+		}
+		if verified.Role != "" {
+			t.Errorf("%s: got role %q, want none", tokenType, verified.Role)
+		}
+		if len(verified.Audience) != 0 {
+			t.Errorf("%s: got aud %v, want none", tokenType, verified.Audience)
+		}
+	}
+}
+
+func TestMFAChallengeKeepsLoginAudience(t *testing.T) {
+	keys := testKeySet()
+
+	token, err := SignClaims(NewMFAChallengeClaims(testUser(), []string{"crm-app"}), keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if len(claims.LoginAudience) != 1 || claims.LoginAudience[0] != "crm-app" {
+		t.Fatalf("got login_aud %v, want [crm-app]", claims.LoginAudience)
+	}
+}
+
+func TestVerifyTokenRejectsAccessTokensForOtherAudiences(t *testing.T) {
+	keys := testKeySet()
+
+	for name, audience := range map[string][]string{"foreign": {"other-app"}, "missing": nil} {
+		claims := NewClaims(testUser(), models.AccessToken)
+		claims.Issuer = keys.Issuer
+		claims.Audience = audience
+		token, err := signToken(claims, keys)
+		if err != nil {
+			t.Fatalf("%s: signToken: %v", name, err)
+		}
+		if _, err := VerifyToken(token, keys); err != ErrInvalidToken {
+			t.Errorf("%s audience: got %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestNewClaimsSetsRegisteredClaims(t *testing.T) {
+	keys := testKeySet()
+	user := testUser()
+
+	first, second := NewClaims(user, models.AccessToken), NewClaims(user, models.AccessToken)
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("got jti %q and %q, want unique ids", first.ID, second.ID)
+	}
+
+	token, err := SignClaims(first, keys)
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	claims, err := VerifyToken(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if claims.Issuer != keys.Issuer || claims.Subject != user.ID || claims.ID != first.ID {
+		t.Errorf("got iss %q sub %q jti %q", claims.Issuer, claims.Subject, claims.ID)
+	}
+	if claims.NotBefore == nil || claims.IssuedAt == nil || claims.NotBefore.After(claims.IssuedAt.Time) {
+		t.Errorf("got nbf %v iat %v, want nbf at issuance", claims.NotBefore, claims.IssuedAt)
+	}
+}
+
+func TestVerifyTokenRejectsOtherIssuers(t *testing.T) {
+	keys := testKeySet()
+
+	for name, issuer := range map[string]string{"foreign": "https://evil.example.com", "missing": ""} {
+		claims := NewClaims(testUser(), models.AccessToken)
+		claims.Issuer = issuer
+		claims.Audience = keys.Audience
+		token, err := signToken(claims, keys)
+		if err != nil {
+			t.Fatalf("%s: signToken: %v", name, err)
+		}
+		if _, err := VerifyToken(token, keys); err != ErrInvalidToken {
+			t.Errorf("%s issuer: got %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestVerifyTokenAllowsClockSkewWithinLeeway(t *testing.T) {
+	keys := testKeySet()
+	keys.Leeway = time.Minute
+
+	tests := []struct {
+		name   string
+		adjust func(claims *models.Claims)
+		want   error
+	}{
+		{"expired within leeway", func(c *models.Claims) { c.ExpiresAt = jwtTime(-30 * time.Second) }, nil},
+		{"expired beyond leeway", func(c *models.Claims) { c.ExpiresAt = jwtTime(-2 * time.Minute) }, ErrExpiredToken},
+		{"not yet valid within leeway", func(c *models.Claims) { c.NotBefore = jwtTime(30 * time.Second) }, nil},
+		{"not yet valid beyond leeway", func(c *models.Claims) { c.NotBefore = jwtTime(2 * time.Minute) }, ErrInvalidToken},
+		{"no expiry", func(c *models.Claims) { c.ExpiresAt = nil }, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		claims := NewClaims(testUser(), models.AccessToken)
+		tt.adjust(claims)
+		token, err := SignClaims(claims, keys)
+		if err != nil {
+			t.Fatalf("%s: SignClaims: %v", tt.name, err)
+		}
+		if _, err := VerifyToken(token, keys); err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// jwtTime returns the current time shifted by offset as a NumericDate
+func jwtTime(offset time.Duration) *jwt.NumericDate {
+	return jwt.NewNumericDate(time.Now().Add(offset))
+}
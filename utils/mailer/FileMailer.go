@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+	"go-auth/utils/secure"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes each email as an .eml file to a directory, for local development and tests
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// NewFileMailer creates a mailer that writes messages to dir, creating it if needed
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+// Send writes the message to <timestamp>-<id>.eml
+func (m *FileMailer) Send(msg *Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), secure.NewID())
+	if err := os.WriteFile(filepath.Join(m.Dir, name), formatMessage(m.From, msg), 0o600); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	return nil
+}
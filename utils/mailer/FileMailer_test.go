@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("NewFileMailer: %v", err)
+	}
+
+	msg := &Message{
+		To:      "alice@example.com",
+		Subject: "Reset your password\r\nBcc: mallory@example.com",
+		Body:    "Hi alice,\n\nUse this token:\n\nabc123\n",
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Send(msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("got %d .eml files (%v), want one per message", len(files), err)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	header, body, found := strings.Cut(string(content), "\r\n\r\n")
+	if !found {
+		t.Fatalf("no blank line between header and body: %q", content)
+	}
+	for _, want := range []string{"From: no-reply@example.com", "To: alice@example.com", "Subject: Reset your passwordBcc: mallory@example.com"} {
+		if !strings.Contains(header, want+"\r\n") {
+			t.Errorf("header is missing %q:\n%s", want, header)
+		}
+	}
+	// Line breaks in the subject must not start a new header
+	if strings.Contains(header, "\r\nBcc:") {
+		t.Errorf("header injection: %q", header)
+	}
+	if want := "Hi alice,\r\n\r\nUse this token:\r\n\r\nabc123\r\n"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
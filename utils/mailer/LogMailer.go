@@ -0,0 +1,18 @@
+package mailer
+
+import "log"
+
+// LogMailer writes emails to the application log instead of sending them.
+// For local development only: reset links end up in the log.
+type LogMailer struct{}
+
+// NewLogMailer creates a mailer that logs every message
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs the message
+func (m *LogMailer) Send(msg *Message) error {
+	log.Printf("Email to %s\nSubject: %s\n\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
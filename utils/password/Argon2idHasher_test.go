@@ -0,0 +1,114 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+// testArgon2Params keeps the tests fast; production uses DefaultArgon2Params
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+
+	hash, err := hasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("got %q, want a PHC-formatted Argon2id hash", hash)
+	}
+	if !hasher.Identifies(hash) {
+		t.Error("Identifies rejected its own hash")
+	}
+
+	if ok, err := hasher.Verify(hash, "correct horse battery staple"); !ok || err != nil {
+		t.Errorf("Verify(correct) = %v, %v", ok, err)
+	}
+	if ok, err := hasher.Verify(hash, "Correct horse battery staple"); ok || err != nil {
+		t.Errorf("Verify(wrong) = %v, %v", ok, err)
+	}
+
+	// Every hash gets its own salt
+	if again, _ := hasher.Hash("correct horse battery staple"); again == hash {
+		t.Error("two hashes of the same password are identical")
+	}
+}
+
+func TestArgon2idVerifiesReferenceHash(t *testing.T) {
+	// "password" with the salt "somesalt", hashed by the Argon2 reference implementation
+	const hash = "$argon2id$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo"
+
+	if ok, err := (&Argon2idHasher{}).Verify(hash, "password"); !ok || err != nil {
+		t.Fatalf("Verify = %v, %v", ok, err)
+	}
+}
+
+func TestArgon2idRejectsOutOfRangeParams(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{"no iterations", "m=1024,t=0,p=1"},
+		{"no parallelism", "m=1024,t=1,p=0"},
+		{"too little memory for the lanes", "m=31,t=1,p=4"},
+		{"no memory", "m=0,t=1,p=1"},
+	}
+	for _, tt := range tests {
+		hash := "$argon2id$v=19$" + tt.params + "$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64Zo/8GaUw434IimaPqxwCo"
+		if ok, err := hasher.Verify(hash, "password"); ok || err != ErrInvalidHash {
+			t.Errorf("%s: Verify = %v, %v; want %v", tt.name, ok, err, ErrInvalidHash)
+		}
+		if !hasher.NeedsRehash(hash) {
+			t.Errorf("%s: NeedsRehash = false", tt.name)
+		}
+	}
+}
+
+func TestArgon2idRejectsMalformedHashes(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+
+	tests := map[string]error{
+		"$argon2id$v=19$m=1024,t=1,p=1$c29tZXNhbHQ":                       ErrInvalidHash,
+		"$argon2id$v=19$m=1024,t=1$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G":       ErrInvalidHash,
+		"$argon2id$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$":                      ErrInvalidHash,
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$GpZ3sK/oH9p7VIiV56G":           ErrInvalidHash,
+		"$argon2id$v=16$m=1024,t=1,p=1$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G":   ErrUnsupportedHash,
+		"$argon2i$v=19$m=1024,t=1,p=1$c29tZXNhbHQ$GpZ3sK/oH9p7VIiV56G/64": ErrInvalidHash,
+	}
+	for hash, want := range tests {
+		if ok, err := hasher.Verify(hash, "password"); ok || err != want {
+			t.Errorf("Verify(%q) = %v, %v; want %v", hash, ok, err, want)
+		}
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+	hash, err := hasher.Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	if hasher.NeedsRehash(hash) {
+		t.Error("hash with the current parameters needs a rehash")
+	}
+
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	if !NewArgon2idHasher(stronger).NeedsRehash(hash) {
+		t.Error("hash with fewer iterations doesn't need a rehash")
+	}
+
+	longer := testArgon2Params
+	longer.KeyLength = 64
+	if !NewArgon2idHasher(longer).NeedsRehash(hash) {
+		t.Error("hash with a shorter key doesn't need a rehash")
+	}
+
+	if !hasher.NeedsRehash("not-a-hash") {
+		t.Error("malformed hash doesn't need a rehash")
+	}
+}
@@ -0,0 +1,75 @@
+package password
+
+import (
+	"fmt"
+	"go-auth/models"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CheckPolicy validates a new password against the policy and returns one error per
+// broken rule, reported against field. The username and email are only used for the
+// personal information rule and may be empty.
+func CheckPolicy(policy *models.PasswordPolicy, field, password, username, email string) []models.FieldError {
+	var errs []models.FieldError
+	fail := func(code, message string) {
+		errs = append(errs, models.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		fail("too_short", fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	}
+	if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+		fail("too_long", fmt.Sprintf("must be at most %d bytes long", policy.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUppercase && !hasUpper {
+		fail("missing_uppercase", "must contain an uppercase letter")
+	}
+	if policy.RequireLowercase && !hasLower {
+		fail("missing_lowercase", "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		fail("missing_digit", "must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		fail("missing_symbol", "must contain a symbol")
+	}
+
+	if policy.DisallowPersonalInfo {
+		lower := strings.ToLower(password)
+		if containsFold(lower, username) {
+			fail("contains_username", "must not contain the username")
+		}
+		// Check the local part too, since "jane.doe" is as guessable as "jane.doe@example.com"
+		localPart, _, _ := strings.Cut(email, "@")
+		if containsFold(lower, email) || containsFold(lower, localPart) {
+			fail("contains_email", "must not contain the email address")
+		}
+	}
+
+	return errs
+}
+
+// containsFold reports whether lowerPassword contains value, ignoring case.
+// Values shorter than 3 characters are ignored so short usernames don't reject most passwords.
+func containsFold(lowerPassword, value string) bool {
+	if utf8.RuneCountInString(value) < 3 {
+		return false
+	}
+	return strings.Contains(lowerPassword, strings.ToLower(value))
+}
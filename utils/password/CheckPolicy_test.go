@@ -0,0 +1,84 @@
+package password
+
+import (
+	"go-auth/models"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// codes returns the rule codes of errs in order
+func codes(errs []models.FieldError) []string {
+	var result []string
+	for _, err := range errs {
+		result = append(result, err.Code)
+	}
+	return result
+}
+
+func TestCheckPolicy(t *testing.T) {
+	strict := &models.PasswordPolicy{
+		MinLength:            10,
+		MaxLength:            72,
+		RequireUppercase:     true,
+		RequireLowercase:     true,
+		RequireDigit:         true,
+		RequireSymbol:        true,
+		DisallowPersonalInfo: true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"satisfies every rule", "Tr0ub4dor&3x", nil},
+		{"too short", "Ab1!", []string{"too_short"}},
+		{"too long", "Aa1!" + strings.Repeat("x", 69), []string{"too_long"}},
+		{"no uppercase", "tr0ub4dor&3x", []string{"missing_uppercase"}},
+		{"no lowercase", "TR0UB4DOR&3X", []string{"missing_lowercase"}},
+		{"no digit", "Troubador&xx", []string{"missing_digit"}},
+		{"no symbol", "Tr0ub4dor3xy", []string{"missing_symbol"}},
+		{"space counts as a symbol", "Tr0ub4dor 3x", nil},
+		{"username", "Xjanedoe1!Y", []string{"contains_username"}},
+		{"username any case", "XJaneDoe1!Y", []string{"contains_username"}},
+		{"email local part", "Xjane.d0e!Y", []string{"contains_email"}},
+		{"everything wrong", "abc", []string{"too_short", "missing_uppercase", "missing_digit", "missing_symbol"}},
+	}
+	for _, tt := range tests {
+		errs := CheckPolicy(strict, "new_password", tt.password, "janedoe", "jane.d0e@example.com")
+		if got := codes(errs); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		for _, err := range errs {
+			if err.Field != "new_password" || err.Message == "" {
+				t.Errorf("%s: got %+v, want the field and a message", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestCheckPolicyCountsCharactersNotBytes(t *testing.T) {
+	policy := &models.PasswordPolicy{MinLength: 8, MaxLength: 16}
+
+	// Eight characters, sixteen bytes
+	if errs := CheckPolicy(policy, "password", "éééééééé", "", ""); len(errs) != 0 {
+		t.Errorf("got %v, want no errors", codes(errs))
+	}
+	// Nine characters, eighteen bytes: bcrypt limits bytes, so MaxLength does too
+	if got := codes(CheckPolicy(policy, "password", "ééééééééé", "", "")); !slices.Equal(got, []string{"too_long"}) {
+		t.Errorf("got %v, want [too_long]", got)
+	}
+}
+
+func TestCheckPolicyIgnoresShortPersonalInfo(t *testing.T) {
+	policy := &models.PasswordPolicy{DisallowPersonalInfo: true}
+
+	// A two-letter username would otherwise reject most passwords
+	if errs := CheckPolicy(policy, "password", "Jordan-River-42", "jo", "jo@example.com"); len(errs) != 0 {
+		t.Errorf("got %v, want no errors", codes(errs))
+	}
+	if errs := CheckPolicy(policy, "password", "anything-goes", "", ""); len(errs) != 0 {
+		t.Errorf("empty personal info: got %v", codes(errs))
+	}
+}
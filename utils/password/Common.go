@@ -1,6 +1,80 @@
 package password
 
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidHash     = errors.New("invalid password hash")
+	ErrUnsupportedHash = errors.New("unsupported password hash algorithm")
+)
+
+// Default hashing parameters (Argon2id values follow RFC 9106's second recommended option)
 const (
-	// BcryptCost is the cost factor for bcrypt hashing
-	BcryptCost = 10
-)
\ No newline at end of file
+	DefaultArgon2Memory      = 64 * 1024 // KiB
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 4
+	DefaultArgon2SaltLength  = 16
+	DefaultArgon2KeyLength   = 32
+
+	// DefaultBcryptCost is the cost factor for bcrypt hashing
+	DefaultBcryptCost = 10
+)
+
+// Verifier checks passwords against hashes in one format
+type Verifier interface {
+	// Identifies reports whether encodedHash uses this verifier's format
+	Identifies(encodedHash string) bool
+	// Verify compares password with encodedHash using the parameters stored in the hash
+	Verify(encodedHash, password string) (bool, error)
+}
+
+// Hasher hashes passwords in one algorithm and verifies hashes in that algorithm's format
+type Hasher interface {
+	Verifier
+	// Hash returns the encoded hash of password
+	Hash(password string) (string, error)
+	// NeedsRehash reports whether encodedHash was produced with different parameters
+	NeedsRehash(encodedHash string) bool
+}
+
+// current hashes new passwords; replaced at startup by Configure
+var current Hasher = NewArgon2idHasher(DefaultArgon2Params())
+
+// verifiers accept hashes in every supported format, including legacy formats of
+// imported users. They are tried in order, so more specific formats come first.
+var verifiers = []Verifier{
+	&Argon2idHasher{},
+	&BcryptHasher{},
+	&PBKDF2Verifier{},
+	&ScryptVerifier{},
+	&DjangoSHA1Verifier{},
+	&SSHAVerifier{},
+}
+
+// verifierFor returns a verifier able to check encodedHash
+func verifierFor(encodedHash string) (Verifier, error) {
+	if current.Identifies(encodedHash) {
+		return current, nil
+	}
+	for _, verifier := range verifiers {
+		if verifier.Identifies(encodedHash) {
+			return verifier, nil
+		}
+	}
+	return nil, ErrUnsupportedHash
+}
+
+// ab64Encoding is passlib's "adapted base64": standard alphabet with '.' instead of '+', unpadded
+var ab64Encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+// decodeBase64 decodes the base64 variants used by legacy hash formats (padded or not, '+' or '.')
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+	if strings.Contains(value, ".") {
+		return ab64Encoding.DecodeString(value)
+	}
+	return base64.RawStdEncoding.DecodeString(value)
+}
@@ -0,0 +1,7 @@
+package password
+
+// Configure sets the hasher used for new passwords. Call it once at startup,
+// before any password is hashed; hashes in other formats still verify.
+func Configure(hasher Hasher) {
+	current = hasher
+}
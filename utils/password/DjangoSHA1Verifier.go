@@ -0,0 +1,33 @@
+package password
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// DjangoSHA1Verifier verifies Django's salted SHA-1 hashes: sha1$<salt>$<hex sha1(salt + password)>.
+// SHA-1 is far too fast for passwords; these hashes are only accepted so imported users can log in once.
+type DjangoSHA1Verifier struct{}
+
+// Identifies reports whether encodedHash is a Django salted SHA-1 hash
+func (v *DjangoSHA1Verifier) Identifies(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "sha1$")
+}
+
+// Verify compares password with a Django salted SHA-1 hash in constant time
+func (v *DjangoSHA1Verifier) Verify(encodedHash, password string) (bool, error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 3 {
+		return false, ErrInvalidHash
+	}
+
+	key, err := hex.DecodeString(parts[2])
+	if err != nil || len(key) != sha1.Size {
+		return false, ErrInvalidHash
+	}
+
+	candidate := sha1.Sum([]byte(parts[1] + password))
+	return subtle.ConstantTimeCompare(key, candidate[:]) == 1, nil
+}
@@ -1,12 +1,6 @@
 package password
 
-import "golang.org/x/crypto/bcrypt"
-
-// HashPassword takes a plain text password and returns a bcrypt hash
+// HashPassword takes a plain text password and returns its hash in the configured algorithm
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedPassword), nil
-}
\ No newline at end of file
+	return current.Hash(password)
+}
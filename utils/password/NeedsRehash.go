@@ -0,0 +1,10 @@
+package password
+
+// NeedsRehash reports whether a stored hash uses an outdated algorithm or parameters
+// and should be replaced by HashPassword the next time the plain text password is known
+func NeedsRehash(hashedPassword string) bool {
+	if !current.Identifies(hashedPassword) {
+		return true
+	}
+	return current.NeedsRehash(hashedPassword)
+}
@@ -0,0 +1,82 @@
+package password
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// useHasher configures hasher for the rest of the test
+func useHasher(t *testing.T, hasher Hasher) {
+	previous := current
+	Configure(hasher)
+	t.Cleanup(func() { Configure(previous) })
+}
+
+func TestHashPasswordUsesConfiguredHasher(t *testing.T) {
+	useHasher(t, NewArgon2idHasher(testArgon2Params))
+
+	hash, err := HashPassword("password")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !(&Argon2idHasher{}).Identifies(hash) || !VerifyPassword(hash, "password") || NeedsRehash(hash) {
+		t.Fatalf("got %q, want a current Argon2id hash", hash)
+	}
+	if VerifyPassword(hash, "wrong") {
+		t.Error("wrong password accepted")
+	}
+}
+
+func TestBcryptHashesRehashToArgon2id(t *testing.T) {
+	useHasher(t, NewArgon2idHasher(testArgon2Params))
+
+	legacy, err := NewBcryptHasher(bcrypt.MinCost).Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	// Old bcrypt hashes still verify, and are flagged for replacement on the next login
+	if !VerifyPassword(legacy, "password") {
+		t.Fatal("bcrypt hash rejected")
+	}
+	if !NeedsRehash(legacy) {
+		t.Error("bcrypt hash doesn't need a rehash under Argon2id")
+	}
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+	useHasher(t, hasher)
+
+	hash, err := HashPassword("password")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !VerifyPassword(hash, "password") || VerifyPassword(hash, "wrong") {
+		t.Fatal("bcrypt round trip failed")
+	}
+	if NeedsRehash(hash) {
+		t.Error("hash with the configured cost needs a rehash")
+	}
+	if !NewBcryptHasher(bcrypt.MinCost + 1).NeedsRehash(hash) {
+		t.Error("hash with a lower cost doesn't need a rehash")
+	}
+
+	// Argon2id hashes keep verifying after switching back to bcrypt
+	argonHash, err := NewArgon2idHasher(testArgon2Params).Hash("password")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !VerifyPassword(argonHash, "password") || !NeedsRehash(argonHash) {
+		t.Error("Argon2id hash must verify and be flagged for rehash under bcrypt")
+	}
+}
+
+func TestVerifyPasswordRejectsUnknownFormats(t *testing.T) {
+	for _, hash := range []string{"", "password", "$1$saltsalt$hash", "$unknown$v=1$abc"} {
+		if VerifyPassword(hash, "password") {
+			t.Errorf("VerifyPassword(%q) accepted", hash)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package password
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PBKDF2Verifier verifies PBKDF2-SHA256 hashes in Django format:
+//
+//	pbkdf2_sha256$<iterations>$<salt>$<base64 hash>
+//
+// and in PHC/passlib format:
+//
+//	$pbkdf2-sha256$<iterations>$<ab64 salt>$<ab64 hash>
+//	$pbkdf2-sha256$i=<iterations>$<b64 salt>$<b64 hash>
+type PBKDF2Verifier struct{}
+
+// Identifies reports whether encodedHash is a PBKDF2-SHA256 hash
+func (v *PBKDF2Verifier) Identifies(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "pbkdf2_sha256$") || strings.HasPrefix(encodedHash, "$pbkdf2-sha256$")
+}
+
+// Verify compares password with a PBKDF2-SHA256 hash in constant time
+func (v *PBKDF2Verifier) Verify(encodedHash, password string) (bool, error) {
+	var iterationsField, saltField, keyField string
+	var salt, key []byte
+	var err error
+
+	if strings.HasPrefix(encodedHash, "$") {
+		// "", "pbkdf2-sha256", iterations, salt, hash
+		parts := strings.Split(encodedHash, "$")
+		if len(parts) != 5 {
+			return false, ErrInvalidHash
+		}
+		iterationsField, saltField, keyField = strings.TrimPrefix(parts[2], "i="), parts[3], parts[4]
+		if salt, err = decodeBase64(saltField); err != nil {
+			return false, ErrInvalidHash
+		}
+		if key, err = decodeBase64(keyField); err != nil {
+			return false, ErrInvalidHash
+		}
+	} else {
+		// Django uses the salt string as-is and a padded base64 hash
+		parts := strings.Split(encodedHash, "$")
+		if len(parts) != 4 {
+			return false, ErrInvalidHash
+		}
+		iterationsField, saltField, keyField = parts[1], parts[2], parts[3]
+		salt = []byte(saltField)
+		if key, err = base64.StdEncoding.DecodeString(keyField); err != nil {
+			return false, ErrInvalidHash
+		}
+	}
+
+	iterations, err := strconv.Atoi(iterationsField)
+	if err != nil || iterations < 1 || len(key) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	candidate := pbkdf2.Key([]byte(password), salt, iterations, len(key), sha256.New)
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
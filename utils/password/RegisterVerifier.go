@@ -0,0 +1,8 @@
+package password
+
+// RegisterVerifier adds a verifier for another hash format, e.g. one used by an app whose
+// users are being imported. Call it at startup. Users whose hashes match it are moved to
+// the current algorithm on their next login, since NeedsRehash reports true for them.
+func RegisterVerifier(verifier Verifier) {
+	verifiers = append(verifiers, verifier)
+}
@@ -0,0 +1,31 @@
+package password
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+const sshaPrefix = "{SSHA}"
+
+// SSHAVerifier verifies LDAP-style salted SHA-1 hashes: {SSHA}<base64(sha1(password + salt) + salt)>.
+// Like DjangoSHA1Verifier it only exists to migrate imported users.
+type SSHAVerifier struct{}
+
+// Identifies reports whether encodedHash is an {SSHA} hash
+func (v *SSHAVerifier) Identifies(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, sshaPrefix)
+}
+
+// Verify compares password with an {SSHA} hash in constant time
+func (v *SSHAVerifier) Verify(encodedHash, password string) (bool, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encodedHash, sshaPrefix))
+	if err != nil || len(decoded) <= sha1.Size {
+		return false, ErrInvalidHash
+	}
+
+	key, salt := decoded[:sha1.Size], decoded[sha1.Size:]
+	candidate := sha1.Sum(append([]byte(password), salt...))
+	return subtle.ConstantTimeCompare(key, candidate[:]) == 1, nil
+}
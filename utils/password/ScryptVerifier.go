@@ -0,0 +1,79 @@
+package password
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptVerifier verifies scrypt hashes in Django format:
+//
+//	scrypt$<N>$<salt>$<r>$<p>$<base64 hash>
+//
+// and in PHC/passlib format:
+//
+//	$scrypt$ln=<log2 N>,r=<r>,p=<p>$<b64 salt>$<b64 hash>
+type ScryptVerifier struct{}
+
+// Identifies reports whether encodedHash is an scrypt hash
+func (v *ScryptVerifier) Identifies(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "scrypt$") || strings.HasPrefix(encodedHash, "$scrypt$")
+}
+
+// Verify compares password with an scrypt hash in constant time
+func (v *ScryptVerifier) Verify(encodedHash, password string) (bool, error) {
+	var n, r, p int
+	var salt, key []byte
+	var err error
+
+	parts := strings.Split(encodedHash, "$")
+	if strings.HasPrefix(encodedHash, "$") {
+		// "", "scrypt", "ln=...,r=...,p=...", salt, hash
+		if len(parts) != 5 {
+			return false, ErrInvalidHash
+		}
+		var logN int
+		if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &r, &p); err != nil || logN < 1 || logN > 30 {
+			return false, ErrInvalidHash
+		}
+		n = 1 << logN
+		if salt, err = decodeBase64(parts[3]); err != nil {
+			return false, ErrInvalidHash
+		}
+		if key, err = decodeBase64(parts[4]); err != nil {
+			return false, ErrInvalidHash
+		}
+	} else {
+		// "scrypt", N, salt, r, p, hash
+		if len(parts) != 6 {
+			return false, ErrInvalidHash
+		}
+		if n, err = strconv.Atoi(parts[1]); err != nil {
+			return false, ErrInvalidHash
+		}
+		if r, err = strconv.Atoi(parts[3]); err != nil {
+			return false, ErrInvalidHash
+		}
+		if p, err = strconv.Atoi(parts[4]); err != nil {
+			return false, ErrInvalidHash
+		}
+		salt = []byte(parts[2])
+		if key, err = base64.StdEncoding.DecodeString(parts[5]); err != nil {
+			return false, ErrInvalidHash
+		}
+	}
+
+	if len(key) == 0 {
+		return false, ErrInvalidHash
+	}
+
+	candidate, err := scrypt.Key([]byte(password), salt, n, r, p, len(key))
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
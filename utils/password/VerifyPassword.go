@@ -1,10 +1,12 @@
 package password
 
-import "golang.org/x/crypto/bcrypt"
-
-// VerifyPassword compares a plain text password with a bcrypt hash
+// VerifyPassword compares a plain text password with a hash in any supported format
 // Returns true if the password matches, false otherwise
 func VerifyPassword(hashedPassword, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
-}
\ No newline at end of file
+	verifier, err := verifierFor(hashedPassword)
+	if err != nil {
+		return false
+	}
+	ok, err := verifier.Verify(hashedPassword, password)
+	return err == nil && ok
+}
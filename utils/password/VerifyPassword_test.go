@@ -0,0 +1,87 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+// legacyHashes of "hunter2", produced with Python's hashlib rather than the verifiers under test
+var legacyHashes = map[string]string{
+	"django pbkdf2":  "pbkdf2_sha256$1000$seasalt42$W5G8Xw57AILGcjxSEi/BWjKYZ6z/oD77MWtEjbNO26A=",
+	"passlib pbkdf2": "$pbkdf2-sha256$1000$AQIDBAUGBwgJCgsMDQ77/w$Z4uYYjnVEeCygYK.C1Hno8MOIky0c0gCvAm4pXgXBVc",
+	"phc pbkdf2":     "$pbkdf2-sha256$i=1000$AQIDBAUGBwgJCgsMDQ77/w$Z4uYYjnVEeCygYK+C1Hno8MOIky0c0gCvAm4pXgXBVc",
+	"django scrypt":  "scrypt$1024$seasalt42$8$1$GoAXcqoM0CuBErJkbpCEouTT2m7XoXDl6pSCKvmhErU=",
+	"phc scrypt":     "$scrypt$ln=10,r=8,p=1$AQIDBAUGBwgJCgsMDQ77/w$/KzAvk0vwJgQVBvN3U3V4YykipMwnX4lIXnPPMqRuRc",
+	"django sha1":    "sha1$seasalt42$6150242e8956e799ed1b1f794180d45b9dbc7ebc",
+	"ldap ssha":      "{SSHA}NJ2OHbz2w54wawd9DDPQvhSSGqJOYUNsMTIzNA==",
+}
+
+func TestVerifyPasswordLegacyFormats(t *testing.T) {
+	useHasher(t, NewArgon2idHasher(testArgon2Params))
+
+	for name, hash := range legacyHashes {
+		if !VerifyPassword(hash, "hunter2") {
+			t.Errorf("%s: correct password rejected", name)
+		}
+		if VerifyPassword(hash, "hunter3") {
+			t.Errorf("%s: wrong password accepted", name)
+		}
+		// Imported users are moved to the current algorithm on their next login
+		if !NeedsRehash(hash) {
+			t.Errorf("%s: doesn't need a rehash", name)
+		}
+	}
+}
+
+func TestLegacyVerifiersRejectMalformedHashes(t *testing.T) {
+	tests := map[string]string{
+		"pbkdf2 missing field":    "pbkdf2_sha256$1000$seasalt42",
+		"pbkdf2 zero iterations":  "pbkdf2_sha256$0$seasalt42$W5G8Xw57AILGcjxSEi/BWjKYZ6z/oD77MWtEjbNO26A=",
+		"pbkdf2 bad base64":       "$pbkdf2-sha256$1000$AQIDBAUGBwgJCgsMDQ77/w$!!!",
+		"scrypt bad cost":         "$scrypt$ln=0,r=8,p=1$AQIDBAUGBwgJCgsMDQ77/w$/KzAvk0vwJgQVBvN3U3V4YykipMwnX4lIXnPPMqRuRc",
+		"scrypt non-power-of-two": "scrypt$1000$seasalt42$8$1$GoAXcqoM0CuBErJkbpCEouTT2m7XoXDl6pSCKvmhErU=",
+		"sha1 short digest":       "sha1$seasalt42$6150242e",
+		"ssha without salt":       "{SSHA}NJ2OHbz2w54wawd9DDPQvhSSGqI=",
+	}
+	for name, hash := range tests {
+		verifier, err := verifierFor(hash)
+		if err != nil {
+			t.Fatalf("%s: verifierFor: %v", name, err)
+		}
+		if ok, err := verifier.Verify(hash, "hunter2"); ok || err != ErrInvalidHash {
+			t.Errorf("%s: got %v, %v; want ErrInvalidHash", name, ok, err)
+		}
+	}
+}
+
+// reversedVerifier accepts "reversed$<password backwards>", standing in for an app's own format
+type reversedVerifier struct{}
+
+func (reversedVerifier) Identifies(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "reversed$")
+}
+
+func (reversedVerifier) Verify(encodedHash, password string) (bool, error) {
+	runes := []rune(password)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return strings.TrimPrefix(encodedHash, "reversed$") == string(runes), nil
+}
+
+func TestRegisterVerifier(t *testing.T) {
+	previous := verifiers
+	t.Cleanup(func() { verifiers = previous })
+
+	if VerifyPassword("reversed$2retnuh", "hunter2") {
+		t.Fatal("unregistered format accepted")
+	}
+
+	RegisterVerifier(reversedVerifier{})
+	if !VerifyPassword("reversed$2retnuh", "hunter2") || VerifyPassword("reversed$2retnuh", "hunter3") {
+		t.Error("registered verifier not used")
+	}
+	if !NeedsRehash("reversed$2retnuh") {
+		t.Error("registered format doesn't need a rehash")
+	}
+}
@@ -0,0 +1,16 @@
+package revocation
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTokenRevoked = errors.New("token revoked")
+
+// Denylist stores the IDs (jti) of tokens revoked before their expiry.
+// Entries only need to live until expiresAt, after which the token is rejected anyway.
+type Denylist interface {
+	Revoke(jti string, expiresAt time.Time) error
+	IsRevoked(jti string) (bool, error)
+	Purge() error
+}
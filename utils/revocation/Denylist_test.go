@@ -0,0 +1,57 @@
+package revocation
+
+import (
+	"go-auth/db/dbtest"
+	"go-auth/utils/secure"
+	"testing"
+	"time"
+)
+
+// testDenylist checks the behaviour every Denylist implementation shares
+func testDenylist(t *testing.T, denylist Denylist) {
+	t.Helper()
+
+	revoked := secure.NewID()
+	expired := secure.NewID()
+	if err := denylist.Revoke(revoked, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := denylist.Revoke(expired, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	for jti, want := range map[string]bool{revoked: true, expired: false, secure.NewID(): false} {
+		got, err := denylist.IsRevoked(jti)
+		if err != nil {
+			t.Fatalf("IsRevoked: %v", err)
+		}
+		if got != want {
+			t.Errorf("IsRevoked(%s) = %v, want %v", jti, got, want)
+		}
+	}
+
+	// Revoking twice is harmless
+	if err := denylist.Revoke(revoked, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke again: %v", err)
+	}
+
+	if err := denylist.Purge(); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if got, _ := denylist.IsRevoked(revoked); !got {
+		t.Error("Purge dropped a token that hasn't expired")
+	}
+}
+
+func TestMemoryDenylist(t *testing.T) {
+	denylist := NewMemoryDenylist()
+	testDenylist(t, denylist)
+
+	if len(denylist.entries) != 1 {
+		t.Fatalf("got %d entries after Purge, want only the unexpired one", len(denylist.entries))
+	}
+}
+
+func TestPostgresDenylist(t *testing.T) {
+	testDenylist(t, NewPostgresDenylist(dbtest.Open(t)))
+}
@@ -0,0 +1,49 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryDenylist keeps revoked token IDs in process memory.
+// Suitable for tests and single-instance deployments only.
+type MemoryDenylist struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+// NewMemoryDenylist creates an empty in-memory denylist
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Revoke adds a token ID until expiresAt
+func (d *MemoryDenylist) Revoke(jti string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries[jti] = expiresAt
+	return nil
+}
+
+// IsRevoked reports whether the token ID is revoked and not yet expired
+func (d *MemoryDenylist) IsRevoked(jti string) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	expiresAt, ok := d.entries[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+// Purge drops entries whose tokens have expired
+func (d *MemoryDenylist) Purge() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	for jti, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, jti)
+		}
+	}
+	return nil
+}
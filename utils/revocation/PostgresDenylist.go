@@ -0,0 +1,33 @@
+package revocation
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"time"
+)
+
+// PostgresDenylist stores revoked token IDs in the revoked_tokens table,
+// so every service instance sees the same revocations
+type PostgresDenylist struct {
+	db *sql.DB
+}
+
+// NewPostgresDenylist creates a denylist backed by the database
+func NewPostgresDenylist(db *sql.DB) *PostgresDenylist {
+	return &PostgresDenylist{db: db}
+}
+
+// Revoke adds a token ID until expiresAt
+func (d *PostgresDenylist) Revoke(jti string, expiresAt time.Time) error {
+	return queries.RevokeToken(d.db, jti, expiresAt)
+}
+
+// IsRevoked reports whether the token ID is revoked and not yet expired
+func (d *PostgresDenylist) IsRevoked(jti string) (bool, error) {
+	return queries.IsTokenRevoked(d.db, jti)
+}
+
+// Purge deletes entries whose tokens have expired
+func (d *PostgresDenylist) Purge() error {
+	return queries.DeleteExpiredRevokedTokens(d.db)
+}
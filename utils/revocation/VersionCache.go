@@ -0,0 +1,84 @@
+package revocation
+
+import (
+	"database/sql"
+	queries "go-auth/db/Queries"
+	"sync"
+	"time"
+)
+
+// VersionCache caches users' and service accounts' current token_version for a short TTL so the
+// online check in AuthMiddleware does not hit the database on every request
+type VersionCache struct {
+	db      *sql.DB
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]versionEntry
+}
+
+type versionEntry struct {
+	version   int
+	found     bool
+	fetchedAt time.Time
+}
+
+// NewVersionCache creates a cache whose entries are refreshed after ttl
+func NewVersionCache(db *sql.DB, ttl time.Duration) *VersionCache {
+	return &VersionCache{
+		db:      db,
+		ttl:     ttl,
+		entries: make(map[string]versionEntry),
+	}
+}
+
+// Get returns the user's current token version.
+// Returns queries.ErrUserNotFound for deleted or unknown users.
+func (c *VersionCache) Get(userID string) (int, error) {
+	return c.get("user:"+userID, queries.ErrUserNotFound, func() (int, error) {
+		return queries.GetTokenVersion(c.db, userID)
+	})
+}
+
+// GetService returns the service account's current token version.
+// Returns queries.ErrServiceAccountNotFound for disabled or unknown accounts.
+func (c *VersionCache) GetService(accountID string) (int, error) {
+	return c.get("service:"+accountID, queries.ErrServiceAccountNotFound, func() (int, error) {
+		return queries.GetServiceAccountTokenVersion(c.db, accountID)
+	})
+}
+
+// Purge drops stale entries, which the next lookup would reload anyway
+func (c *VersionCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if time.Since(entry.fetchedAt) > c.ttl {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// get returns the cached version for key, calling load when it is missing or stale.
+// notFound is the error load reports for principals that no longer exist.
+func (c *VersionCache) get(key string, notFound error, load func() (int, error)) (int, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if !ok || time.Since(entry.fetchedAt) > c.ttl {
+		version, err := load()
+		if err != nil && err != notFound {
+			return 0, err
+		}
+
+		entry = versionEntry{version: version, found: err == nil, fetchedAt: time.Now()}
+		c.mu.Lock()
+		c.entries[key] = entry
+		c.mu.Unlock()
+	}
+
+	if !entry.found {
+		return 0, notFound
+	}
+	return entry.version, nil
+}
@@ -0,0 +1,63 @@
+package revocation
+
+import (
+	queries "go-auth/db/Queries"
+	"go-auth/db/dbtest"
+	"testing"
+	"time"
+)
+
+func TestVersionCache(t *testing.T) {
+	db := dbtest.Open(t)
+	user := dbtest.CreateUser(t, db)
+
+	cached := NewVersionCache(db, time.Hour)
+	fresh := NewVersionCache(db, 0)
+	for _, cache := range []*VersionCache{cached, fresh} {
+		if version, err := cache.Get(user.ID); err != nil || version != user.TokenVersion {
+			t.Fatalf("Get = %d, %v; want %d", version, err, user.TokenVersion)
+		}
+	}
+
+	if err := queries.BumpTokenVersion(db, user.ID); err != nil {
+		t.Fatalf("BumpTokenVersion: %v", err)
+	}
+
+	// Within the TTL the old version is served from the cache
+	if version, _ := cached.Get(user.ID); version != user.TokenVersion {
+		t.Fatalf("cached Get = %d, want %d", version, user.TokenVersion)
+	}
+	if version, _ := fresh.Get(user.ID); version != user.TokenVersion+1 {
+		t.Fatalf("fresh Get = %d, want %d", version, user.TokenVersion+1)
+	}
+
+	if err := queries.DeleteUser(db, user.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := fresh.Get(user.ID); err != queries.ErrUserNotFound {
+		t.Fatalf("deleted user: got %v, want ErrUserNotFound", err)
+	}
+	if _, err := fresh.Get("00000000-0000-4000-8000-000000000000"); err != queries.ErrUserNotFound {
+		t.Fatalf("unknown user: got %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestVersionCachePurge(t *testing.T) {
+	cache := NewVersionCache(nil, time.Minute)
+	cache.entries["user:stale"] = versionEntry{version: 1, found: true, fetchedAt: time.Now().Add(-2 * time.Minute)}
+	cache.entries["user:fresh"] = versionEntry{version: 1, found: true, fetchedAt: time.Now()}
+
+	cache.Purge()
+
+	if _, ok := cache.entries["user:stale"]; ok {
+		t.Error("stale entry kept")
+	}
+	if _, ok := cache.entries["user:fresh"]; !ok {
+		t.Error("fresh entry dropped")
+	}
+
+	// Fresh entries are still served without a database
+	if version, err := cache.Get("fresh"); err != nil || version != 1 {
+		t.Errorf("Get = %d, %v; want 1", version, err)
+	}
+}
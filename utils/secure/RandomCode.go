@@ -0,0 +1,17 @@
+package secure
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// RandomCode returns length characters chosen uniformly at random from alphabet
+func RandomCode(alphabet string, length int) string {
+	max := big.NewInt(int64(len(alphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, _ := rand.Int(rand.Reader, max)
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code)
+}
@@ -0,0 +1,13 @@
+package secure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// RandomToken returns size bytes of cryptographically secure randomness, base64url encoded
+func RandomToken(size int) string {
+	buf := make([]byte, size)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
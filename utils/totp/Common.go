@@ -0,0 +1,25 @@
+package totp
+
+import (
+	"encoding/base32"
+	"errors"
+	"time"
+)
+
+var ErrInvalidSecret = errors.New("invalid totp secret")
+
+// RFC 6238 parameters understood by every common authenticator app
+const (
+	Digits     = 6
+	Period     = 30 * time.Second
+	SecretSize = 20 // Bytes, the size of an HMAC-SHA1 key
+	Skew       = 1  // Codes from this many periods before and after now are accepted
+)
+
+// secretEncoding is the unpadded base32 alphabet used in otpauth:// URIs
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// step returns the RFC 6238 time step containing t
+func step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
@@ -0,0 +1,61 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B (SHA-1), truncated to the last 6 of the 8 digits
+	vectors := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, v := range vectors {
+		code, err := GenerateCode(rfcSecret, step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("GenerateCode: %v", err)
+		}
+		if code != v.want {
+			t.Errorf("T=%d: got %s, want %s", v.unix, code, v.want)
+		}
+	}
+}
+
+func TestGenerateCodeSecretFormats(t *testing.T) {
+	want, _ := GenerateCode(rfcSecret, 1)
+
+	// Authenticator apps accept lower case and padded secrets
+	for _, secret := range []string{"gezdgnbvgy3tqojqgezdgnbvgy3tqojq", rfcSecret + "===="} {
+		if code, err := GenerateCode(secret, 1); err != nil || code != want {
+			t.Errorf("%s: got %q, %v, want %s", secret, code, err, want)
+		}
+	}
+
+	for _, secret := range []string{"", "not base32!", "1"} {
+		if _, err := GenerateCode(secret, 1); err != ErrInvalidSecret {
+			t.Errorf("%q: got %v, want ErrInvalidSecret", secret, err)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret := GenerateSecret()
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(key) != SecretSize {
+		t.Fatalf("secret %q decodes to %d bytes (%v), want %d", secret, len(key), err, SecretSize)
+	}
+	if GenerateSecret() == secret {
+		t.Error("two secrets are the same")
+	}
+}
@@ -0,0 +1,10 @@
+package totp
+
+import "crypto/rand"
+
+// GenerateSecret returns a new random base32 encoded secret
+func GenerateSecret() string {
+	secret := make([]byte, SecretSize)
+	rand.Read(secret)
+	return secretEncoding.EncodeToString(secret)
+}
@@ -0,0 +1,25 @@
+package totp
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// KeyURI returns the otpauth:// URI authenticator apps import, usually shown as a QR code
+func KeyURI(issuer, account, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {strconv.Itoa(Digits)},
+		"period":    {strconv.Itoa(int(Period.Seconds()))},
+	}
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return uri.String()
+}
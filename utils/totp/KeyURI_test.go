@@ -0,0 +1,23 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestKeyURI(t *testing.T) {
+	uri, err := url.Parse(KeyURI("Go Auth", "alice@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("invalid URI: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Go Auth:alice@example.com" {
+		t.Errorf("got %s://%s%s, want otpauth://totp/Go Auth:alice@example.com", uri.Scheme, uri.Host, uri.Path)
+	}
+	want := map[string]string{"secret": rfcSecret, "issuer": "Go Auth", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for key, value := range want {
+		if got := uri.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package totp
+
+import (
+	"crypto/subtle"
+	"time"
+)
+
+// Validate checks code against the steps around t, allowing for clock drift of Skew periods.
+// It returns the matching time step, which callers store so the same code can't be used twice.
+func Validate(secret, code string, t time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := step(t)
+	for counter := current - Skew; counter <= current+Skew; counter++ {
+		expected, err := GenerateCode(secret, counter)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,34 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := step(now)
+
+	// Codes one period off are accepted for clock drift, two periods are not
+	for offset := int64(-2); offset <= 2; offset++ {
+		code, err := GenerateCode(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatalf("GenerateCode: %v", err)
+		}
+		matched, ok := Validate(rfcSecret, code, now)
+		if wantOK := offset >= -Skew && offset <= Skew; ok != wantOK {
+			t.Errorf("offset %d: got %v, want %v", offset, ok, wantOK)
+		} else if ok && matched != current+offset {
+			t.Errorf("offset %d: matched step %d, want %d", offset, matched, current+offset)
+		}
+	}
+
+	for _, code := range []string{"", "05047", "0504710", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("%q was accepted", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "050471", now); ok {
+		t.Error("a code for an invalid secret was accepted")
+	}
+}
@@ -0,0 +1,93 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// AuthenticatorData is the parsed authenticator data of a registration or assertion
+type AuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte // Attested credential data, only present on registration
+	CredentialID []byte
+	PublicKey    []byte // COSE_Key
+}
+
+// UserPresent reports whether the user touched the authenticator
+func (d *AuthenticatorData) UserPresent() bool {
+	return d.Flags&flagUserPresent != 0
+}
+
+// UserVerified reports whether the authenticator verified the user (PIN or biometric)
+func (d *AuthenticatorData) UserVerified() bool {
+	return d.Flags&flagUserVerified != 0
+}
+
+// ParseAuthenticatorData parses raw authenticator data (WebAuthn §6.1)
+func ParseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < authenticatorHeader {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidAuthenticatorData)
+	}
+
+	parsed := &AuthenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[authenticatorHeader:]
+
+	if parsed.Flags&flagAttestedData != 0 {
+		// aaguid (16) + credentialIdLength (2) + credentialId + credentialPublicKey
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: truncated attested credential data", ErrInvalidAuthenticatorData)
+		}
+		parsed.AAGUID = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, fmt.Errorf("%w: invalid credential id", ErrInvalidAuthenticatorData)
+		}
+		parsed.CredentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		_, used, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid credential public key", ErrInvalidAuthenticatorData)
+		}
+		parsed.PublicKey = rest[:used]
+		rest = rest[used:]
+	}
+
+	if parsed.Flags&flagExtensionData != 0 {
+		_, used, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid extensions", ErrInvalidAuthenticatorData)
+		}
+		rest = rest[used:]
+	}
+
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrInvalidAuthenticatorData)
+	}
+
+	return parsed, nil
+}
+
+// verify checks the RP ID hash and that the user was present and verified
+func (d *AuthenticatorData) verify(rpID string) error {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(d.RPIDHash, rpIDHash[:]) {
+		return fmt.Errorf("%w: rp id mismatch", ErrInvalidAuthenticatorData)
+	}
+	if !d.UserPresent() {
+		return fmt.Errorf("%w: user not present", ErrInvalidAuthenticatorData)
+	}
+	if !d.UserVerified() {
+		return fmt.Errorf("%w: user not verified", ErrInvalidAuthenticatorData)
+	}
+	return nil
+}
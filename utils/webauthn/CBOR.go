@@ -0,0 +1,139 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errInvalidCBOR = errors.New("invalid cbor")
+
+// cborMaxDepth bounds nesting so hostile input can't exhaust the stack
+const cborMaxDepth = 16
+
+// decodeCBOR decodes the first CBOR data item in data and returns it with the number of
+// bytes it used. Only the definite-length subset authenticators emit (CTAP2 canonical CBOR)
+// is supported. Integers decode to int64, byte strings to []byte, text to string, arrays
+// to []any, maps to map[any]any (int64 or string keys), and true/false/null to bool and nil.
+// Tags are dropped, keeping the tagged item.
+func decodeCBOR(data []byte) (any, int, error) {
+	return decodeCBORItem(data, 0, 0)
+}
+
+func decodeCBORItem(data []byte, pos, depth int) (any, int, error) {
+	if depth > cborMaxDepth || pos >= len(data) {
+		return nil, 0, errInvalidCBOR
+	}
+
+	major := data[pos] >> 5
+	info := data[pos] & 0x1f
+	pos++
+
+	// Simple values and floats carry their value in the additional info
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, pos, nil
+		case 21:
+			return true, pos, nil
+		case 22, 23:
+			return nil, pos, nil
+		case 26:
+			if pos+4 > len(data) {
+				return nil, 0, errInvalidCBOR
+			}
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data[pos:]))), pos + 4, nil
+		case 27:
+			if pos+8 > len(data) {
+				return nil, 0, errInvalidCBOR
+			}
+			return math.Float64frombits(binary.BigEndian.Uint64(data[pos:])), pos + 8, nil
+		}
+		return nil, 0, errInvalidCBOR
+	}
+
+	// Every other major type is followed by an argument: a value, length or count
+	var argument uint64
+	switch {
+	case info < 24:
+		argument = uint64(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if pos+size > len(data) {
+			return nil, 0, errInvalidCBOR
+		}
+		for _, b := range data[pos : pos+size] {
+			argument = argument<<8 | uint64(b)
+		}
+		pos += size
+	default:
+		// Indefinite lengths and reserved values
+		return nil, 0, errInvalidCBOR
+	}
+
+	switch major {
+	case 0:
+		if argument > math.MaxInt64 {
+			return nil, 0, errInvalidCBOR
+		}
+		return int64(argument), pos, nil
+	case 1:
+		if argument > math.MaxInt64 {
+			return nil, 0, errInvalidCBOR
+		}
+		return -1 - int64(argument), pos, nil
+	case 2, 3:
+		if argument > uint64(len(data)-pos) {
+			return nil, 0, errInvalidCBOR
+		}
+		end := pos + int(argument)
+		if major == 3 {
+			return string(data[pos:end]), end, nil
+		}
+		return append([]byte(nil), data[pos:end]...), end, nil
+	case 4:
+		// Every item takes at least one byte, so longer counts can't be valid
+		if argument > uint64(len(data)-pos) {
+			return nil, 0, errInvalidCBOR
+		}
+		items := make([]any, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			item, next, err := decodeCBORItem(data, pos, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			pos = next
+		}
+		return items, pos, nil
+	case 5:
+		if argument > uint64(len(data)-pos)/2 {
+			return nil, 0, errInvalidCBOR
+		}
+		entries := make(map[any]any, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, next, err := decodeCBORItem(data, pos, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, errInvalidCBOR
+			}
+			if _, exists := entries[key]; exists {
+				return nil, 0, errInvalidCBOR
+			}
+			value, next, err := decodeCBORItem(data, next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			entries[key] = value
+			pos = next
+		}
+		return entries, pos, nil
+	default:
+		// Major type 6: a tag followed by the tagged item
+		return decodeCBORItem(data, pos, depth+1)
+	}
+}
@@ -0,0 +1,40 @@
+package webauthn
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeCBORRejectsMalformedInput(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":                 {},
+		"truncated integer":     {0x19, 0x01},
+		"truncated bytes":       {0x45, 0x01, 0x02},
+		"truncated map":         {0xa2, 0x01, 0x02, 0x03},
+		"indefinite bytes":      {0x5f, 0x41, 0x00, 0xff},
+		"reserved info":         {0x1c},
+		"huge array count":      {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"byte string map key":   {0xa1, 0x41, 0x00, 0x01},
+		"integer overflow":      {0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"unsupported simple":    {0xf8, 0x20},
+		"nested past max depth": append(bytes.Repeat([]byte{0x81}, cborMaxDepth+2), 0x00),
+	}
+	for name, data := range tests {
+		if _, _, err := decodeCBOR(data); err == nil {
+			t.Errorf("%s: decoded % x without error", name, data)
+		}
+	}
+}
+
+func TestDecodeCBORRoundTrip(t *testing.T) {
+	encoded := cborEncode([]cborPair{{1, 2}, {-3, []byte{0xaa, 0xbb}}, {"fmt", "none"}, {"big", 70000}})
+
+	decoded, used, err := decodeCBOR(encoded)
+	if err != nil || used != len(encoded) {
+		t.Fatalf("decodeCBOR: used %d of %d bytes, err %v", used, len(encoded), err)
+	}
+	m := decoded.(map[any]any)
+	if m[int64(1)] != int64(2) || !bytes.Equal(m[int64(-3)].([]byte), []byte{0xaa, 0xbb}) || m["fmt"] != "none" || m["big"] != int64(70000) {
+		t.Fatalf("got %v", m)
+	}
+}
@@ -0,0 +1,54 @@
+package webauthn
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Client data types of the two ceremonies
+const (
+	clientDataCreate = "webauthn.create"
+	clientDataGet    = "webauthn.get"
+)
+
+// ClientData is the browser's clientDataJSON, which the authenticator signs over
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"` // base64url, as issued by the service
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ParseClientData parses clientDataJSON. The challenge can be used to look up the ceremony
+// before the response is verified.
+func ParseClientData(clientDataJSON []byte) (*ClientData, error) {
+	var clientData ClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClientData, err)
+	}
+	if clientData.Challenge == "" {
+		return nil, fmt.Errorf("%w: missing challenge", ErrInvalidClientData)
+	}
+	return &clientData, nil
+}
+
+// verifyClientData checks the ceremony type, challenge and origin of clientDataJSON
+func (rp *RelyingParty) verifyClientData(clientDataJSON []byte, ceremony, challenge string) error {
+	clientData, err := ParseClientData(clientDataJSON)
+	if err != nil {
+		return err
+	}
+	if clientData.Type != ceremony {
+		return fmt.Errorf("%w: unexpected type %q", ErrInvalidClientData, clientData.Type)
+	}
+	if clientData.Challenge != challenge {
+		return fmt.Errorf("%w: challenge mismatch", ErrInvalidClientData)
+	}
+	if !rp.allowsOrigin(clientData.Origin) {
+		return fmt.Errorf("%w: origin %q is not allowed", ErrInvalidClientData, clientData.Origin)
+	}
+	if clientData.CrossOrigin {
+		return fmt.Errorf("%w: cross-origin requests are not allowed", ErrInvalidClientData)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package webauthn
+
+import "errors"
+
+var (
+	ErrInvalidClientData        = errors.New("invalid client data")
+	ErrInvalidAuthenticatorData = errors.New("invalid authenticator data")
+	ErrInvalidAttestation       = errors.New("invalid attestation object")
+	ErrUnsupportedPublicKey     = errors.New("unsupported credential public key")
+	ErrInvalidSignature         = errors.New("invalid signature")
+)
+
+// ChallengeSize is the number of random bytes in a ceremony challenge
+const ChallengeSize = 32
+
+// COSE algorithms (RFC 9053) accepted for credentials, in order of preference
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent     = 0x01
+	flagUserVerified    = 0x04
+	flagAttestedData    = 0x40
+	flagExtensionData   = 0x80
+	authenticatorHeader = 37 // rpIdHash (32) + flags (1) + signCount (4)
+)
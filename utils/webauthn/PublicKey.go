@@ -0,0 +1,106 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// COSE_Key parameters (RFC 9052 / RFC 9053)
+const (
+	coseKeyType      = 1
+	coseAlg          = 3
+	coseCurve        = -1 // Also the RSA modulus n
+	coseX            = -2 // Also the RSA exponent e
+	coseY            = -3
+	coseKeyOKP       = 1
+	coseKeyEC2       = 2
+	coseKeyRSA       = 3
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+// PublicKey is a credential public key and the algorithm it signs with
+type PublicKey struct {
+	Algorithm int
+	Key       crypto.PublicKey
+}
+
+// ParsePublicKey parses a COSE_Key for ES256 (P-256), EdDSA (Ed25519) or RS256
+func ParsePublicKey(coseKey []byte) (*PublicKey, error) {
+	decoded, used, err := decodeCBOR(coseKey)
+	if err != nil || used != len(coseKey) {
+		return nil, fmt.Errorf("%w: invalid cose key", ErrUnsupportedPublicKey)
+	}
+	params, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid cose key", ErrUnsupportedPublicKey)
+	}
+
+	keyType, _ := params[int64(coseKeyType)].(int64)
+	alg, _ := params[int64(coseAlg)].(int64)
+
+	switch {
+	case keyType == coseKeyEC2 && alg == AlgES256:
+		curve, _ := params[int64(coseCurve)].(int64)
+		x, _ := params[int64(coseX)].([]byte)
+		y, _ := params[int64(coseY)].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%w: invalid ec2 key", ErrUnsupportedPublicKey)
+		}
+		// Reject points that are not on the curve
+		point := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("%w: invalid ec2 key", ErrUnsupportedPublicKey)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return &PublicKey{Algorithm: AlgES256, Key: key}, nil
+
+	case keyType == coseKeyOKP && alg == AlgEdDSA:
+		curve, _ := params[int64(coseCurve)].(int64)
+		x, _ := params[int64(coseX)].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid okp key", ErrUnsupportedPublicKey)
+		}
+		return &PublicKey{Algorithm: AlgEdDSA, Key: ed25519.PublicKey(x)}, nil
+
+	case keyType == coseKeyRSA && alg == AlgRS256:
+		n, _ := params[int64(coseCurve)].([]byte)
+		e, _ := params[int64(coseX)].([]byte)
+		if len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: invalid rsa key", ErrUnsupportedPublicKey)
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < 2048 || key.E < 3 || key.E%2 == 0 {
+			return nil, fmt.Errorf("%w: invalid rsa key", ErrUnsupportedPublicKey)
+		}
+		return &PublicKey{Algorithm: AlgRS256, Key: key}, nil
+	}
+
+	return nil, fmt.Errorf("%w: key type %d with algorithm %d", ErrUnsupportedPublicKey, keyType, alg)
+}
+
+// Verify checks signature over message
+func (k *PublicKey) Verify(message, signature []byte) error {
+	var ok bool
+	switch key := k.Key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		ok = ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, message, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
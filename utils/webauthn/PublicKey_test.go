@@ -0,0 +1,26 @@
+package webauthn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePublicKeyRejectsInvalidKeys(t *testing.T) {
+	point := make([]byte, 32)
+	point[31] = 1
+
+	tests := map[string][]byte{
+		"unsupported algorithm": cborEncode([]cborPair{{coseKeyType, coseKeyEC2}, {coseAlg, -35}, {coseCurve, coseCurveP256}, {coseX, point}, {coseY, point}}),
+		"wrong curve":           cborEncode([]cborPair{{coseKeyType, coseKeyEC2}, {coseAlg, AlgES256}, {coseCurve, 2}, {coseX, point}, {coseY, point}}),
+		"point not on curve":    cborEncode([]cborPair{{coseKeyType, coseKeyEC2}, {coseAlg, AlgES256}, {coseCurve, coseCurveP256}, {coseX, point}, {coseY, point}}),
+		"short coordinate":      cborEncode([]cborPair{{coseKeyType, coseKeyEC2}, {coseAlg, AlgES256}, {coseCurve, coseCurveP256}, {coseX, point[:31]}, {coseY, point}}),
+		"short ed25519 key":     cborEncode([]cborPair{{coseKeyType, coseKeyOKP}, {coseAlg, AlgEdDSA}, {coseCurve, coseCurveEd25519}, {coseX, point[:31]}}),
+		"small rsa modulus":     cborEncode([]cborPair{{coseKeyType, coseKeyRSA}, {coseAlg, AlgRS256}, {coseCurve, make([]byte, 128)}, {coseX, []byte{1, 0, 1}}}),
+		"duplicate key":         cborEncode([]cborPair{{coseKeyType, coseKeyOKP}, {coseKeyType, coseKeyOKP}}),
+	}
+	for name, coseKey := range tests {
+		if _, err := ParsePublicKey(coseKey); !errors.Is(err, ErrUnsupportedPublicKey) {
+			t.Errorf("%s: got %v, want ErrUnsupportedPublicKey", name, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package webauthn
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"go-auth/models"
+	"slices"
+)
+
+// RelyingParty is this service as seen by authenticators. Credentials are bound to ID, a
+// domain such as "example.com", and ceremonies are only accepted from the listed Origins.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// NewChallenge returns a fresh random ceremony challenge, base64url encoded as it will
+// appear in clientDataJSON
+func NewChallenge() string {
+	challenge := make([]byte, ChallengeSize)
+	rand.Read(challenge)
+	return base64.RawURLEncoding.EncodeToString(challenge)
+}
+
+// CreationOptions builds the options to register a discoverable credential (passkey) for
+// the user. Existing credentials are excluded so an authenticator isn't registered twice.
+func (rp *RelyingParty) CreationOptions(user *models.User, challenge string, existing []*models.WebAuthnCredential) models.WebAuthnCreationOptions {
+	rawChallenge, _ := base64.RawURLEncoding.DecodeString(challenge)
+
+	exclude := make([]models.WebAuthnCredentialDescriptor, 0, len(existing))
+	for _, credential := range existing {
+		id, err := base64.RawURLEncoding.DecodeString(credential.ID)
+		if err != nil {
+			continue
+		}
+		exclude = append(exclude, models.WebAuthnCredentialDescriptor{Type: "public-key", ID: id, Transports: credential.Transports})
+	}
+
+	return models.WebAuthnCreationOptions{
+		Challenge: rawChallenge,
+		RP:        models.WebAuthnRelyingParty{ID: rp.ID, Name: rp.Name},
+		User: models.WebAuthnUser{
+			ID:          []byte(user.ID),
+			Name:        user.Email,
+			DisplayName: user.Username,
+		},
+		PubKeyCredParams: []models.WebAuthnCredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:            int(models.WebAuthnChallengeDuration.Milliseconds()),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: models.WebAuthnAuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions builds the options for a passwordless login with a discoverable credential
+func (rp *RelyingParty) RequestOptions(challenge string) models.WebAuthnRequestOptions {
+	rawChallenge, _ := base64.RawURLEncoding.DecodeString(challenge)
+
+	return models.WebAuthnRequestOptions{
+		Challenge:        rawChallenge,
+		Timeout:          int(models.WebAuthnChallengeDuration.Milliseconds()),
+		RPID:             rp.ID,
+		UserVerification: "required",
+	}
+}
+
+// allowsOrigin reports whether a ceremony may come from origin
+func (rp *RelyingParty) allowsOrigin(origin string) bool {
+	return slices.Contains(rp.Origins, origin)
+}
@@ -0,0 +1,187 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+// Test relying party; ceremonies default to its ID and only origin
+var testRP = &RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+
+// cborPair is a map entry for cborEncode; maps are written in the given order
+type cborPair struct {
+	Key   any
+	Value any
+}
+
+// cborEncode writes the small CBOR subset authenticators use: ints, byte and text strings and maps
+func cborEncode(v any) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= 0xff:
+			return []byte{major<<5 | 24, byte(n)}
+		case n <= 0xffff:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case []cborPair:
+		out := head(5, uint64(len(v)))
+		for _, pair := range v {
+			out = append(out, cborEncode(pair.Key)...)
+			out = append(out, cborEncode(pair.Value)...)
+		}
+		return out
+	}
+	panic("cborEncode: unsupported type")
+}
+
+// softwareAuthenticator is a passkey held in memory, signing like a platform authenticator would
+type softwareAuthenticator struct {
+	alg          int
+	ecKey        *ecdsa.PrivateKey
+	edKey        ed25519.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T, alg int) *softwareAuthenticator {
+	t.Helper()
+
+	auth := &softwareAuthenticator{alg: alg, credentialID: make([]byte, 16), signCount: 1}
+	rand.Read(auth.credentialID)
+
+	var err error
+	switch alg {
+	case AlgES256:
+		auth.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, auth.edKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("unsupported algorithm %d", alg)
+	}
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return auth
+}
+
+// coseKey encodes the credential public key as a COSE_Key
+func (a *softwareAuthenticator) coseKey() []byte {
+	if a.alg == AlgEdDSA {
+		return cborEncode([]cborPair{
+			{coseKeyType, coseKeyOKP},
+			{coseAlg, AlgEdDSA},
+			{coseCurve, coseCurveEd25519},
+			{coseX, []byte(a.edKey.Public().(ed25519.PublicKey))},
+		})
+	}
+
+	ecdhKey, _ := a.ecKey.ECDH()
+	point := ecdhKey.PublicKey().Bytes() // 0x04 || x || y
+	return cborEncode([]cborPair{
+		{coseKeyType, coseKeyEC2},
+		{coseAlg, AlgES256},
+		{coseCurve, coseCurveP256},
+		{coseX, point[1:33]},
+		{coseY, point[33:]},
+	})
+}
+
+func (a *softwareAuthenticator) sign(message []byte) []byte {
+	if a.alg == AlgEdDSA {
+		return ed25519.Sign(a.edKey, message)
+	}
+	digest := sha256.Sum256(message)
+	signature, err := ecdsa.SignASN1(rand.Reader, a.ecKey, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+// ceremony is what the browser and authenticator put into a response; tests change single
+// fields to produce invalid responses
+type ceremony struct {
+	Type        string
+	Challenge   string
+	Origin      string
+	CrossOrigin bool
+	RPID        string
+	Flags       byte
+}
+
+func newCeremony(clientDataType, challenge string) ceremony {
+	return ceremony{
+		Type:      clientDataType,
+		Challenge: challenge,
+		Origin:    testRP.Origins[0],
+		RPID:      testRP.ID,
+		Flags:     flagUserPresent | flagUserVerified,
+	}
+}
+
+func (c ceremony) clientDataJSON() []byte {
+	clientData, _ := json.Marshal(ClientData{Type: c.Type, Challenge: c.Challenge, Origin: c.Origin, CrossOrigin: c.CrossOrigin})
+	return clientData
+}
+
+// authenticatorData builds authenticator data, with the attested credential when attest is set
+func (a *softwareAuthenticator) authenticatorData(c ceremony, attest bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.RPID))
+	flags := c.Flags
+	if attest {
+		flags |= flagAttestedData
+	}
+
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attest {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+// register answers navigator.credentials.create() with a "none" attestation
+func (a *softwareAuthenticator) register(c ceremony) (clientDataJSON, attestationObject []byte) {
+	attestationObject = cborEncode([]cborPair{
+		{"fmt", "none"},
+		{"attStmt", []cborPair{}},
+		{"authData", a.authenticatorData(c, true)},
+	})
+	return c.clientDataJSON(), attestationObject
+}
+
+// assert answers navigator.credentials.get(), bumping the signature counter
+func (a *softwareAuthenticator) assert(c ceremony) (clientDataJSON, authenticatorData, signature []byte) {
+	a.signCount++
+	clientDataJSON = c.clientDataJSON()
+	authenticatorData = a.authenticatorData(c, false)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signature = a.sign(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	return clientDataJSON, authenticatorData, signature
+}
@@ -0,0 +1,35 @@
+package webauthn
+
+import "crypto/sha256"
+
+// VerifyAssertion verifies the response to navigator.credentials.get() for the issued
+// challenge against the stored credential public key (WebAuthn §7.2) and returns the
+// authenticator's new signature counter. Checking the counter against the stored one
+// is left to the caller.
+func (rp *RelyingParty) VerifyAssertion(challenge string, publicKey, clientDataJSON, authenticatorData, signature []byte) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, clientDataGet, challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := ParseAuthenticatorData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := authData.verify(rp.ID); err != nil {
+		return 0, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	// The signature covers authenticatorData || SHA-256(clientDataJSON)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	message := append(append([]byte(nil), authenticatorData...), clientDataHash[:]...)
+	if err := key.Verify(message, signature); err != nil {
+		return 0, err
+	}
+
+	return authData.SignCount, nil
+}
@@ -0,0 +1,126 @@
+package webauthn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyAssertion(t *testing.T) {
+	for name, alg := range testAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			auth := newSoftwareAuthenticator(t, alg)
+
+			// Every login returns the authenticator's increased counter
+			for range 3 {
+				challenge := NewChallenge()
+				clientDataJSON, authenticatorData, signature := auth.assert(newCeremony(clientDataGet, challenge))
+
+				signCount, err := testRP.VerifyAssertion(challenge, auth.coseKey(), clientDataJSON, authenticatorData, signature)
+				if err != nil {
+					t.Fatalf("VerifyAssertion: %v", err)
+				}
+				if signCount != auth.signCount {
+					t.Fatalf("got sign count %d, want %d", signCount, auth.signCount)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	challenge := NewChallenge()
+
+	tests := []struct {
+		name   string
+		modify func(c *ceremony)
+		want   error
+	}{
+		{"wrong origin", func(c *ceremony) { c.Origin = "https://evil.example.net" }, ErrInvalidClientData},
+		{"http origin", func(c *ceremony) { c.Origin = "http://example.com" }, ErrInvalidClientData},
+		{"wrong challenge", func(c *ceremony) { c.Challenge = NewChallenge() }, ErrInvalidClientData},
+		{"cross origin", func(c *ceremony) { c.CrossOrigin = true }, ErrInvalidClientData},
+		{"registration client data", func(c *ceremony) { c.Type = clientDataCreate }, ErrInvalidClientData},
+		{"wrong rp id", func(c *ceremony) { c.RPID = "evil.example.net" }, ErrInvalidAuthenticatorData},
+		{"user not present", func(c *ceremony) { c.Flags &^= flagUserPresent }, ErrInvalidAuthenticatorData},
+		{"user not verified", func(c *ceremony) { c.Flags &^= flagUserVerified }, ErrInvalidAuthenticatorData},
+	}
+	for _, tt := range tests {
+		for name, alg := range testAlgorithms {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				auth := newSoftwareAuthenticator(t, alg)
+				c := newCeremony(clientDataGet, challenge)
+				tt.modify(&c)
+
+				clientDataJSON, authenticatorData, signature := auth.assert(c)
+				if _, err := testRP.VerifyAssertion(challenge, auth.coseKey(), clientDataJSON, authenticatorData, signature); !errors.Is(err, tt.want) {
+					t.Fatalf("got %v, want %v", err, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyAssertionRejectsBadSignatures(t *testing.T) {
+	for name, alg := range testAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			auth := newSoftwareAuthenticator(t, alg)
+			other := newSoftwareAuthenticator(t, alg)
+			challenge := NewChallenge()
+			clientDataJSON, authenticatorData, signature := auth.assert(newCeremony(clientDataGet, challenge))
+
+			flipped := append([]byte(nil), signature...)
+			flipped[len(flipped)-1] ^= 0x01
+
+			// A higher counter the authenticator never signed
+			tamperedData := append([]byte(nil), authenticatorData...)
+			tamperedData[authenticatorHeader-1]++
+
+			// Still valid client data for the challenge, but not the bytes that were signed
+			tamperedClientData := append(append([]byte(nil), clientDataJSON...), ' ')
+
+			tests := map[string]struct {
+				publicKey, clientDataJSON, authenticatorData, signature []byte
+			}{
+				"flipped bit":          {auth.coseKey(), clientDataJSON, authenticatorData, flipped},
+				"empty signature":      {auth.coseKey(), clientDataJSON, authenticatorData, nil},
+				"another credential":   {other.coseKey(), clientDataJSON, authenticatorData, signature},
+				"tampered auth data":   {auth.coseKey(), clientDataJSON, tamperedData, signature},
+				"tampered client data": {auth.coseKey(), tamperedClientData, authenticatorData, signature},
+			}
+			for name, tt := range tests {
+				if _, err := testRP.VerifyAssertion(challenge, tt.publicKey, tt.clientDataJSON, tt.authenticatorData, tt.signature); !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("%s: got %v, want ErrInvalidSignature", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionRejectsMalformedInput(t *testing.T) {
+	auth := newSoftwareAuthenticator(t, AlgES256)
+	challenge := NewChallenge()
+	clientDataJSON, authenticatorData, signature := auth.assert(newCeremony(clientDataGet, challenge))
+	publicKey := auth.coseKey()
+
+	tests := map[string]struct {
+		publicKey, clientDataJSON, authenticatorData []byte
+		want                                         error
+	}{
+		"client data not json":     {publicKey, []byte("{"), authenticatorData, ErrInvalidClientData},
+		"short authenticator data": {publicKey, clientDataJSON, authenticatorData[:20], ErrInvalidAuthenticatorData},
+		"trailing authenticator data": {
+			publicKey, clientDataJSON, append(append([]byte(nil), authenticatorData...), 0x00), ErrInvalidAuthenticatorData,
+		},
+		"truncated public key": {publicKey[:len(publicKey)-4], clientDataJSON, authenticatorData, ErrUnsupportedPublicKey},
+		"public key not cbor":  {[]byte{0xff, 0x00}, clientDataJSON, authenticatorData, ErrUnsupportedPublicKey},
+		"public key not a map": {cborEncode("key"), clientDataJSON, authenticatorData, ErrUnsupportedPublicKey},
+		"trailing public key":  {append(append([]byte(nil), publicKey...), 0x00), clientDataJSON, authenticatorData, ErrUnsupportedPublicKey},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := testRP.VerifyAssertion(challenge, tt.publicKey, tt.clientDataJSON, tt.authenticatorData, signature); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
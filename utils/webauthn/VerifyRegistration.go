@@ -0,0 +1,56 @@
+package webauthn
+
+import "fmt"
+
+// Credential is a newly registered credential, ready to be stored
+type Credential struct {
+	ID        []byte
+	PublicKey []byte // COSE_Key
+	SignCount uint32
+}
+
+// VerifyRegistration verifies the response to navigator.credentials.create() for the
+// issued challenge (WebAuthn §7.1) and returns the new credential. Attestation "none" is
+// requested, so any attestation statement is ignored and the authenticator is not vetted.
+func (rp *RelyingParty) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, clientDataCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, used, err := decodeCBOR(attestationObject)
+	if err != nil || used != len(attestationObject) {
+		return nil, ErrInvalidAttestation
+	}
+	attestation, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidAttestation
+	}
+	if _, ok := attestation["fmt"].(string); !ok {
+		return nil, fmt.Errorf("%w: missing fmt", ErrInvalidAttestation)
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing authData", ErrInvalidAttestation)
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.verify(rp.ID); err != nil {
+		return nil, err
+	}
+	if authData.CredentialID == nil {
+		return nil, fmt.Errorf("%w: missing attested credential data", ErrInvalidAuthenticatorData)
+	}
+
+	if _, err := ParsePublicKey(authData.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        authData.CredentialID,
+		PublicKey: authData.PublicKey,
+		SignCount: authData.SignCount,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package webauthn
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testAlgorithms = map[string]int{"ES256": AlgES256, "EdDSA": AlgEdDSA}
+
+func TestVerifyRegistration(t *testing.T) {
+	for name, alg := range testAlgorithms {
+		t.Run(name, func(t *testing.T) {
+			auth := newSoftwareAuthenticator(t, alg)
+			challenge := NewChallenge()
+
+			clientDataJSON, attestationObject := auth.register(newCeremony(clientDataCreate, challenge))
+
+			credential, err := testRP.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+			if err != nil {
+				t.Fatalf("VerifyRegistration: %v", err)
+			}
+			if !bytes.Equal(credential.ID, auth.credentialID) {
+				t.Errorf("got credential id %x, want %x", credential.ID, auth.credentialID)
+			}
+			if !bytes.Equal(credential.PublicKey, auth.coseKey()) {
+				t.Errorf("stored public key differs from the authenticator's COSE key")
+			}
+			if credential.SignCount != auth.signCount {
+				t.Errorf("got sign count %d, want %d", credential.SignCount, auth.signCount)
+			}
+
+			key, err := ParsePublicKey(credential.PublicKey)
+			if err != nil || key.Algorithm != alg {
+				t.Errorf("ParsePublicKey: got %v, %v", key, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	challenge := NewChallenge()
+
+	tests := []struct {
+		name   string
+		modify func(c *ceremony)
+		want   error
+	}{
+		{"wrong origin", func(c *ceremony) { c.Origin = "https://evil.example.net" }, ErrInvalidClientData},
+		{"origin on another port", func(c *ceremony) { c.Origin = "https://example.com:8443" }, ErrInvalidClientData},
+		{"wrong challenge", func(c *ceremony) { c.Challenge = NewChallenge() }, ErrInvalidClientData},
+		{"cross origin", func(c *ceremony) { c.CrossOrigin = true }, ErrInvalidClientData},
+		{"assertion client data", func(c *ceremony) { c.Type = clientDataGet }, ErrInvalidClientData},
+		{"wrong rp id", func(c *ceremony) { c.RPID = "evil.example.net" }, ErrInvalidAuthenticatorData},
+		{"user not present", func(c *ceremony) { c.Flags &^= flagUserPresent }, ErrInvalidAuthenticatorData},
+		{"user not verified", func(c *ceremony) { c.Flags &^= flagUserVerified }, ErrInvalidAuthenticatorData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := newSoftwareAuthenticator(t, AlgES256)
+			c := newCeremony(clientDataCreate, challenge)
+			tt.modify(&c)
+
+			clientDataJSON, attestationObject := auth.register(c)
+			if _, err := testRP.VerifyRegistration(challenge, clientDataJSON, attestationObject); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejectsMalformedAttestation(t *testing.T) {
+	auth := newSoftwareAuthenticator(t, AlgES256)
+	challenge := NewChallenge()
+	clientDataJSON, attestationObject := auth.register(newCeremony(clientDataCreate, challenge))
+	authData := auth.authenticatorData(newCeremony(clientDataCreate, challenge), true)
+	assertionData := auth.authenticatorData(newCeremony(clientDataCreate, challenge), false)
+
+	tests := map[string]struct {
+		attestationObject []byte
+		want              error
+	}{
+		"empty":          {nil, ErrInvalidAttestation},
+		"truncated":      {attestationObject[:len(attestationObject)-10], ErrInvalidAttestation},
+		"trailing bytes": {append(append([]byte(nil), attestationObject...), 0x00), ErrInvalidAttestation},
+		"not a map":      {cborEncode([]byte("attestation")), ErrInvalidAttestation},
+		"indefinite map": {append([]byte{0xbf}, attestationObject[1:]...), ErrInvalidAttestation},
+		"missing fmt":    {cborEncode([]cborPair{{"authData", authData}}), ErrInvalidAttestation},
+		"missing authData": {
+			cborEncode([]cborPair{{"fmt", "none"}, {"attStmt", []cborPair{}}}),
+			ErrInvalidAttestation,
+		},
+		"truncated authData": {
+			cborEncode([]cborPair{{"fmt", "none"}, {"authData", authData[:len(authData)-5]}}),
+			ErrInvalidAuthenticatorData,
+		},
+		"attested flag without credential": {
+			cborEncode([]cborPair{{"fmt", "none"}, {"authData", authData[:authenticatorHeader]}}),
+			ErrInvalidAuthenticatorData,
+		},
+		"no attested credential": {
+			cborEncode([]cborPair{{"fmt", "none"}, {"authData", assertionData}}),
+			ErrInvalidAuthenticatorData,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := testRP.VerifyRegistration(challenge, clientDataJSON, tt.attestationObject); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}